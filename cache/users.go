@@ -11,9 +11,16 @@ import (
 // UsersCache хранилище для пользователей с активными задачами
 type UsersCache struct {
 	mu         sync.RWMutex
-	users      map[string]struct{} // Set пользователей
-	stopSync   chan struct{}       // Канал для остановки синхронизации
-	syncTicker *time.Ticker        // Ticker для периодической синхронизации
+	users      map[string]struct{}  // Set пользователей
+	scheduled  map[string]time.Time // Пользователи, у которых есть только отложенные задачи, и время ближайшей из них
+	stopSync   chan struct{}        // Канал для остановки синхронизации
+	syncTicker *time.Ticker         // Ticker для периодической синхронизации
+}
+
+// ScheduledUser пользователь с отложенными задачами
+type ScheduledUser struct {
+	UserID    string    `json:"user_id"`
+	NextRunAt time.Time `json:"next_run_at"`
 }
 
 // Global instance
@@ -22,8 +29,9 @@ var usersCache *UsersCache
 // InitUsersCache инициализирует кэш пользователей
 func InitUsersCache() error {
 	usersCache = &UsersCache{
-		users:    make(map[string]struct{}),
-		stopSync: make(chan struct{}),
+		users:     make(map[string]struct{}),
+		scheduled: make(map[string]time.Time),
+		stopSync:  make(chan struct{}),
 	}
 
 	// Синхронизируем с базой данных при старте
@@ -79,9 +87,33 @@ func AddUserWithTask(userID string) {
 	defer usersCache.mu.Unlock()
 
 	usersCache.users[userID] = struct{}{}
+	delete(usersCache.scheduled, userID)
 	log.Printf("Added user to cache: %s", userID)
 }
 
+// AddUserWithScheduledTask добавляет пользователя с отложенной задачей или повторной попыткой в кэш.
+// Пока время runAt не наступило, пользователь не считается имеющим доступные задачи
+func AddUserWithScheduledTask(userID string, runAt time.Time) {
+	if usersCache == nil {
+		log.Printf("Warning: users cache is not initialized")
+		return
+	}
+
+	usersCache.mu.Lock()
+	defer usersCache.mu.Unlock()
+
+	// Пользователь уже имеет доступные задачи
+	if _, exists := usersCache.users[userID]; exists {
+		return
+	}
+
+	// Запоминаем время ближайшей отложенной задачи
+	if nextRunAt, exists := usersCache.scheduled[userID]; !exists || runAt.Before(nextRunAt) {
+		usersCache.scheduled[userID] = runAt
+	}
+	log.Printf("Added user with scheduled task to cache: %s (run at %s)", userID, runAt.Format(time.RFC3339))
+}
+
 // RemoveUserWithTask удаляет пользователя из кэша
 func RemoveUserWithTask(userID string) {
 	if usersCache == nil {
@@ -93,6 +125,7 @@ func RemoveUserWithTask(userID string) {
 	defer usersCache.mu.Unlock()
 
 	delete(usersCache.users, userID)
+	delete(usersCache.scheduled, userID)
 	log.Printf("Removed user from cache: %s", userID)
 }
 
@@ -103,8 +136,10 @@ func GetUsersWithTasks() []string {
 		return []string{}
 	}
 
-	usersCache.mu.RLock()
-	defer usersCache.mu.RUnlock()
+	usersCache.mu.Lock()
+	defer usersCache.mu.Unlock()
+
+	promoteDueScheduledUsers(time.Now())
 
 	users := make([]string, 0, len(usersCache.users))
 	for user := range usersCache.users {
@@ -114,6 +149,37 @@ func GetUsersWithTasks() []string {
 	return users
 }
 
+// GetScheduledUsers возвращает пользователей, у которых есть только отложенные задачи
+func GetScheduledUsers() []ScheduledUser {
+	if usersCache == nil {
+		log.Printf("Warning: users cache is not initialized")
+		return []ScheduledUser{}
+	}
+
+	usersCache.mu.Lock()
+	defer usersCache.mu.Unlock()
+
+	promoteDueScheduledUsers(time.Now())
+
+	users := make([]ScheduledUser, 0, len(usersCache.scheduled))
+	for user, nextRunAt := range usersCache.scheduled {
+		users = append(users, ScheduledUser{UserID: user, NextRunAt: nextRunAt})
+	}
+
+	return users
+}
+
+// promoteDueScheduledUsers переносит пользователей, чьи отложенные задачи стали доступны, в основной список.
+// Вызывается под блокировкой на запись
+func promoteDueScheduledUsers(now time.Time) {
+	for user, nextRunAt := range usersCache.scheduled {
+		if !nextRunAt.After(now) {
+			usersCache.users[user] = struct{}{}
+			delete(usersCache.scheduled, user)
+		}
+	}
+}
+
 // CheckUserInCache проверяет, есть ли пользователь в кэше
 func CheckUserInCache(userID string) bool {
 	if usersCache == nil {
@@ -131,28 +197,49 @@ func CheckUserInCache(userID string) bool {
 func SyncUsersWithTasks() error {
 	db := database.GetDB()
 
-	// Получаем всех уникальных пользователей с активными задачами
+	now := time.Now()
+
+	// Получаем всех уникальных пользователей с активными задачами, не считая задачи, время выполнения
+	// которых (run_at) или повторной попытки (not_before) еще не наступило
 	var users []string
 	if err := db.Model(&models.Task{}).
 		Select("DISTINCT assignee").
-		Where("status IN ?", []models.TaskStatus{
-			models.StatusSubmitted,
+		Where("status IN ? OR (status = ? AND (run_at IS NULL OR run_at <= ?) AND (not_before IS NULL OR not_before <= ?))", []models.TaskStatus{
 			models.StatusWorking,
 			models.StatusWaiting,
-		}).
+		}, models.StatusSubmitted, now, now).
 		Pluck("assignee", &users).Error; err != nil {
 		return err
 	}
 
+	// Получаем пользователей с отложенными задачами и время ближайшей из них.
+	// GREATEST в Postgres пропускает NULL: задача доступна после более позднего из run_at и not_before
+	var scheduledUsers []ScheduledUser
+	if err := db.Model(&models.Task{}).
+		Select("assignee AS user_id, MIN(GREATEST(run_at, not_before)) AS next_run_at").
+		Where("status = ? AND (run_at > ? OR not_before > ?)", models.StatusSubmitted, now, now).
+		Group("assignee").
+		Scan(&scheduledUsers).Error; err != nil {
+		return err
+	}
+
 	// Заменяем весь кэш новыми данными
 	newUsers := make(map[string]struct{})
 	for _, user := range users {
 		newUsers[user] = struct{}{}
 	}
 
+	newScheduled := make(map[string]time.Time)
+	for _, user := range scheduledUsers {
+		if _, exists := newUsers[user.UserID]; !exists {
+			newScheduled[user.UserID] = user.NextRunAt
+		}
+	}
+
 	// Атомарно заменяем кэш
 	usersCache.mu.Lock()
 	usersCache.users = newUsers
+	usersCache.scheduled = newScheduled
 	usersCache.mu.Unlock()
 
 	if len(users) > 0 {
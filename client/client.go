@@ -0,0 +1,102 @@
+// Package client - Go клиент HTTP API менеджера задач
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client клиент HTTP API менеджера задач. Безопасен для использования из нескольких горутин
+type Client struct {
+	baseURL    string
+	tokens     TokenSource
+	httpClient *http.Client
+}
+
+// New создает клиент для сервиса по адресу baseURL (например, "https://task.example.com").
+// Токен для каждого запроса берется из tokens
+func New(baseURL string, tokens TokenSource) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		tokens:     tokens,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// WithHTTPClient возвращает копию клиента, выполняющую запросы через httpClient
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	clone := *c
+	clone.httpClient = httpClient
+	return &clone
+}
+
+// do выполняет запрос к API и декодирует ответ в out (если out не nil).
+// При ответе 401 токен сбрасывается и запрос повторяется один раз с новым токеном
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = encoded
+	}
+
+	resp, err := c.send(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		if invalidator, ok := c.tokens.(interface{ Invalidate() }); ok {
+			resp.Body.Close()
+			invalidator.Invalidate()
+			if resp, err = c.send(ctx, method, path, payload); err != nil {
+				return err
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send отправляет запрос с текущим токеном
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request %s %s failed: %w", method, path, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Ошибки, с которыми можно сравнить APIError через errors.Is
+var (
+	ErrInvalid      = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("access denied")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// statusErrors ошибки, соответствующие HTTP статусам ответа
+var statusErrors = map[int]error{
+	http.StatusBadRequest:      ErrInvalid,
+	http.StatusUnauthorized:    ErrUnauthorized,
+	http.StatusForbidden:       ErrForbidden,
+	http.StatusNotFound:        ErrNotFound,
+	http.StatusConflict:        ErrConflict,
+	http.StatusTooManyRequests: ErrRateLimited,
+}
+
+// APIError ошибка, возвращенная API в теле {"error": "...", ...}
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Fields дополнительные поля ответа, например current_status
+	Fields map[string]interface{}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Is позволяет проверять вид ошибки: errors.Is(err, client.ErrNotFound)
+func (e *APIError) Is(target error) bool {
+	return statusErrors[e.StatusCode] == target
+}
+
+// CurrentStatus возвращает статус задачи из ошибки перехода, если API его сообщил
+func (e *APIError) CurrentStatus() string {
+	status, _ := e.Fields["current_status"].(string)
+	return status
+}
+
+// decodeError разбирает ответ с ошибкой
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	apiErr.Message, _ = fields["error"].(string)
+	delete(fields, "error")
+	if len(fields) > 0 {
+		apiErr.Fields = fields
+	}
+	return apiErr
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"agent-task-manager/handlers"
+	"agent-task-manager/handlers/tasks"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Me возвращает пользователя текущего токена (GET /me)
+func (c *Client) Me(ctx context.Context) (*handlers.UserInfoResponse, error) {
+	var user handlers.UserInfoResponse
+	if err := c.do(ctx, http.MethodGet, "/me", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateTask создает задачу (POST /task)
+func (c *Client) CreateTask(ctx context.Context, req service.CreateTaskRequest) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task", req)
+}
+
+// ClaimTask берет в работу следующую задачу пользователя (GET /task).
+// С wait > 0 запрос ждет появления задачи не дольше wait (и LONG_POLL_MAX_WAIT сервиса).
+// Если задач нет, возвращает ошибку, для которой errors.Is(err, ErrNotFound)
+func (c *Client) ClaimTask(ctx context.Context, wait time.Duration) (*service.TaskWithSubtasks, error) {
+	return c.ClaimTaskWithChildren(ctx, wait, 0)
+}
+
+// ClaimTaskWithChildren берет в работу следующую задачу, как ClaimTask, и возвращает в Children
+// подзадачи всех статусов на childrenDepth уровней вниз (GET /task?children_depth=...)
+func (c *Client) ClaimTaskWithChildren(ctx context.Context, wait time.Duration, childrenDepth int) (*service.TaskWithSubtasks, error) {
+	query := url.Values{}
+	if wait > 0 {
+		query.Set("wait", strconv.Itoa(int(wait.Round(time.Second)/time.Second)))
+	}
+	if childrenDepth > 0 {
+		query.Set("children_depth", strconv.Itoa(childrenDepth))
+	}
+	path := "/task"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var task service.TaskWithSubtasks
+	if err := c.do(ctx, http.MethodGet, path, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Heartbeat продлевает аренду задачи в работе (POST /task/:id/heartbeat)
+func (c *Client) Heartbeat(ctx context.Context, taskID uuid.UUID) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/heartbeat", nil)
+}
+
+// CompleteTask завершает задачу с результатом (POST /task/:id/complete)
+func (c *Client) CompleteTask(ctx context.Context, taskID uuid.UUID, req service.CompleteTaskRequest) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/complete", req)
+}
+
+// FailTask завершает задачу с ошибкой (POST /tasks/:id/fail)
+func (c *Client) FailTask(ctx context.Context, taskID uuid.UUID, reason string) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/tasks/"+taskID.String()+"/fail", service.FailTaskRequest{Reason: reason})
+}
+
+// CancelTask отменяет задачу и её активные подзадачи (POST /task/:id/cancel)
+func (c *Client) CancelTask(ctx context.Context, taskID uuid.UUID) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/cancel", nil)
+}
+
+// RejectTask отклоняет назначенную задачу с причиной (POST /task/:id/reject)
+func (c *Client) RejectTask(ctx context.Context, taskID uuid.UUID, reason string) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/reject", service.RejectTaskRequest{Reason: reason})
+}
+
+// RequestInput приостанавливает задачу с вопросом создателю (POST /task/:id/request-input)
+func (c *Client) RequestInput(ctx context.Context, taskID uuid.UUID, question string) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/request-input", service.RequestInputRequest{Question: question})
+}
+
+// ProvideInput отвечает на вопрос исполнителя и возвращает задачу в очередь (POST /task/:id/provide-input)
+func (c *Client) ProvideInput(ctx context.Context, taskID uuid.UUID, answer string) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/provide-input", service.ProvideInputRequest{Answer: answer})
+}
+
+// RedriveTask возвращает задачу из dead-letter в очередь (POST /task/:id/redrive)
+func (c *Client) RedriveTask(ctx context.Context, taskID uuid.UUID) (*models.Task, error) {
+	return c.taskRequest(ctx, http.MethodPost, "/task/"+taskID.String()+"/redrive", nil)
+}
+
+// TaskHistory возвращает журнал переходов задачи (GET /task/:id/history)
+func (c *Client) TaskHistory(ctx context.Context, taskID uuid.UUID) (*tasks.TaskHistoryResponse, error) {
+	var history tasks.TaskHistoryResponse
+	if err := c.do(ctx, http.MethodGet, "/task/"+taskID.String()+"/history", nil, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// TaskCredentialAccess возвращает журнал выдачи credentials задачи (GET /task/:id/credential-access)
+func (c *Client) TaskCredentialAccess(ctx context.Context, taskID uuid.UUID) (*tasks.TaskCredentialAccessResponse, error) {
+	var log tasks.TaskCredentialAccessResponse
+	if err := c.do(ctx, http.MethodGet, "/task/"+taskID.String()+"/credential-access", nil, &log); err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// CredentialAccess ищет записи журнала выдачи credentials по фильтру (GET /credential-access).
+// Администраторы получают записи всех задач, остальные пользователи - задач, которые они создали
+func (c *Client) CredentialAccess(ctx context.Context, filter service.CredentialAccessFilter) ([]models.CredentialAccess, error) {
+	query := url.Values{}
+	if filter.TaskID != nil {
+		query.Set("task_id", filter.TaskID.String())
+	}
+	if filter.Assignee != "" {
+		query.Set("assignee", filter.Assignee)
+	}
+	if filter.Service != "" {
+		query.Set("service", filter.Service)
+	}
+	if filter.Reference != "" {
+		query.Set("reference", filter.Reference)
+	}
+	if filter.Since != nil {
+		query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if filter.Until != nil {
+		query.Set("until", filter.Until.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	path := "/credential-access"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var accesses []models.CredentialAccess
+	if err := c.do(ctx, http.MethodGet, path, nil, &accesses); err != nil {
+		return nil, err
+	}
+	return accesses, nil
+}
+
+// DeadLetterTasks возвращает задачи пользователя в dead-letter (GET /dead-letter)
+func (c *Client) DeadLetterTasks(ctx context.Context) ([]tasks.TaskView, error) {
+	var deadLettered []tasks.TaskView
+	if err := c.do(ctx, http.MethodGet, "/dead-letter", nil, &deadLettered); err != nil {
+		return nil, err
+	}
+	return deadLettered, nil
+}
+
+// RootTasks возвращает корневые задачи, созданные пользователем (GET /root-task)
+func (c *Client) RootTasks(ctx context.Context) ([]tasks.RootTaskSummary, error) {
+	var summaries []tasks.RootTaskSummary
+	if err := c.do(ctx, http.MethodGet, "/root-task", nil, &summaries); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// RootTaskTree возвращает все задачи дерева корневой задачи без credentials (GET /root-task/:id/tasks)
+func (c *Client) RootTaskTree(ctx context.Context, rootTaskID uuid.UUID) ([]tasks.TaskView, error) {
+	var tree []tasks.TaskView
+	if err := c.do(ctx, http.MethodGet, "/root-task/"+rootTaskID.String()+"/tasks", nil, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Stats возвращает статистику задач пользователя за период (GET /stat).
+// period: today, yesterday, week, month, year, all-time; пустая строка - all-time
+func (c *Client) Stats(ctx context.Context, period string) (*handlers.StatsResponse, error) {
+	path := "/stat"
+	if period != "" {
+		path += "?period=" + url.QueryEscape(period)
+	}
+
+	var stats handlers.StatsResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// taskRequest выполняет запрос, возвращающий задачу
+func (c *Client) taskRequest(ctx context.Context, method, path string, body interface{}) (*models.Task, error) {
+	var task models.Task
+	if err := c.do(ctx, method, path, body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"agent-task-manager/handlers"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource возвращает JWT для запросов к API
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken источник с фиксированным токеном
+type StaticToken string
+
+// Token возвращает токен
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// tokenRefreshMargin запас до истечения токена, при котором он выпускается заново
+const tokenRefreshMargin = time.Minute
+
+// SecretTokenSource выпускает токены пользователя через POST /generate-jwt и обновляет их перед истечением.
+// Эндпоинт ограничен 5 запросами в минуту, поэтому токен кэшируется
+type SecretTokenSource struct {
+	baseURL    string
+	secret     string
+	userID     string
+	expiresIn  time.Duration
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewSecretTokenSource создает источник токенов пользователя userID на сервисе baseURL.
+// expiresIn округляется до часов; 0 - срок сервиса по умолчанию
+func NewSecretTokenSource(baseURL, secret, userID string, expiresIn time.Duration) *SecretTokenSource {
+	return &SecretTokenSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		secret:     secret,
+		userID:     userID,
+		expiresIn:  expiresIn,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// Token возвращает кэшированный токен или выпускает новый, если срок текущего подходит к концу
+func (s *SecretTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	jwtResponse, err := GenerateToken(ctx, s.httpClient, s.baseURL, s.secret, s.userID, s.expiresIn)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = jwtResponse.Token
+	s.expiresAt = time.Unix(jwtResponse.ExpiresAt, 0)
+	return s.token, nil
+}
+
+// Invalidate сбрасывает кэшированный токен. Клиент вызывает его, получив 401
+func (s *SecretTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// GenerateToken выпускает токен пользователя userID через POST /generate-jwt.
+// expiresIn округляется до часов; 0 - срок сервиса по умолчанию
+func GenerateToken(ctx context.Context, httpClient *http.Client, baseURL, secret, userID string, expiresIn time.Duration) (*handlers.JWTResponse, error) {
+	hours := int(expiresIn.Round(time.Hour) / time.Hour)
+	if expiresIn > 0 && hours == 0 {
+		hours = 1
+	}
+	payload, err := json.Marshal(handlers.GenerateJWTRequest{
+		Secret:    secret,
+		UserID:    userID,
+		ExpiresIn: hours,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/generate-jwt", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeError(resp)
+	}
+
+	var jwtResponse handlers.JWTResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jwtResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return &jwtResponse, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"agent-task-manager/service"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// HandlerFunc выполняет задачу и возвращает непустой результат для завершения.
+// Ошибка переводит задачу в failed с текстом ошибки в качестве причины
+type HandlerFunc func(ctx context.Context, task *service.TaskWithSubtasks) (string, error)
+
+// Значения параметров Worker по умолчанию
+const (
+	defaultClaimWait         = 30 * time.Second
+	defaultHeartbeatInterval = 5 * time.Minute
+	defaultErrorDelay        = 5 * time.Second
+)
+
+// Worker в цикле берет задачи пользователя в работу, выполняет их обработчиком
+// и завершает или проваливает по результату
+type Worker struct {
+	client  *Client
+	handler HandlerFunc
+
+	// ClaimWait время ожидания задачи в одном запросе GET /task?wait=...
+	ClaimWait time.Duration
+	// HeartbeatInterval интервал продления аренды, должен быть меньше LEASE_TTL сервиса
+	HeartbeatInterval time.Duration
+	// ErrorDelay пауза перед повтором после ошибки запроса задачи
+	ErrorDelay time.Duration
+	// ChildrenDepth сколько уровней подзадач любых статусов передавать обработчику в Children (0 - не передавать)
+	ChildrenDepth int
+}
+
+// NewWorker создает исполнителя задач с параметрами по умолчанию
+func NewWorker(client *Client, handler HandlerFunc) *Worker {
+	return &Worker{
+		client:            client,
+		handler:           handler,
+		ClaimWait:         defaultClaimWait,
+		HeartbeatInterval: defaultHeartbeatInterval,
+		ErrorDelay:        defaultErrorDelay,
+	}
+}
+
+// Run выполняет задачи, пока не будет отменен ctx. Возвращает ошибку контекста
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		task, err := w.client.ClaimTaskWithChildren(ctx, w.ClaimWait, w.ChildrenDepth)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+				continue
+			}
+			log.Printf("Worker: failed to claim task: %v", err)
+			select {
+			case <-time.After(w.ErrorDelay):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		w.process(ctx, task)
+	}
+}
+
+// process выполняет взятую задачу, продлевая аренду, и отправляет результат
+func (w *Worker) process(ctx context.Context, task *service.TaskWithSubtasks) {
+	handlerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Продлеваем аренду, пока работает обработчик. Если задача больше не в работе
+	// (отменена или аренда истекла), отменяем контекст обработчика
+	lost := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(w.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_, err := w.client.Heartbeat(handlerCtx, task.ID)
+				if errors.Is(err, ErrConflict) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrNotFound) {
+					log.Printf("Worker: task %s is no longer in work: %v", task.ID, err)
+					close(lost)
+					cancel()
+					return
+				}
+				if err != nil && handlerCtx.Err() == nil {
+					log.Printf("Worker: failed to extend lease of task %s: %v", task.ID, err)
+				}
+			case <-handlerCtx.Done():
+				return
+			}
+		}
+	}()
+
+	result, handlerErr := w.handler(handlerCtx, task)
+	cancel()
+	<-heartbeatDone
+
+	select {
+	case <-lost:
+		return
+	default:
+	}
+	if ctx.Err() != nil {
+		// Работа прервана остановкой исполнителя, задача вернется в очередь по истечении аренды
+		return
+	}
+
+	if handlerErr != nil {
+		if _, err := w.client.FailTask(ctx, task.ID, handlerErr.Error()); err != nil {
+			log.Printf("Worker: failed to fail task %s: %v", task.ID, err)
+		}
+		return
+	}
+	if _, err := w.client.CompleteTask(ctx, task.ID, service.CompleteTaskRequest{Description: result}); err != nil {
+		log.Printf("Worker: failed to complete task %s: %v", task.ID, err)
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"agent-task-manager/client"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// runToken выпускает токен через /generate-jwt и при --save сохраняет его в конфигурацию
+func runToken(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	secret := fs.String("secret", os.Getenv("ATM_SECRET"), "service SECRET_KEY (default $ATM_SECRET)")
+	user := fs.String("user", "", "user ID of the token (default anonymous)")
+	expiresIn := fs.Int("expires-in", 0, "token lifetime in hours (default service setting)")
+	save := fs.Bool("save", false, "save server URL and token to the config file")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *secret == "" {
+		return errors.New("--secret or ATM_SECRET is required")
+	}
+
+	server, err := env.server()
+	if err != nil {
+		return err
+	}
+
+	response, err := client.GenerateToken(ctx, http.DefaultClient, server, *secret, *user, time.Duration(*expiresIn)*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	if *save {
+		env.config.Server = server
+		env.config.Token = response.Token
+		if err := env.saveConfig(); err != nil {
+			return err
+		}
+	}
+
+	if env.output == outputJSON {
+		return env.printJSON(response)
+	}
+	fmt.Fprintln(env.stdout, response.Token)
+	if *save {
+		fmt.Fprintf(os.Stderr, "Token for %s saved to %s (expires %s)\n",
+			response.UserID, env.configPath, time.Unix(response.ExpiresAt, 0).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// runCreate создает задачу
+func runCreate(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	description := fs.String("description", "", "task description (required)")
+	assignee := fs.String("assignee", "", "assignee user ID")
+	parent := fs.String("parent", "", "parent task ID")
+	priority := fs.Int("priority", 0, "priority from -100 to 100 (default inherited from parent)")
+	runAt := fs.String("run-at", "", "do not hand out before this time (RFC3339)")
+	dependsOn := fs.String("depends-on", "", "comma-separated IDs of tasks that must be completed first")
+	onChildFailure := fs.String("on-child-failure", "", "reaction to a failed subtask: wait, resubmit_parent, fail_parent or cancel_siblings (default wait)")
+	input := fs.String("input", "", "structured task input: JSON or @file")
+	inputSchema := fs.String("input-schema", "", "JSON Schema of the input: JSON or @file")
+	outputSchema := fs.String("output-schema", "", "JSON Schema of the output required on completion: JSON or @file")
+	credentials := fs.String("credentials", "", `credentials JSON: {"service": {"ENV_VAR": "value"}}`)
+	inheritCredentials := fs.String("inherit-credentials", "", "comma-separated credential services inherited from the parent task")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *description == "" {
+		return errors.New("--description is required")
+	}
+
+	req := service.CreateTaskRequest{
+		Description:    *description,
+		Assignee:       *assignee,
+		OnChildFailure: models.ChildFailurePolicy(*onChildFailure),
+	}
+	if *parent != "" {
+		parentID, err := uuid.Parse(*parent)
+		if err != nil {
+			return fmt.Errorf("invalid parent task id: %w", err)
+		}
+		req.ParentTaskID = &parentID
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "priority" {
+			req.Priority = priority
+		}
+	})
+	if *dependsOn != "" {
+		for _, value := range strings.Split(*dependsOn, ",") {
+			dependencyID, err := uuid.Parse(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("invalid --depends-on task id: %w", err)
+			}
+			req.DependsOn = append(req.DependsOn, dependencyID)
+		}
+	}
+	if *runAt != "" {
+		parsed, err := time.Parse(time.RFC3339, *runAt)
+		if err != nil {
+			return fmt.Errorf("invalid --run-at: %w", err)
+		}
+		req.RunAt = &parsed
+	}
+	if *credentials != "" {
+		if !json.Valid([]byte(*credentials)) {
+			return errors.New("--credentials must be valid JSON")
+		}
+		req.Credentials = json.RawMessage(*credentials)
+	}
+	if *inheritCredentials != "" {
+		for _, serviceName := range strings.Split(*inheritCredentials, ",") {
+			req.InheritCredentials = append(req.InheritCredentials, strings.TrimSpace(serviceName))
+		}
+	}
+	for _, payload := range []struct {
+		flag  string
+		value string
+		dest  *json.RawMessage
+	}{
+		{"input", *input, &req.Input},
+		{"input-schema", *inputSchema, &req.InputSchema},
+		{"output-schema", *outputSchema, &req.OutputSchema},
+	} {
+		var err error
+		if *payload.dest, err = readJSONFlag(payload.flag, payload.value); err != nil {
+			return err
+		}
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	task, err := c.CreateTask(ctx, req)
+	if err != nil {
+		return err
+	}
+	return env.printTask(task)
+}
+
+// runClaim берет в работу следующую задачу
+func runClaim(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("claim", flag.ContinueOnError)
+	wait := fs.Duration("wait", 0, "wait for a task up to this duration, e.g. 30s")
+	childrenDepth := fs.Int("children-depth", 0, "show subtasks of any status this many levels deep (up to 10)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	task, err := c.ClaimTaskWithChildren(ctx, *wait, *childrenDepth)
+	if err != nil {
+		return err
+	}
+	return env.printClaimedTask(task)
+}
+
+// runComplete завершает задачу с результатом
+func runComplete(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("complete", flag.ContinueOnError)
+	result := fs.String("result", "", "task result (required)")
+	output := fs.String("output", "", "structured task result: JSON or @file (required if the task has an output schema)")
+	taskID, err := parseTaskCommand(fs, args)
+	if err != nil {
+		return err
+	}
+	if *result == "" {
+		return errors.New("--result is required")
+	}
+	outputJSON, err := readJSONFlag("output", *output)
+	if err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	task, err := c.CompleteTask(ctx, taskID, service.CompleteTaskRequest{Description: *result, Output: outputJSON})
+	if err != nil {
+		return err
+	}
+	return env.printTask(task)
+}
+
+// runFail завершает задачу с ошибкой
+func runFail(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("fail", flag.ContinueOnError)
+	reason := fs.String("reason", "", "failure reason (required)")
+	taskID, err := parseTaskCommand(fs, args)
+	if err != nil {
+		return err
+	}
+	if *reason == "" {
+		return errors.New("--reason is required")
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	task, err := c.FailTask(ctx, taskID, *reason)
+	if err != nil {
+		return err
+	}
+	return env.printTask(task)
+}
+
+// runCancel отменяет задачу
+func runCancel(ctx context.Context, env *environment, args []string) error {
+	taskID, err := parseTaskCommand(flag.NewFlagSet("cancel", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	task, err := c.CancelTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return env.printTask(task)
+}
+
+// runHistory выводит журнал переходов задачи
+func runHistory(ctx context.Context, env *environment, args []string) error {
+	taskID, err := parseTaskCommand(flag.NewFlagSet("history", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	history, err := c.TaskHistory(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return env.printHistory(history)
+}
+
+// runCredentialAccess выводит журнал выдачи credentials: всех доступных задач или одной задачи
+func runCredentialAccess(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("credential-access", flag.ContinueOnError)
+	assignee := fs.String("assignee", "", "only credentials handed to this assignee")
+	serviceName := fs.String("service", "", "only accesses that exposed this credential service")
+	reference := fs.String("reference", "", "only accesses that resolved this secret reference")
+	since := fs.String("since", "", "only accesses at or after this time (RFC3339)")
+	limit := fs.Int("limit", 0, "maximum number of records, newest first (default 100)")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return errors.New("credential-access: expected at most one task id argument")
+	}
+
+	filter := service.CredentialAccessFilter{
+		Assignee:  *assignee,
+		Service:   *serviceName,
+		Reference: *reference,
+		Limit:     *limit,
+	}
+	if len(positional) == 1 {
+		taskID, err := uuid.Parse(positional[0])
+		if err != nil {
+			return fmt.Errorf("invalid task id: %w", err)
+		}
+		filter.TaskID = &taskID
+	}
+	if *since != "" {
+		parsed, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = &parsed
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	accesses, err := c.CredentialAccess(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.printCredentialAccess(accesses)
+}
+
+// runTree выводит дерево задач корневой задачи
+func runTree(ctx context.Context, env *environment, args []string) error {
+	rootTaskID, err := parseTaskCommand(flag.NewFlagSet("tree", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	tree, err := c.RootTaskTree(ctx, rootTaskID)
+	if err != nil {
+		return err
+	}
+	return env.printTree(rootTaskID, tree)
+}
+
+// runRoots выводит корневые задачи пользователя
+func runRoots(ctx context.Context, env *environment, args []string) error {
+	if _, err := parseFlags(flag.NewFlagSet("roots", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	roots, err := c.RootTasks(ctx)
+	if err != nil {
+		return err
+	}
+	return env.printRoots(roots)
+}
+
+// runStats выводит статистику задач
+func runStats(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	period := fs.String("period", "", "today, yesterday, week, month, year or all-time (default all-time)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	c, err := env.client()
+	if err != nil {
+		return err
+	}
+	stats, err := c.Stats(ctx, *period)
+	if err != nil {
+		return err
+	}
+	return env.printStats(stats)
+}
+
+// parseTaskCommand разбирает флаги подкоманды с единственным аргументом - ID задачи
+func parseTaskCommand(fs *flag.FlagSet, args []string) (uuid.UUID, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(positional) != 1 {
+		return uuid.Nil, fmt.Errorf("%s: expected one task id argument", fs.Name())
+	}
+
+	taskID, err := uuid.Parse(positional[0])
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid task id: %w", err)
+	}
+	return taskID, nil
+}
+
+// readJSONFlag читает JSON из значения флага или из файла, если значение начинается с @
+func readJSONFlag(name, value string) (json.RawMessage, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data := []byte(value)
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read --%s file: %w", name, err)
+		}
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("--%s must be valid JSON", name)
+	}
+	return json.RawMessage(data), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"agent-task-manager/client"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileConfig содержимое файла конфигурации atm
+type fileConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+}
+
+// environment настройки, общие для подкоманд
+type environment struct {
+	configPath string
+	config     fileConfig
+	output     string
+	stdout     io.Writer
+}
+
+// defaultConfigPath путь к файлу конфигурации по умолчанию
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "atm.json"
+	}
+	return filepath.Join(dir, "atm", "config.json")
+}
+
+// newEnvironment загружает файл конфигурации и применяет переопределения:
+// переменные окружения ATM_SERVER, ATM_TOKEN, затем флаги
+func newEnvironment(configPath, server, token, output string, stdout io.Writer) (*environment, error) {
+	if configPath == "" {
+		configPath = os.Getenv("ATM_CONFIG")
+	}
+	if configPath == "" {
+		configPath = defaultConfigPath()
+	}
+
+	env := &environment{
+		configPath: configPath,
+		output:     output,
+		stdout:     stdout,
+	}
+
+	data, err := os.ReadFile(configPath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &env.config); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if value := os.Getenv("ATM_SERVER"); value != "" {
+		env.config.Server = value
+	}
+	if value := os.Getenv("ATM_TOKEN"); value != "" {
+		env.config.Token = value
+	}
+	if server != "" {
+		env.config.Server = server
+	}
+	if token != "" {
+		env.config.Token = token
+	}
+	return env, nil
+}
+
+// server возвращает адрес сервиса или ошибку, если он не задан
+func (e *environment) server() (string, error) {
+	if e.config.Server == "" {
+		return "", errors.New("server URL is not set: use --server, ATM_SERVER or the config file")
+	}
+	return e.config.Server, nil
+}
+
+// client создает клиент API с токеном из конфигурации
+func (e *environment) client() (*client.Client, error) {
+	server, err := e.server()
+	if err != nil {
+		return nil, err
+	}
+	if e.config.Token == "" {
+		return nil, errors.New("token is not set: run 'atm token --save', use --token or ATM_TOKEN")
+	}
+	return client.New(server, client.StaticToken(e.config.Token)), nil
+}
+
+// saveConfig записывает конфигурацию в файл, доступный только владельцу
+func (e *environment) saveConfig() error {
+	if err := os.MkdirAll(filepath.Dir(e.configPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(e.config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(e.configPath, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
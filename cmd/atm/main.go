@@ -0,0 +1,115 @@
+// Команда atm - консольный клиент менеджера задач для операторов и агентов
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command подкоманда atm
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(ctx context.Context, env *environment, args []string) error
+}
+
+// commands подкоманды в порядке вывода справки
+var commands = []command{
+	{"token", "token --secret SECRET [--user ID] [--expires-in HOURS] [--save]", "Generate a JWT via /generate-jwt", runToken},
+	{"create", "create --description TEXT [--assignee ID] [--parent ID] [--priority N] [--run-at RFC3339] [--depends-on ID,...] [--on-child-failure POLICY] [--input JSON] [--input-schema JSON] [--output-schema JSON] [--credentials JSON] [--inherit-credentials SERVICE,...]", "Create a task", runCreate},
+	{"claim", "claim [--wait DURATION] [--children-depth N]", "Take the next task to work", runClaim},
+	{"complete", "complete TASK_ID --result TEXT [--output JSON]", "Complete a task", runComplete},
+	{"fail", "fail TASK_ID --reason TEXT", "Fail a task", runFail},
+	{"cancel", "cancel TASK_ID", "Cancel a task and its active subtasks", runCancel},
+	{"history", "history TASK_ID", "Show status transitions of a task", runHistory},
+	{"credential-access", "credential-access [TASK_ID] [--assignee ID] [--service NAME] [--reference REF] [--since RFC3339] [--limit N]", "Show who received task credentials and when", runCredentialAccess},
+	{"tree", "tree ROOT_TASK_ID", "Show the task tree of a root task", runTree},
+	{"roots", "roots", "List root tasks created by you", runRoots},
+	{"stats", "stats [--period PERIOD]", "Show task statistics (today, yesterday, week, month, year, all-time)", runStats},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "atm:", err)
+		os.Exit(1)
+	}
+}
+
+// run разбирает общие флаги и выполняет подкоманду
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	global := flag.NewFlagSet("atm", flag.ContinueOnError)
+	global.Usage = func() { printUsage(global) }
+	configPath := global.String("config", "", "config file (default $ATM_CONFIG or "+defaultConfigPath()+")")
+	server := global.String("server", "", "server URL (overrides $ATM_SERVER and config)")
+	token := global.String("token", "", "JWT (overrides $ATM_TOKEN and config)")
+	output := global.String("o", "table", "output format: table or json")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		printUsage(global)
+		return flag.ErrHelp
+	}
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	env, err := newEnvironment(*configPath, *server, *token, *output, stdout)
+	if err != nil {
+		return err
+	}
+
+	name := global.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(ctx, env, global.Args()[1:])
+		}
+	}
+	printUsage(global)
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// printUsage выводит справку по общим флагам и подкомандам
+func printUsage(global *flag.FlagSet) {
+	out := global.Output()
+	fmt.Fprintln(out, "Usage: atm [flags] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.description)
+		fmt.Fprintf(out, "  %-10s   atm %s\n", "", cmd.usage)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	global.PrintDefaults()
+}
+
+// parseFlags разбирает флаги подкоманды, допуская их после позиционных аргументов.
+// Возвращает позиционные аргументы
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
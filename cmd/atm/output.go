@@ -0,0 +1,297 @@
+package main
+
+import (
+	"agent-task-manager/handlers"
+	"agent-task-manager/handlers/tasks"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Форматы вывода
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// maxDescriptionWidth длина описания задачи в таблицах и дереве
+const maxDescriptionWidth = 60
+
+// printJSON выводит значение в формате JSON
+func (e *environment) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// table создает writer для вывода колонок, выровненных пробелами
+func (e *environment) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+}
+
+// printTask выводит задачу
+func (e *environment) printTask(task *models.Task) error {
+	if e.output == outputJSON {
+		return e.printJSON(task)
+	}
+
+	w := e.table()
+	writeTask(w, task)
+	return w.Flush()
+}
+
+// writeTask пишет поля задачи в виде "Поле: значение"
+func writeTask(w *tabwriter.Writer, task *models.Task) {
+	fmt.Fprintf(w, "ID:\t%s\n", task.ID)
+	fmt.Fprintf(w, "Status:\t%s\n", task.Status)
+	fmt.Fprintf(w, "Created by:\t%s\n", task.CreatedBy)
+	fmt.Fprintf(w, "Assignee:\t%s\n", task.Assignee)
+	fmt.Fprintf(w, "Priority:\t%d\n", task.Priority)
+	if task.OnChildFailure != "" && task.OnChildFailure != models.ChildFailureWait {
+		fmt.Fprintf(w, "On child failure:\t%s\n", task.OnChildFailure)
+	}
+	if task.ParentTaskID != nil {
+		fmt.Fprintf(w, "Parent:\t%s\n", task.ParentTaskID)
+	}
+	if task.RootTaskID != nil && *task.RootTaskID != task.ID {
+		fmt.Fprintf(w, "Root:\t%s\n", task.RootTaskID)
+	}
+	if task.LeaseExpiresAt != nil {
+		fmt.Fprintf(w, "Lease expires:\t%s\n", formatTime(*task.LeaseExpiresAt))
+	}
+	fmt.Fprintf(w, "Description:\t%s\n", task.Description)
+	if task.Result != "" {
+		fmt.Fprintf(w, "Result:\t%s\n", task.Result)
+	}
+	for _, payload := range []struct {
+		title string
+		value json.RawMessage
+	}{
+		{"Input", task.Input},
+		{"Output", task.Output},
+		{"Input schema", task.InputSchema},
+		{"Output schema", task.OutputSchema},
+	} {
+		if len(payload.value) > 0 {
+			fmt.Fprintf(w, "%s:\t%s\n", payload.title, compactJSON(payload.value))
+		}
+	}
+}
+
+// printClaimedTask выводит задачу, взятую в работу, с завершенными, отклоненными и неудавшимися подзадачами
+func (e *environment) printClaimedTask(task *service.TaskWithSubtasks) error {
+	if e.output == outputJSON {
+		return e.printJSON(task)
+	}
+
+	w := e.table()
+	writeTask(w, &task.Task)
+	if credentials := string(task.Credentials); credentials != "" && credentials != "null" && credentials != "{}" {
+		fmt.Fprintf(w, "Credentials:\t%s\n", credentials)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, group := range []struct {
+		title    string
+		subtasks []models.Task
+	}{
+		{"Completed subtasks", task.CompletedSubtasks},
+		{"Rejected subtasks", task.RejectedSubtasks},
+		{"Failed subtasks", task.FailedSubtasks},
+	} {
+		if len(group.subtasks) == 0 {
+			continue
+		}
+		fmt.Fprintf(e.stdout, "\n%s:\n", group.title)
+		w := e.table()
+		fmt.Fprintln(w, "ID\tASSIGNEE\tRESULT")
+		for _, subtask := range group.subtasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", subtask.ID, subtask.Assignee, truncate(subtask.Result))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(task.Children) > 0 {
+		fmt.Fprintln(e.stdout, "\nSubtasks:")
+		e.printTaskNodes(task.Children, "")
+	}
+	return nil
+}
+
+// printTaskNodes выводит дерево подзадач взятой задачи с их результатами
+func (e *environment) printTaskNodes(nodes []service.TaskNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		line := fmt.Sprintf("[%s] %s %s: %s", node.Status, node.ID, valueOrDash(node.Assignee), truncate(node.Description))
+		if node.Result != "" {
+			line += " => " + truncate(node.Result)
+		}
+		fmt.Fprintln(e.stdout, prefix+branch+line)
+		e.printTaskNodes(node.Children, prefix+indent)
+	}
+}
+
+// printHistory выводит журнал переходов задачи
+func (e *environment) printHistory(history *tasks.TaskHistoryResponse) error {
+	if e.output == outputJSON {
+		return e.printJSON(history)
+	}
+
+	w := e.table()
+	fmt.Fprintln(w, "TIME\tEVENT\tFROM\tTO\tACTOR")
+	for _, event := range history.Events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			formatTime(event.CreatedAt), event.Type, valueOrDash(string(event.FromStatus)), event.ToStatus, event.Actor)
+	}
+	return w.Flush()
+}
+
+// printCredentialAccess выводит записи журнала выдачи credentials
+func (e *environment) printCredentialAccess(accesses []models.CredentialAccess) error {
+	if e.output == outputJSON {
+		return e.printJSON(accesses)
+	}
+
+	w := e.table()
+	fmt.Fprintln(w, "TIME\tTASK ID\tASSIGNEE\tSERVICES\tCLIENT\tPROTOCOL")
+	for _, access := range accesses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatTime(access.AccessedAt), access.TaskID, access.Assignee, strings.Join(access.Services, ","),
+			valueOrDash(access.ClientIP), access.Protocol)
+	}
+	return w.Flush()
+}
+
+// printRoots выводит корневые задачи
+func (e *environment) printRoots(roots []tasks.RootTaskSummary) error {
+	if e.output == outputJSON {
+		return e.printJSON(roots)
+	}
+
+	w := e.table()
+	fmt.Fprintln(w, "ROOT TASK ID\tSTATUS\tASSIGNEE\tPRIORITY\tCREATED\tDESCRIPTION")
+	for _, root := range roots {
+		status := string(root.Status)
+		if root.Scheduled {
+			status += " (scheduled)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			root.RootTaskID, status, root.Assignee, root.Priority, formatTime(root.CreatedAt), truncate(root.Description))
+	}
+	return w.Flush()
+}
+
+// printStats выводит статистику задач
+func (e *environment) printStats(stats *handlers.StatsResponse) error {
+	if e.output == outputJSON {
+		return e.printJSON(stats)
+	}
+
+	w := e.table()
+	fmt.Fprintf(w, "Period:\t%s\n", stats.Period)
+	fmt.Fprintf(w, "Pending:\t%d\n", stats.PendingTasks)
+	fmt.Fprintf(w, "In progress:\t%d\n", stats.InProgress)
+	fmt.Fprintf(w, "New:\t%d\n", stats.NewTasks)
+	fmt.Fprintf(w, "Failed:\t%d\n", stats.FailedTasks)
+	return w.Flush()
+}
+
+// printTree выводит дерево задач, начиная с корневой, с отступами по уровням вложенности
+func (e *environment) printTree(rootTaskID uuid.UUID, tree []tasks.TaskView) error {
+	if e.output == outputJSON {
+		return e.printJSON(tree)
+	}
+
+	// Группируем подзадачи по родителю, сохраняя порядок создания из ответа
+	children := make(map[uuid.UUID][]tasks.TaskView)
+	var root *tasks.TaskView
+	for i, task := range tree {
+		if task.ID == rootTaskID {
+			root = &tree[i]
+			continue
+		}
+		if task.ParentTaskID != nil {
+			children[*task.ParentTaskID] = append(children[*task.ParentTaskID], task)
+		}
+	}
+	if root == nil {
+		return fmt.Errorf("root task %s not found in the tree", rootTaskID)
+	}
+
+	fmt.Fprintln(e.stdout, treeLine(*root))
+	var printChildren func(parentID uuid.UUID, prefix string)
+	printChildren = func(parentID uuid.UUID, prefix string) {
+		subtasks := children[parentID]
+		for i, task := range subtasks {
+			branch, indent := "├── ", "│   "
+			if i == len(subtasks)-1 {
+				branch, indent = "└── ", "    "
+			}
+			fmt.Fprintln(e.stdout, prefix+branch+treeLine(task))
+			printChildren(task.ID, prefix+indent)
+		}
+	}
+	printChildren(root.ID, "")
+	return nil
+}
+
+// treeLine строка задачи в дереве: статус, ID, исполнитель, описание и зависимости
+func treeLine(task tasks.TaskView) string {
+	status := string(task.Status)
+	if task.Scheduled {
+		status += ", scheduled"
+	}
+	line := fmt.Sprintf("[%s] %s %s: %s", status, task.ID, valueOrDash(task.Assignee), truncate(task.Description))
+	if len(task.DependsOn) > 0 {
+		dependsOn := make([]string, len(task.DependsOn))
+		for i, id := range task.DependsOn {
+			dependsOn[i] = id.String()
+		}
+		line += " (depends on " + strings.Join(dependsOn, ", ") + ")"
+	}
+	return line
+}
+
+// truncate сокращает текст до одной строки длиной не больше maxDescriptionWidth символов
+func truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= maxDescriptionWidth {
+		return text
+	}
+	return string(runes[:maxDescriptionWidth-1]) + "…"
+}
+
+// compactJSON выводит JSON в одну строку
+func compactJSON(value json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return string(value)
+	}
+	return buf.String()
+}
+
+// formatTime форматирует время в локальной зоне
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// valueOrDash заменяет пустое значение прочерком
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
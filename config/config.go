@@ -3,22 +3,74 @@ package config
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"agent-task-manager/encryption"
+	"agent-task-manager/secrets"
+	"agent-task-manager/signing"
+	"agent-task-manager/webhooks"
+
 	"github.com/joho/godotenv"
 )
 
 // Config структура для хранения конфигурации приложения
 type Config struct {
-	Port              string
-	SecretKey         string
-	PostgresURL       string
-	AllowedOrigins    []string
-	BlacklistedUsers  []string
-	CleanupInterval   time.Duration
-	CacheSyncInterval time.Duration
+	Port               string
+	SecretKey          string
+	PostgresURL        string
+	AllowedOrigins     []string
+	BlacklistedUsers   []string
+	CleanupInterval    time.Duration
+	CacheSyncInterval  time.Duration
+	LeaseTTL           time.Duration
+	LeaseCheckInterval time.Duration
+	// PriorityAgingInterval - за каждый такой интервал ожидания эффективный приоритет задачи
+	// увеличивается на 1, чтобы низкоприоритетные задачи не голодали. 0 - старение отключено
+	PriorityAgingInterval time.Duration
+	// RecurringCheckInterval - интервал проверки периодических задач, которые пора запустить
+	RecurringCheckInterval time.Duration
+	// LongPollMaxWait - максимальное время удержания запроса GET /task?wait=...
+	LongPollMaxWait time.Duration
+	// PgNotifyEnabled - доставлять оповещения о новых задачах между репликами через Postgres LISTEN/NOTIFY
+	PgNotifyEnabled bool
+	// WebhookDeliveryInterval - интервал проверки очереди доставки вебхуков
+	WebhookDeliveryInterval time.Duration
+	// WebhookTargets - внутренние хосты и сети, на которые разрешено отправлять вебхуки (WEBHOOK_ALLOWED_PRIVATE_TARGETS)
+	WebhookTargets *webhooks.TargetPolicy
+	// MCPToken - JWT токен пользователя, от имени которого работает MCP сервер в режиме stdio
+	MCPToken string
+	// GRPCPort - порт gRPC сервера TaskManager, запускаемого рядом с HTTP сервером
+	GRPCPort string
+	// CredentialsKeyring - ключи шифрования credentials задач (CREDENTIALS_ENCRYPTION_KEYS).
+	// nil - ключи не заданы, credentials хранятся в открытом виде
+	CredentialsKeyring *encryption.Keyring
+	// SecretStore - хранилище секретов для ссылок secretref://<name> (SECRET_STORE: file, env или vault), nil - не задано
+	SecretStore secrets.Store
+	// SecretResolver - разрешение ссылок secretref:// и file:// в credentials при выдаче задачи исполнителю
+	// с проверкой политики доступа SECRET_ACCESS_POLICY
+	SecretResolver *secrets.Resolver
+	// RequireCredentialReferences - принимать в credentials только ссылки на секреты, но не сами секреты
+	RequireCredentialReferences bool
+	// TrustedProxies - IP адреса и сети прокси, которым доверяется заголовок X-Forwarded-For (TRUSTED_PROXIES).
+	// Пусто - заголовок игнорируется и IP клиента берется из адреса соединения
+	TrustedProxies []string
+	// AdminUsers - пользователи с доступом к журналу выдачи credentials всех задач (ADMIN_USERS)
+	AdminUsers []string
+	// JWTKeys - асимметричные ключи подписи и проверки JWT (JWT_SIGNING_KEYS, JWT_VERIFICATION_KEYS).
+	// nil - ключи не заданы, токены подписываются HMAC ключом SECRET_KEY
+	JWTKeys *signing.KeySet
+	// JWTAllowHMAC - принимать токены, подписанные SECRET_KEY, при заданных JWT_SIGNING_KEYS (на время перехода)
+	JWTAllowHMAC bool
+}
+
+// IsAdmin сообщает, что пользователь указан в ADMIN_USERS
+func (c *Config) IsAdmin(userID string) bool {
+	return slices.Contains(c.AdminUsers, userID)
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
@@ -42,6 +94,8 @@ func LoadConfig() (*Config, error) {
 		Port:        getEnvOrDefault("PORT", "8081"),
 		SecretKey:   getEnvOrDefault("SECRET_KEY", ""),
 		PostgresURL: getEnvOrDefault("POSTGRES_URL", ""),
+		MCPToken:    getEnvOrDefault("MCP_TOKEN", ""),
+		GRPCPort:    getEnvOrDefault("GRPC_PORT", "9090"),
 	}
 
 	// Загружаем интервал очистки (по умолчанию 1 час)
@@ -62,6 +116,75 @@ func LoadConfig() (*Config, error) {
 	}
 	config.CacheSyncInterval = cacheSyncInterval
 
+	// Загружаем время аренды задачи исполнителем (по умолчанию 30 минут)
+	leaseTTLStr := getEnvOrDefault("LEASE_TTL", "30m")
+	leaseTTL, err := time.ParseDuration(leaseTTLStr)
+	if err != nil || leaseTTL <= 0 {
+		log.Printf("Invalid LEASE_TTL format, using default (30m): %v", err)
+		leaseTTL = 30 * time.Minute
+	}
+	config.LeaseTTL = leaseTTL
+
+	// Загружаем интервал проверки истекших аренд (по умолчанию 1 минута)
+	leaseCheckIntervalStr := getEnvOrDefault("LEASE_CHECK_INTERVAL", "1m")
+	leaseCheckInterval, err := time.ParseDuration(leaseCheckIntervalStr)
+	if err != nil || leaseCheckInterval <= 0 {
+		log.Printf("Invalid LEASE_CHECK_INTERVAL format, using default (1m): %v", err)
+		leaseCheckInterval = 1 * time.Minute
+	}
+	config.LeaseCheckInterval = leaseCheckInterval
+
+	// Загружаем интервал старения приоритета (по умолчанию отключено)
+	priorityAgingIntervalStr := getEnvOrDefault("PRIORITY_AGING_INTERVAL", "0")
+	priorityAgingInterval, err := time.ParseDuration(priorityAgingIntervalStr)
+	if err != nil || priorityAgingInterval < 0 {
+		log.Printf("Invalid PRIORITY_AGING_INTERVAL format, priority aging disabled: %v", err)
+		priorityAgingInterval = 0
+	}
+	config.PriorityAgingInterval = priorityAgingInterval
+
+	// Загружаем интервал проверки периодических задач (по умолчанию 30 секунд)
+	recurringCheckIntervalStr := getEnvOrDefault("RECURRING_CHECK_INTERVAL", "30s")
+	recurringCheckInterval, err := time.ParseDuration(recurringCheckIntervalStr)
+	if err != nil || recurringCheckInterval <= 0 {
+		log.Printf("Invalid RECURRING_CHECK_INTERVAL format, using default (30s): %v", err)
+		recurringCheckInterval = 30 * time.Second
+	}
+	config.RecurringCheckInterval = recurringCheckInterval
+
+	// Загружаем максимальное время ожидания задачи в long-polling (по умолчанию 60 секунд)
+	longPollMaxWaitStr := getEnvOrDefault("LONG_POLL_MAX_WAIT", "60s")
+	longPollMaxWait, err := time.ParseDuration(longPollMaxWaitStr)
+	if err != nil || longPollMaxWait < 0 {
+		log.Printf("Invalid LONG_POLL_MAX_WAIT format, using default (60s): %v", err)
+		longPollMaxWait = 60 * time.Second
+	}
+	config.LongPollMaxWait = longPollMaxWait
+
+	// Загружаем флаг оповещений через Postgres LISTEN/NOTIFY (по умолчанию включено)
+	pgNotifyEnabled, err := strconv.ParseBool(getEnvOrDefault("PG_NOTIFY_ENABLED", "true"))
+	if err != nil {
+		log.Printf("Invalid PG_NOTIFY_ENABLED format, using default (true): %v", err)
+		pgNotifyEnabled = true
+	}
+	config.PgNotifyEnabled = pgNotifyEnabled
+
+	// Загружаем интервал доставки вебхуков (по умолчанию 5 секунд)
+	webhookDeliveryIntervalStr := getEnvOrDefault("WEBHOOK_DELIVERY_INTERVAL", "5s")
+	webhookDeliveryInterval, err := time.ParseDuration(webhookDeliveryIntervalStr)
+	if err != nil || webhookDeliveryInterval <= 0 {
+		log.Printf("Invalid WEBHOOK_DELIVERY_INTERVAL format, using default (5s): %v", err)
+		webhookDeliveryInterval = 5 * time.Second
+	}
+	config.WebhookDeliveryInterval = webhookDeliveryInterval
+
+	// Загружаем внутренние адреса, разрешенные для вебхуков (по умолчанию loopback, частные и link-local адреса запрещены)
+	webhookTargets, err := webhooks.ParseTargetPolicy(getEnvOrDefault("WEBHOOK_ALLOWED_PRIVATE_TARGETS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_ALLOWED_PRIVATE_TARGETS: %w", err)
+	}
+	config.WebhookTargets = webhookTargets
+
 	// Загружаем список разрешенных доменов
 	allowedOriginsStr := getEnvOrDefault("ALLOWED_ORIGINS", "*")
 	if allowedOriginsStr == "*" {
@@ -88,6 +211,103 @@ func LoadConfig() (*Config, error) {
 		config.BlacklistedUsers = []string{}
 	}
 
+	// Без доверенных прокси IP клиента (журнал выдачи credentials, rate limit) нельзя подменить заголовком X-Forwarded-For
+	for _, proxy := range strings.Split(getEnvOrDefault("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: expected IP address or CIDR", proxy)
+			}
+		}
+		config.TrustedProxies = append(config.TrustedProxies, proxy)
+	}
+
+	// Администраторы видят журнал выдачи credentials всех задач
+	for _, user := range strings.Split(getEnvOrDefault("ADMIN_USERS", ""), ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			config.AdminUsers = append(config.AdminUsers, user)
+		}
+	}
+
+	// Загружаем ключи шифрования credentials задач
+	if credentialsKeys := getEnvOrDefault("CREDENTIALS_ENCRYPTION_KEYS", ""); credentialsKeys != "" {
+		keyring, err := encryption.ParseKeyring(credentialsKeys, getEnvOrDefault("CREDENTIALS_ENCRYPTION_KEY_ID", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CREDENTIALS_ENCRYPTION_KEYS: %w", err)
+		}
+		config.CredentialsKeyring = keyring
+	} else {
+		log.Println("Warning: CREDENTIALS_ENCRYPTION_KEYS is not set, task credentials are stored unencrypted")
+	}
+
+	// Загружаем хранилище секретов для ссылок secretref://
+	switch secretStore := getEnvOrDefault("SECRET_STORE", ""); secretStore {
+	case "":
+	case "file":
+		if config.CredentialsKeyring == nil {
+			return nil, fmt.Errorf("SECRET_STORE=file requires CREDENTIALS_ENCRYPTION_KEYS")
+		}
+		config.SecretStore = secrets.NewFileStore(getEnvOrDefault("SECRET_STORE_FILE", "secrets.json"), config.CredentialsKeyring)
+	case "env":
+		config.SecretStore = secrets.NewEnvStore(getEnvOrDefault("SECRET_ENV_PREFIX", "ATM_SECRET_"))
+	case "vault":
+		vaultAddr := getEnvOrDefault("VAULT_ADDR", "")
+		if vaultAddr == "" {
+			return nil, fmt.Errorf("SECRET_STORE=vault requires VAULT_ADDR")
+		}
+		config.SecretStore = secrets.NewVaultStore(vaultAddr, getEnvOrDefault("VAULT_TOKEN", ""), getEnvOrDefault("VAULT_KV_MOUNT", "secret"))
+	default:
+		return nil, fmt.Errorf("invalid SECRET_STORE %q: expected file, env or vault", secretStore)
+	}
+
+	// Загружаем каталог, из которого разрешаются ссылки file:///path (по умолчанию ссылки на файлы отключены)
+	var secretFiles *secrets.FileDir
+	if secretFileDir := getEnvOrDefault("SECRET_FILE_DIR", ""); secretFileDir != "" {
+		secretFiles, err = secrets.NewFileDir(secretFileDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SECRET_FILE_DIR: %w", err)
+		}
+	}
+
+	// Загружаем политику доступа к секретам (по умолчанию ссылки на секреты запрещены всем пользователям)
+	secretPolicy, err := secrets.ParseAccessPolicy(getEnvOrDefault("SECRET_ACCESS_POLICY", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECRET_ACCESS_POLICY: %w", err)
+	}
+	if (config.SecretStore != nil || secretFiles != nil) && secretPolicy.IsEmpty() {
+		log.Println("Warning: SECRET_ACCESS_POLICY is not set, secret references in credentials are rejected for all users")
+	}
+	config.SecretResolver = secrets.NewResolver(config.SecretStore, secretFiles, secretPolicy)
+
+	// Загружаем флаг, запрещающий передавать в credentials сами секреты (по умолчанию выключено)
+	requireCredentialReferences, err := strconv.ParseBool(getEnvOrDefault("REQUIRE_CREDENTIAL_REFERENCES", "false"))
+	if err != nil {
+		log.Printf("Invalid REQUIRE_CREDENTIAL_REFERENCES format, using default (false): %v", err)
+		requireCredentialReferences = false
+	}
+	config.RequireCredentialReferences = requireCredentialReferences
+
+	// Загружаем асимметричные ключи подписи JWT (по умолчанию токены подписываются HMAC ключом SECRET_KEY)
+	if signingKeys := getEnvOrDefault("JWT_SIGNING_KEYS", ""); signingKeys != "" {
+		keySet, err := signing.LoadKeySet(signingKeys, getEnvOrDefault("JWT_SIGNING_KEY_ID", ""), getEnvOrDefault("JWT_VERIFICATION_KEYS", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_SIGNING_KEYS: %w", err)
+		}
+		config.JWTKeys = keySet
+	} else if getEnvOrDefault("JWT_VERIFICATION_KEYS", "") != "" {
+		return nil, fmt.Errorf("JWT_VERIFICATION_KEYS requires JWT_SIGNING_KEYS")
+	}
+
+	// Загружаем флаг, разрешающий HMAC токены вместе с асимметричными ключами (по умолчанию выключено)
+	jwtAllowHMAC, err := strconv.ParseBool(getEnvOrDefault("JWT_ALLOW_HMAC", "false"))
+	if err != nil {
+		log.Printf("Invalid JWT_ALLOW_HMAC format, using default (false): %v", err)
+		jwtAllowHMAC = false
+	}
+	config.JWTAllowHMAC = jwtAllowHMAC
+
 	// Проверяем обязательные параметры
 	if config.SecretKey == "" {
 		return nil, fmt.Errorf("SECRET_KEY environment variable is required")
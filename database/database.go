@@ -32,7 +32,15 @@ func InitDB(postgresURL string) error {
 	log.Println("Connected to PostgreSQL database")
 
 	// Автомиграция
-	if err := db.AutoMigrate(&models.Task{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.Task{},
+		&models.TaskEvent{},
+		&models.CredentialAccess{},
+		&models.TaskDependency{},
+		&models.RecurringTask{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 	log.Println("Database migration completed")
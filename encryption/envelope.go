@@ -0,0 +1,130 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKey ключ, которым зашифрована запись, отсутствует в наборе ключей
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Envelope зашифрованные данные вместе с зашифрованным ключом данных и ID ключа, которым он зашифрован
+type Envelope struct {
+	KeyID        string // ID ключа шифрования ключей
+	EncryptedKey []byte // Ключ данных, зашифрованный KEK: nonce || ciphertext
+	Ciphertext   []byte // Данные, зашифрованные ключом данных: nonce || ciphertext
+}
+
+// Seal шифрует plaintext новым ключом данных, а ключ данных - активным KEK.
+// associatedData (например, ID записи) не шифруется, но должна совпасть при расшифровке,
+// поэтому зашифрованные данные нельзя незаметно перенести в другую запись
+func (k *Keyring) Seal(plaintext, associatedData []byte) (*Envelope, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	ciphertext, err := seal(dataKey, plaintext, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey, err := seal(k.keys[k.activeID], dataKey, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		KeyID:        k.activeID,
+		EncryptedKey: encryptedKey,
+		Ciphertext:   ciphertext,
+	}, nil
+}
+
+// Open расшифровывает данные конверта
+func (k *Keyring) Open(envelope *Envelope, associatedData []byte) ([]byte, error) {
+	dataKey, err := k.openDataKey(envelope, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(dataKey, envelope.Ciphertext, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap перешифровывает ключ данных конверта активным KEK, не трогая сами данные.
+// Возвращает false, если конверт уже зашифрован активным ключом
+func (k *Keyring) Rewrap(envelope *Envelope, associatedData []byte) (bool, error) {
+	if envelope.KeyID == k.activeID {
+		return false, nil
+	}
+
+	dataKey, err := k.openDataKey(envelope, associatedData)
+	if err != nil {
+		return false, err
+	}
+	encryptedKey, err := seal(k.keys[k.activeID], dataKey, associatedData)
+	if err != nil {
+		return false, err
+	}
+
+	envelope.KeyID = k.activeID
+	envelope.EncryptedKey = encryptedKey
+	return true, nil
+}
+
+// openDataKey расшифровывает ключ данных конверта ключом, указанным в конверте
+func (k *Keyring) openDataKey(envelope *Envelope, associatedData []byte) ([]byte, error) {
+	kek, ok := k.keys[envelope.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, envelope.KeyID)
+	}
+
+	dataKey, err := open(kek, envelope.EncryptedKey, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with key %q: %w", envelope.KeyID, err)
+	}
+	return dataKey, nil
+}
+
+// seal шифрует plaintext в AES-GCM со случайным nonce, который записывается перед шифротекстом
+func seal(key, plaintext, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// open расшифровывает результат seal
+func open(key, sealed, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
+
+// newGCM создает AES-GCM для ключа
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,162 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey возвращает ключ из повторяющегося байта в base64
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, keySize))
+}
+
+func mustParseKeyring(t *testing.T, spec, activeID string) *Keyring {
+	t.Helper()
+	keyring, err := ParseKeyring(spec, activeID)
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	return keyring
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		activeID string
+		wantID   string
+		wantErr  bool
+	}{
+		{name: "first key is active", spec: "old:" + testKey(1) + ", new:" + testKey(2), wantID: "old"},
+		{name: "explicit active key", spec: "old:" + testKey(1) + ",new:" + testKey(2), activeID: "new", wantID: "new"},
+		{name: "unknown active key", spec: "old:" + testKey(1), activeID: "new", wantErr: true},
+		{name: "no keys", spec: " , ", wantErr: true},
+		{name: "missing id", spec: ":" + testKey(1), wantErr: true},
+		{name: "duplicate id", spec: "k:" + testKey(1) + ",k:" + testKey(2), wantErr: true},
+		{name: "invalid base64", spec: "k:not-base64!", wantErr: true},
+		{name: "short key", spec: "k:" + base64.StdEncoding.EncodeToString([]byte("short")), wantErr: true},
+		{name: "long id", spec: strings.Repeat("k", maxKeyIDLength+1) + ":" + testKey(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := ParseKeyring(tt.spec, tt.activeID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyring: %v", err)
+			}
+			if keyring.ActiveKeyID() != tt.wantID {
+				t.Fatalf("expected active key %q, got %q", tt.wantID, keyring.ActiveKeyID())
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	keyring := mustParseKeyring(t, "k1:"+testKey(1)+",k2:"+testKey(2), "k1")
+	plaintext := []byte(`{"github": {"TOKEN": "secret"}}`)
+	associatedData := []byte("task-1")
+
+	tests := []struct {
+		name           string
+		tamper         func(envelope *Envelope)
+		associatedData []byte
+		ok             bool
+		wantErr        error
+	}{
+		{name: "valid", associatedData: associatedData, ok: true},
+		{name: "unknown key id", tamper: func(e *Envelope) { e.KeyID = "k3" }, associatedData: associatedData, wantErr: ErrUnknownKey},
+		{name: "other key id", tamper: func(e *Envelope) { e.KeyID = "k2" }, associatedData: associatedData},
+		{name: "associated data of another record", associatedData: []byte("task-2")},
+		{name: "tampered ciphertext", tamper: func(e *Envelope) { e.Ciphertext[len(e.Ciphertext)-1] ^= 1 }, associatedData: associatedData},
+		{name: "tampered data key", tamper: func(e *Envelope) { e.EncryptedKey[len(e.EncryptedKey)-1] ^= 1 }, associatedData: associatedData},
+		{name: "truncated ciphertext", tamper: func(e *Envelope) { e.Ciphertext = e.Ciphertext[:4] }, associatedData: associatedData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := keyring.Seal(plaintext, associatedData)
+			if err != nil {
+				t.Fatalf("Seal: %v", err)
+			}
+			if envelope.KeyID != "k1" || bytes.Contains(envelope.Ciphertext, plaintext) {
+				t.Fatalf("unexpected envelope: key %q", envelope.KeyID)
+			}
+			if tt.tamper != nil {
+				tt.tamper(envelope)
+			}
+
+			opened, err := keyring.Open(envelope, tt.associatedData)
+			if tt.ok {
+				if err != nil || !bytes.Equal(opened, plaintext) {
+					t.Fatalf("expected plaintext, got %q, %v", opened, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error, got plaintext %q", opened)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	associatedData := []byte("task-1")
+	oldKeyring := mustParseKeyring(t, "old:"+testKey(1), "")
+	envelope, err := oldKeyring.Seal([]byte("secret"), associatedData)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	ciphertext := bytes.Clone(envelope.Ciphertext)
+
+	// После ротации новый активный ключ стоит в списке рядом со старым
+	rotated := mustParseKeyring(t, "new:"+testKey(2)+",old:"+testKey(1), "new")
+
+	// С чужими associated data ключ данных не расшифровать, конверт не меняется
+	tampered := *envelope
+	if _, err := rotated.Rewrap(&tampered, []byte("task-2")); err == nil {
+		t.Fatal("expected rewrap with wrong associated data to fail")
+	}
+	if tampered.KeyID != "old" {
+		t.Fatalf("expected envelope to keep key %q, got %q", "old", tampered.KeyID)
+	}
+
+	rewrapped, err := rotated.Rewrap(envelope, associatedData)
+	if err != nil || !rewrapped {
+		t.Fatalf("expected envelope to be rewrapped, got %v, %v", rewrapped, err)
+	}
+	if envelope.KeyID != "new" {
+		t.Fatalf("expected key %q, got %q", "new", envelope.KeyID)
+	}
+	if !bytes.Equal(envelope.Ciphertext, ciphertext) {
+		t.Fatal("expected data ciphertext to stay unchanged")
+	}
+
+	// Повторная перешифровка не нужна
+	if rewrapped, err := rotated.Rewrap(envelope, associatedData); err != nil || rewrapped {
+		t.Fatalf("expected no rewrap for active key, got %v, %v", rewrapped, err)
+	}
+
+	// Старый ключ можно удалить из списка
+	newOnly := mustParseKeyring(t, "new:"+testKey(2), "")
+	plaintext, err := newOnly.Open(envelope, associatedData)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("expected secret, got %q, %v", plaintext, err)
+	}
+
+	// Ключ, которым зашифрован конверт, уже удален
+	if _, err := newOnly.Rewrap(&tampered, associatedData); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
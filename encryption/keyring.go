@@ -0,0 +1,79 @@
+// Package encryption шифрует секреты, хранящиеся в БД, по схеме envelope encryption:
+// данные шифруются случайным ключом данных (DEK), а сам ключ данных - ключом шифрования ключей (KEK) из конфигурации.
+// Смена KEK требует перешифровать только ключи данных, но не сами данные
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// keySize размер KEK и DEK в байтах (AES-256)
+const keySize = 32
+
+// maxKeyIDLength максимальная длина ID ключа, хранящегося в каждой зашифрованной записи
+const maxKeyIDLength = 64
+
+// Keyring набор ключей шифрования ключей. Новые данные шифруются активным ключом,
+// остальные ключи нужны, чтобы расшифровать записи, еще не перешифрованные после ротации
+type Keyring struct {
+	keys     map[string][]byte
+	activeID string
+}
+
+// ParseKeyring разбирает список ключей вида "id1:base64,id2:base64" и выбирает активный ключ.
+// Если activeID пуст, активным становится первый ключ списка. Каждый ключ - 32 байта в base64
+func ParseKeyring(spec, activeID string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string][]byte)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid key entry %q: expected id:base64key", entry)
+		}
+		if len(id) > maxKeyIDLength {
+			return nil, fmt.Errorf("key id %q is longer than %d characters", id, maxKeyIDLength)
+		}
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+
+		keyring.keys[id] = key
+		if keyring.activeID == "" {
+			keyring.activeID = id
+		}
+	}
+
+	if len(keyring.keys) == 0 {
+		return nil, errors.New("no keys configured")
+	}
+	if activeID != "" {
+		if _, ok := keyring.keys[activeID]; !ok {
+			return nil, fmt.Errorf("active key %q is not in the key list", activeID)
+		}
+		keyring.activeID = activeID
+	}
+
+	return keyring, nil
+}
+
+// ActiveKeyID возвращает ID ключа, которым шифруются новые данные
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
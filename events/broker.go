@@ -0,0 +1,305 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"agent-task-manager/models"
+
+	"github.com/google/uuid"
+)
+
+// EventType тип события жизненного цикла задачи
+type EventType string
+
+const (
+	EventCreated       EventType = "created"        // Задача создана
+	EventClaimed       EventType = "claimed"        // Задача взята в работу исполнителем
+	EventCompleted     EventType = "completed"      // Задача завершена
+	EventFailed        EventType = "failed"         // Попытка выполнения задачи неудачна
+	EventCanceled      EventType = "canceled"       // Задача отменена (в том числе каскадно)
+	EventWaiting       EventType = "waiting"        // Родительская задача ожидает завершения созданной подзадачи
+	EventRejected      EventType = "rejected"       // Исполнитель отклонил задачу
+	EventInputRequired EventType = "input-required" // Исполнитель запросил дополнительные данные у создателя
+	EventInputProvided EventType = "input-provided" // Создатель ответил на запрос, задача вернулась в очередь
+	EventResubmitted   EventType = "resubmitted"    // Родительская задача вернулась в submitted после завершения подзадач
+	EventRequeued      EventType = "requeued"       // Аренда задачи истекла, задача вернулась в очередь
+	EventRedriven      EventType = "redriven"       // Задача перезапущена из dead-letter
+	EventUnblocked     EventType = "unblocked"      // Все зависимости задачи завершены, задача вернулась в submitted
+)
+
+// Types все типы событий жизненного цикла задачи
+var Types = []EventType{
+	EventCreated,
+	EventClaimed,
+	EventCompleted,
+	EventFailed,
+	EventCanceled,
+	EventWaiting,
+	EventRejected,
+	EventInputRequired,
+	EventInputProvided,
+	EventResubmitted,
+	EventRequeued,
+	EventRedriven,
+	EventUnblocked,
+}
+
+// IsValidType проверяет, что тип события известен
+func IsValidType(eventType string) bool {
+	for _, t := range Types {
+		if string(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// subscriberBufferSize размер очереди событий подписчика; при переполнении подписка закрывается
+	subscriberBufferSize = 64
+	// maxReplayEvents максимальное количество пропущенных событий, отправляемых из журнала при возобновлении потока.
+	// Если пропущено больше, клиенту нужно заново загрузить дерево
+	maxReplayEvents = 1000
+	// recentEventsSize количество ID последних разосланных событий дерева, по которым отбрасываются повторы:
+	// своя реплика получает событие и при публикации, и через Postgres NOTIFY
+	recentEventsSize = 1024
+)
+
+// Event событие изменения задачи в дереве корневой задачи. ID - ID строки журнала task_events
+type Event struct {
+	ID         int64             `json:"id"`
+	Type       EventType         `json:"type"`
+	RootTaskID uuid.UUID         `json:"root_task_id"`
+	TaskID     uuid.UUID         `json:"task_id"`
+	Status     models.TaskStatus `json:"status"`
+	FromStatus models.TaskStatus `json:"from_status,omitempty"` // Статус до перехода, пусто для создания
+	Actor      string            `json:"actor"`                 // user_id инициатора или system
+	Task       models.Task       `json:"task"`                  // Задача без credentials
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// subscriber подписчик событий дерева
+type subscriber struct {
+	ch       chan Event
+	ready    bool               // Пропущенные события загружены, новые события отправляются в канал
+	pending  []Event            // События, пришедшие до загрузки пропущенных событий
+	replayed map[int64]struct{} // ID событий, уже отправленных из журнала
+}
+
+// rootStream подписчики дерева задач и ID последних разосланных событий
+type rootStream struct {
+	subscribers map[*subscriber]struct{}
+	recent      map[int64]struct{}
+	recentOrder []int64
+}
+
+// Broker рассылает события задач подписчикам деревьев в текущем процессе. События других реплик приходят
+// через Postgres LISTEN/NOTIFY, а пропущенные клиентом события загружаются из журнала task_events
+type Broker struct {
+	mu    sync.Mutex
+	roots map[uuid.UUID]*rootStream
+}
+
+// ReplayLoader загружает из журнала до limit событий дерева с ID больше afterID в порядке ID
+type ReplayLoader func(afterID int64, limit int) ([]Event, error)
+
+// Global instance
+var broker = &Broker{
+	roots: make(map[uuid.UUID]*rootStream),
+}
+
+// NewEvent создает событие перехода задачи из статуса fromStatus, выполненного actor.
+// Задача сохраняется в событии без credentials (models.Task.Redacted)
+func NewEvent(eventType EventType, task models.Task, fromStatus models.TaskStatus, actor string) Event {
+	rootTaskID := task.ID
+	if task.RootTaskID != nil {
+		rootTaskID = *task.RootTaskID
+	}
+
+	task = task.Redacted()
+
+	return Event{
+		Type:       eventType,
+		RootTaskID: rootTaskID,
+		TaskID:     task.ID,
+		Status:     task.Status,
+		FromStatus: fromStatus,
+		Actor:      actor,
+		Task:       task,
+		OccurredAt: time.Now(),
+	}
+}
+
+// Publish рассылает записанные в журнал события подписчикам деревьев в текущем процессе.
+// Вызывается после коммита транзакции; подписчики других реплик получают события через Postgres NOTIFY
+func Publish(events ...Event) {
+	deliver(events)
+}
+
+// deliver рассылает события подписчикам их деревьев, пропуская уже разосланные
+func deliver(events []Event) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for _, event := range events {
+		stream := broker.roots[event.RootTaskID]
+		if stream == nil || !stream.remember(event.ID) {
+			continue
+		}
+		for sub := range stream.subscribers {
+			stream.send(sub, event)
+		}
+	}
+}
+
+// remember запоминает ID события и сообщает, что событие еще не рассылалось. Вызывается под блокировкой
+func (s *rootStream) remember(id int64) bool {
+	if _, seen := s.recent[id]; seen {
+		return false
+	}
+
+	s.recent[id] = struct{}{}
+	s.recentOrder = append(s.recentOrder, id)
+	if len(s.recentOrder) > recentEventsSize {
+		delete(s.recent, s.recentOrder[0])
+		s.recentOrder = s.recentOrder[1:]
+	}
+	return true
+}
+
+// send передает событие подписчику. До загрузки пропущенных событий оно откладывается,
+// а события, уже отправленные из журнала, пропускаются. Вызывается под блокировкой
+func (s *rootStream) send(sub *subscriber, event Event) {
+	if !sub.ready {
+		if len(sub.pending) >= subscriberBufferSize {
+			s.drop(sub)
+			return
+		}
+		sub.pending = append(sub.pending, event)
+		return
+	}
+
+	if _, replayed := sub.replayed[event.ID]; replayed {
+		return
+	}
+
+	select {
+	case sub.ch <- event:
+	default:
+		// Подписчик не успевает читать - закрываем подписку, клиент переподключится с Last-Event-ID
+		s.drop(sub)
+	}
+}
+
+// drop закрывает подписку. Вызывается под блокировкой
+func (s *rootStream) drop(sub *subscriber) {
+	if _, exists := s.subscribers[sub]; exists {
+		delete(s.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscribe подписывает на события дерева корневой задачи. Если lastEventID > 0, события после него загружаются
+// функцией load из журнала task_events уже после регистрации подписчика, поэтому между ними ничего не теряется.
+// Возвращает пропущенные события, признак того, что отправлены все пропущенные события (иначе клиенту нужно
+// заново загрузить дерево), канал новых событий без уже отправленных из журнала (закрывается при переполнении)
+// и функцию отписки
+func Subscribe(rootTaskID uuid.UUID, lastEventID int64, load ReplayLoader) ([]Event, bool, <-chan Event, func(), error) {
+	sub := &subscriber{ch: make(chan Event, subscriberBufferSize)}
+
+	broker.mu.Lock()
+	stream := broker.roots[rootTaskID]
+	if stream == nil {
+		stream = &rootStream{
+			subscribers: make(map[*subscriber]struct{}),
+			recent:      make(map[int64]struct{}),
+		}
+		broker.roots[rootTaskID] = stream
+	}
+	stream.subscribers[sub] = struct{}{}
+	broker.mu.Unlock()
+
+	unsubscribe := func() {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+
+		stream.drop(sub)
+		if len(stream.subscribers) == 0 && broker.roots[rootTaskID] == stream {
+			delete(broker.roots, rootTaskID)
+		}
+	}
+
+	var replay []Event
+	complete := true
+	if lastEventID > 0 && load != nil {
+		loaded, err := load(lastEventID, maxReplayEvents+1)
+		if err != nil {
+			unsubscribe()
+			return nil, false, nil, nil, err
+		}
+		if len(loaded) > maxReplayEvents {
+			complete = false
+		} else {
+			replay = loaded
+		}
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	sub.replayed = make(map[int64]struct{}, len(replay))
+	for _, event := range replay {
+		sub.replayed[event.ID] = struct{}{}
+	}
+	sub.ready = true
+
+	// Отправляем события, пришедшие во время загрузки журнала
+	pending := sub.pending
+	sub.pending = nil
+	for _, event := range pending {
+		if _, active := stream.subscribers[sub]; !active {
+			break
+		}
+		stream.send(sub, event)
+	}
+
+	return replay, complete, sub.ch, unsubscribe, nil
+}
+
+// hasSubscribers сообщает, что у дерева есть подписчики в текущем процессе
+func hasSubscribers(rootTaskID uuid.UUID) bool {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	return broker.roots[rootTaskID] != nil
+}
+
+// DropSubscribers закрывает все подписки процесса. Клиенты переподключаются с Last-Event-ID и получают из журнала
+// события, оповещения о которых могли быть потеряны, пока слушатель Postgres был отключен
+func DropSubscribers() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for rootTaskID := range broker.roots {
+		broker.dropRoot(rootTaskID)
+	}
+}
+
+// dropRoot закрывает подписки дерева задач
+func dropRoot(rootTaskID uuid.UUID) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	broker.dropRoot(rootTaskID)
+}
+
+// dropRoot закрывает подписки дерева задач. Вызывается под блокировкой
+func (b *Broker) dropRoot(rootTaskID uuid.UUID) {
+	stream := b.roots[rootTaskID]
+	if stream == nil {
+		return
+	}
+	for sub := range stream.subscribers {
+		stream.drop(sub)
+	}
+	delete(b.roots, rootTaskID)
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"agent-task-manager/models"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ActorSystem инициатор переходов, выполняемых планировщиками
+const ActorSystem = "system"
+
+// Recorder получает события, записанные в журнал, с ID строк task_events и пишет свои данные в той же транзакции,
+// например доставки вебхуков. Ошибка откатывает переход вместе с журналом
+type Recorder func(tx *gorm.DB, events []Event) error
+
+// recorders получатели событий внутри транзакции перехода
+var (
+	recordersMu sync.RWMutex
+	recorders   []Recorder
+)
+
+// RegisterRecorder регистрирует получателя событий, вызываемого при записи журнала
+func RegisterRecorder(recorder Recorder) {
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+	recorders = append(recorders, recorder)
+}
+
+// TaskEvent возвращает строку журнала task_events для события со снимком задачи
+func (e Event) TaskEvent() (models.TaskEvent, error) {
+	snapshot, err := json.Marshal(e.Task)
+	if err != nil {
+		return models.TaskEvent{}, fmt.Errorf("failed to encode task snapshot: %w", err)
+	}
+
+	return models.TaskEvent{
+		CreatedAt:    e.OccurredAt,
+		TaskID:       e.TaskID,
+		RootTaskID:   e.RootTaskID,
+		Type:         string(e.Type),
+		FromStatus:   e.FromStatus,
+		ToStatus:     e.Status,
+		Actor:        e.Actor,
+		TaskSnapshot: snapshot,
+	}, nil
+}
+
+// FromTaskEvent восстанавливает событие из строки журнала task_events.
+// У строк, записанных до появления снимков, задача содержит только ID и статус
+func FromTaskEvent(row models.TaskEvent) (Event, error) {
+	event := Event{
+		ID:         row.ID,
+		Type:       EventType(row.Type),
+		RootTaskID: row.RootTaskID,
+		TaskID:     row.TaskID,
+		Status:     row.ToStatus,
+		FromStatus: row.FromStatus,
+		Actor:      row.Actor,
+		Task:       models.Task{ID: row.TaskID, Status: row.ToStatus},
+		OccurredAt: row.CreatedAt,
+	}
+
+	if len(row.TaskSnapshot) > 0 {
+		if err := json.Unmarshal(row.TaskSnapshot, &event.Task); err != nil {
+			return Event{}, fmt.Errorf("failed to decode task snapshot of event %d: %w", row.ID, err)
+		}
+	}
+	return event, nil
+}
+
+// Record записывает события в журнал task_events, присваивает им ID строк журнала
+// и передает их зарегистрированным получателям.
+// Вызывается внутри транзакции перехода, чтобы журнал и доставки не расходились с состоянием задач
+func Record(tx *gorm.DB, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]models.TaskEvent, len(events))
+	for i, event := range events {
+		row, err := event.TaskEvent()
+		if err != nil {
+			return err
+		}
+		rows[i] = row
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return err
+	}
+
+	for i := range events {
+		events[i].ID = rows[i].ID
+	}
+
+	recordersMu.RLock()
+	defer recordersMu.RUnlock()
+	for _, recorder := range recorders {
+		if err := recorder(tx, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
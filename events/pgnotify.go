@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// NotifyChannel канал Postgres NOTIFY, в который отправляются ID записанных событий
+	NotifyChannel = "task_events"
+	// maxNotifyIDs максимальное количество ID событий в одном оповещении (payload NOTIFY ограничен 8000 байт)
+	maxNotifyIDs = 200
+	// loadTimeout таймаут загрузки событий из журнала по оповещению
+	loadTimeout = 10 * time.Second
+)
+
+// EventLoader загружает события журнала task_events по ID
+type EventLoader func(ctx context.Context, ids []int64) ([]Event, error)
+
+// EnablePgNotify включает рассылку событий между репликами: при записи журнала в той же транзакции отправляется
+// Postgres NOTIFY с ID событий, и Postgres доставляет его слушателям только после коммита
+func EnablePgNotify() {
+	RegisterRecorder(notifyRecorded)
+}
+
+// notifyRecorded отправляет оповещения вида "<root_task_id>:<id>,<id>" о событиях, записанных в журнал
+func notifyRecorded(tx *gorm.DB, events []Event) error {
+	var roots []uuid.UUID
+	idsByRoot := make(map[uuid.UUID][]string)
+	for _, event := range events {
+		if _, exists := idsByRoot[event.RootTaskID]; !exists {
+			roots = append(roots, event.RootTaskID)
+		}
+		idsByRoot[event.RootTaskID] = append(idsByRoot[event.RootTaskID], strconv.FormatInt(event.ID, 10))
+	}
+
+	for _, rootTaskID := range roots {
+		ids := idsByRoot[rootTaskID]
+		for start := 0; start < len(ids); start += maxNotifyIDs {
+			end := min(start+maxNotifyIDs, len(ids))
+			payload := rootTaskID.String() + ":" + strings.Join(ids[start:end], ",")
+			if err := tx.Exec("SELECT pg_notify(?, ?)", NotifyChannel, payload).Error; err != nil {
+				return fmt.Errorf("failed to notify task events: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseNotification разбирает payload оповещения о событиях
+func parseNotification(payload string) (uuid.UUID, []int64, error) {
+	root, list, ok := strings.Cut(payload, ":")
+	if !ok {
+		return uuid.Nil, nil, fmt.Errorf("invalid task events notification %q", payload)
+	}
+
+	rootTaskID, err := uuid.Parse(root)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid root task id in notification %q: %w", payload, err)
+	}
+
+	var ids []int64
+	for _, value := range strings.Split(list, ",") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return uuid.Nil, nil, fmt.Errorf("invalid event id in notification %q: %w", payload, err)
+		}
+		ids = append(ids, id)
+	}
+	return rootTaskID, ids, nil
+}
+
+// NotificationHandler возвращает обработчик оповещений канала NotifyChannel: события деревьев, на которые есть
+// подписчики в текущем процессе, загружаются из журнала и рассылаются им. События, уже разосланные
+// при публикации в этом процессе, повторно не отправляются
+func NotificationHandler(load EventLoader) func(payload string) {
+	return func(payload string) {
+		rootTaskID, ids, err := parseNotification(payload)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			return
+		}
+		if !hasSubscribers(rootTaskID) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+		defer cancel()
+
+		loaded, err := load(ctx, ids)
+		if err != nil {
+			// Подписчики могли пропустить события - закрываем их потоки, клиенты догрузят события из журнала
+			log.Printf("Warning: failed to load notified task events, dropping subscribers of tree %s: %v", rootTaskID, err)
+			dropRoot(rootTaskID)
+			return
+		}
+		deliver(loaded)
+	}
+}
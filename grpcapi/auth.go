@@ -0,0 +1,93 @@
+package grpcapi
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/handlers"
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// userIDKey ключ user_id в контексте вызова
+type userIDKey struct{}
+
+// authenticate проверяет JWT из метаданных authorization так же, как JwtAuthMiddleware проверяет заголовок Authorization,
+// и возвращает контекст с user_id
+func authenticate(ctx context.Context, cfg *config.Config) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	// Проверяем формат Bearer токена
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format. Use: Bearer <token>")
+	}
+
+	claims, err := handlers.ParseToken(cfg, parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(ctx, userIDKey{}, claims.UserID), nil
+}
+
+// userIDFromContext возвращает user_id, сохраненный интерцептором аутентификации
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// clientIPFromContext возвращает IP адрес клиента вызова, пустую строку - если он неизвестен
+func clientIPFromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// unaryAuthInterceptor аутентифицирует унарные вызовы
+func unaryAuthInterceptor(cfg *config.Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticatedStream поток с контекстом, содержащим user_id
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока с user_id
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamAuthInterceptor аутентифицирует потоковые вызовы
+func streamAuthInterceptor(cfg *config.Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), cfg)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
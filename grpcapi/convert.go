@@ -0,0 +1,248 @@
+package grpcapi
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/grpcapi/taskmanagerv1"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errorDomain домен ошибок в ErrorInfo
+const errorDomain = "agent-task-manager"
+
+// errorCodes коды gRPC видов ошибок сервиса задач
+var errorCodes = map[service.ErrorKind]codes.Code{
+	service.KindInvalid:   codes.InvalidArgument,
+	service.KindForbidden: codes.PermissionDenied,
+	service.KindNotFound:  codes.NotFound,
+	service.KindConflict:  codes.FailedPrecondition,
+}
+
+// toStatusError переводит ошибку сервиса задач в статус gRPC.
+// Дополнительные поля ответа (current_status, parent_status) передаются в ErrorInfo.Metadata
+func toStatusError(err error) error {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := errorCodes[serviceErr.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, serviceErr.Message)
+	if len(serviceErr.Fields) == 0 {
+		return st.Err()
+	}
+
+	metadata := make(map[string]string, len(serviceErr.Fields))
+	for key, value := range serviceErr.Fields {
+		metadata[key] = fmt.Sprint(value)
+	}
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   code.String(),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// parseUUID разбирает обязательный UUID из запроса
+func parseUUID(value, field string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s format", field)
+	}
+	return id, nil
+}
+
+// parseOptionalUUID разбирает необязательный UUID из запроса, пустая строка - nil
+func parseOptionalUUID(value, field string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := parseUUID(value, field)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseUUIDs разбирает список UUID из запроса
+func parseUUIDs(values []string, field string) ([]uuid.UUID, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(values))
+	for i, value := range values {
+		id, err := parseUUID(value, field)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// uuidStrings переводит список UUID в строки
+func uuidStrings(ids []uuid.UUID) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+// timestamp переводит необязательное время в Timestamp
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// optionalTime переводит необязательный Timestamp во время
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// optionalUUIDString переводит необязательный UUID в строку, nil - пустая строка
+func optionalUUIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// toCredentials переводит credentials задачи в сообщения по сервисам
+func toCredentials(raw json.RawMessage) map[string]*taskmanagerv1.ServiceCredentials {
+	var credentials map[string]map[string]string
+	if len(raw) == 0 || json.Unmarshal(raw, &credentials) != nil || len(credentials) == 0 {
+		return nil
+	}
+
+	result := make(map[string]*taskmanagerv1.ServiceCredentials, len(credentials))
+	for serviceName, env := range credentials {
+		result[serviceName] = &taskmanagerv1.ServiceCredentials{Env: env}
+	}
+	return result
+}
+
+// fromCredentials переводит credentials запроса в JSON в формате REST API: { "service": { "ENV_VAR": "value" } }
+func fromCredentials(credentials map[string]*taskmanagerv1.ServiceCredentials) (json.RawMessage, error) {
+	if len(credentials) == 0 {
+		return nil, nil
+	}
+
+	env := make(map[string]map[string]string, len(credentials))
+	for serviceName, serviceCredentials := range credentials {
+		env[serviceName] = serviceCredentials.GetEnv()
+	}
+	return json.Marshal(env)
+}
+
+// toTask переводит задачу в сообщение без credentials (models.Task.Redacted):
+// credentials передаются только в ответе Claim
+func toTask(task models.Task) *taskmanagerv1.Task {
+	task = task.Redacted()
+	return &taskmanagerv1.Task{
+		Id:             task.ID.String(),
+		CreatedAt:      timestamppb.New(task.CreatedAt),
+		DeleteAt:       timestamp(task.DeleteAt),
+		CreatedBy:      task.CreatedBy,
+		Assignee:       task.Assignee,
+		Description:    task.Description,
+		RootTaskId:     optionalUUIDString(task.RootTaskID),
+		ParentTaskId:   optionalUUIDString(task.ParentTaskID),
+		Result:         task.Result,
+		Status:         string(task.Status),
+		Priority:       int32(task.Priority),
+		RunAt:          timestamp(task.RunAt),
+		Scheduled:      task.IsScheduled(time.Now()),
+		LeaseExpiresAt: timestamp(task.LeaseExpiresAt),
+		RequeueCount:   int32(task.RequeueCount),
+		MaxAttempts:    int32(task.MaxAttempts),
+		Attempts:       int32(task.Attempts),
+		NotBefore:      timestamp(task.NotBefore),
+		InputQuestion:  task.InputQuestion,
+		InputAnswer:    task.InputAnswer,
+		DependsOn:      uuidStrings(task.DependsOn),
+		OnChildFailure: string(task.OnChildFailure),
+		Input:          string(task.Input),
+		Output:         string(task.Output),
+		InputSchema:    string(task.InputSchema),
+		OutputSchema:   string(task.OutputSchema),
+	}
+}
+
+// toTasks переводит список задач в сообщения
+func toTasks(tasks []models.Task) []*taskmanagerv1.Task {
+	result := make([]*taskmanagerv1.Task, len(tasks))
+	for i, task := range tasks {
+		result[i] = toTask(task)
+	}
+	return result
+}
+
+// rawJSON переводит JSON-текст из сообщения в json.RawMessage, пустая строка означает отсутствие значения
+func rawJSON(text string) json.RawMessage {
+	if text == "" {
+		return nil
+	}
+	return json.RawMessage(text)
+}
+
+// toTaskNodes переводит дерево подзадач в сообщения
+func toTaskNodes(nodes []service.TaskNode) []*taskmanagerv1.TaskNode {
+	result := make([]*taskmanagerv1.TaskNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = &taskmanagerv1.TaskNode{
+			Task:     toTask(node.Task),
+			Children: toTaskNodes(node.Children),
+		}
+	}
+	return result
+}
+
+// toTaskEvent переводит событие дерева в сообщение
+func toTaskEvent(event events.Event) *taskmanagerv1.TaskEvent {
+	return &taskmanagerv1.TaskEvent{
+		Id:         event.ID,
+		Type:       string(event.Type),
+		RootTaskId: event.RootTaskID.String(),
+		TaskId:     event.TaskID.String(),
+		Status:     string(event.Status),
+		FromStatus: string(event.FromStatus),
+		Actor:      event.Actor,
+		Task:       toTask(event.Task),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+}
+
+// eventResponse оборачивает событие дерева в сообщение потока
+func eventResponse(event events.Event) *taskmanagerv1.WatchTreeResponse {
+	return &taskmanagerv1.WatchTreeResponse{
+		Payload: &taskmanagerv1.WatchTreeResponse_Event{Event: toTaskEvent(event)},
+	}
+}
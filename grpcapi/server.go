@@ -0,0 +1,276 @@
+package grpcapi
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/events"
+	"agent-task-manager/grpcapi/taskmanagerv1"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server реализация gRPC сервиса TaskManager поверх сервиса задач
+type Server struct {
+	taskmanagerv1.UnimplementedTaskManagerServer
+
+	svc *service.TaskService
+}
+
+// NewServer создает gRPC сервер с сервисом TaskManager и JWT аутентификацией через интерцепторы
+func NewServer(cfg *config.Config, svc *service.TaskService) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAuthInterceptor(cfg)),
+		grpc.StreamInterceptor(streamAuthInterceptor(cfg)),
+	)
+	taskmanagerv1.RegisterTaskManagerServer(grpcServer, &Server{svc: svc})
+	return grpcServer
+}
+
+// Create создает задачу
+func (s *Server) Create(ctx context.Context, req *taskmanagerv1.CreateRequest) (*taskmanagerv1.Task, error) {
+	if req.GetDescription() == "" {
+		return nil, status.Error(codes.InvalidArgument, "description is required")
+	}
+
+	parentTaskID, err := parseOptionalUUID(req.GetParentTaskId(), "parent task id")
+	if err != nil {
+		return nil, err
+	}
+
+	dependsOn, err := parseUUIDs(req.GetDependsOn(), "depends on task id")
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := fromCredentials(req.GetCredentials())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid credentials: "+err.Error())
+	}
+
+	createReq := service.CreateTaskRequest{
+		Description:  req.GetDescription(),
+		Assignee:     req.GetAssignee(),
+		ParentTaskID: parentTaskID,
+		DeleteAt:     optionalTime(req.GetDeleteAt()),
+		Credentials:  credentials,
+		RunAt:        optionalTime(req.GetRunAt()),
+		DependsOn:    dependsOn,
+
+		InheritCredentials: req.GetInheritCredentials(),
+
+		OnChildFailure: models.ChildFailurePolicy(req.GetOnChildFailure()),
+
+		Input:        rawJSON(req.GetInput()),
+		InputSchema:  rawJSON(req.GetInputSchema()),
+		OutputSchema: rawJSON(req.GetOutputSchema()),
+	}
+	if req.RetryPolicy != nil {
+		createReq.RetryPolicy = &service.RetryPolicy{
+			MaxAttempts:        int(req.RetryPolicy.GetMaxAttempts()),
+			BackoffBaseSeconds: int(req.RetryPolicy.GetBackoffBaseSeconds()),
+			MaxDelaySeconds:    int(req.RetryPolicy.GetMaxDelaySeconds()),
+		}
+	}
+	if req.Priority != nil {
+		priority := int(req.GetPriority())
+		createReq.Priority = &priority
+	}
+
+	task, err := s.svc.Create(ctx, userIDFromContext(ctx), createReq)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toTask(*task), nil
+}
+
+// Claim берет в работу следующую задачу пользователя, ожидая её до wait_seconds
+func (s *Server) Claim(ctx context.Context, req *taskmanagerv1.ClaimRequest) (*taskmanagerv1.ClaimResponse, error) {
+	if req.GetWaitSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "wait_seconds cannot be negative")
+	}
+
+	opts := service.ClaimOptions{
+		ChildrenDepth: int(req.GetChildrenDepth()),
+		ClientIP:      clientIPFromContext(ctx),
+		Protocol:      "grpc",
+	}
+	response, err := s.svc.WaitForTask(ctx, userIDFromContext(ctx), time.Duration(req.GetWaitSeconds())*time.Second, opts)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, toStatusError(err)
+	}
+
+	// Credentials раскрываются только исполнителю в ответе Claim
+	task := toTask(response.Task)
+	task.Credentials = toCredentials(response.Credentials)
+
+	return &taskmanagerv1.ClaimResponse{
+		Task:              task,
+		CompletedSubtasks: toTasks(response.CompletedSubtasks),
+		RejectedSubtasks:  toTasks(response.RejectedSubtasks),
+		FailedSubtasks:    toTasks(response.FailedSubtasks),
+		Children:          toTaskNodes(response.Children),
+	}, nil
+}
+
+// Heartbeat продлевает аренду задачи в работе
+func (s *Server) Heartbeat(ctx context.Context, req *taskmanagerv1.HeartbeatRequest) (*taskmanagerv1.Task, error) {
+	taskID, err := parseUUID(req.GetTaskId(), "task id")
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.svc.Heartbeat(ctx, userIDFromContext(ctx), taskID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toTask(*task), nil
+}
+
+// Complete завершает задачу
+func (s *Server) Complete(ctx context.Context, req *taskmanagerv1.CompleteRequest) (*taskmanagerv1.Task, error) {
+	taskID, err := parseUUID(req.GetTaskId(), "task id")
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDescription() == "" {
+		return nil, status.Error(codes.InvalidArgument, "description is required")
+	}
+
+	task, err := s.svc.Complete(ctx, userIDFromContext(ctx), taskID, service.CompleteTaskRequest{
+		Description: req.GetDescription(),
+		Output:      rawJSON(req.GetOutput()),
+		DeleteAt:    optionalTime(req.GetDeleteAt()),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toTask(*task), nil
+}
+
+// Fail помечает задачу как неудачную
+func (s *Server) Fail(ctx context.Context, req *taskmanagerv1.FailRequest) (*taskmanagerv1.Task, error) {
+	taskID, err := parseUUID(req.GetTaskId(), "task id")
+	if err != nil {
+		return nil, err
+	}
+	if req.GetReason() == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	task, err := s.svc.Fail(ctx, userIDFromContext(ctx), taskID, service.FailTaskRequest{Reason: req.GetReason()})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toTask(*task), nil
+}
+
+// Cancel отменяет задачу и её активные подзадачи
+func (s *Server) Cancel(ctx context.Context, req *taskmanagerv1.CancelRequest) (*taskmanagerv1.Task, error) {
+	taskID, err := parseUUID(req.GetTaskId(), "task id")
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.svc.Cancel(ctx, userIDFromContext(ctx), taskID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toTask(*task), nil
+}
+
+// ListRootTasks возвращает корневые задачи пользователя
+func (s *Server) ListRootTasks(ctx context.Context, _ *taskmanagerv1.ListRootTasksRequest) (*taskmanagerv1.ListRootTasksResponse, error) {
+	tasks, err := s.svc.ListRootTasks(ctx, userIDFromContext(ctx))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &taskmanagerv1.ListRootTasksResponse{Tasks: toTasks(tasks)}, nil
+}
+
+// GetTree возвращает все задачи дерева корневой задачи
+func (s *Server) GetTree(ctx context.Context, req *taskmanagerv1.GetTreeRequest) (*taskmanagerv1.GetTreeResponse, error) {
+	rootTaskID, err := parseUUID(req.GetRootTaskId(), "root task id")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.svc.GetTree(ctx, userIDFromContext(ctx), rootTaskID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &taskmanagerv1.GetTreeResponse{Tasks: toTasks(tasks)}, nil
+}
+
+// WatchTree транслирует события дерева корневой задачи, пока клиент не отменит вызов.
+// Если подписка закрыта из-за переполнения или переподключения к Postgres, поток завершается со статусом Unavailable,
+// и клиент переподключается с last_event_id
+func (s *Server) WatchTree(req *taskmanagerv1.WatchTreeRequest, stream taskmanagerv1.TaskManager_WatchTreeServer) error {
+	ctx := stream.Context()
+
+	rootTaskID, err := parseUUID(req.GetRootTaskId(), "root task id")
+	if err != nil {
+		return err
+	}
+	if req.GetLastEventId() < 0 {
+		return status.Error(codes.InvalidArgument, "invalid last event id")
+	}
+
+	// Проверяем, что root задача существует и создана текущим пользователем
+	if _, err := s.svc.GetRootTask(ctx, userIDFromContext(ctx), rootTaskID); err != nil {
+		return toStatusError(err)
+	}
+
+	// Пропущенные события загружаются из журнала task_events
+	replay, complete, updates, unsubscribe, err := events.Subscribe(rootTaskID, req.GetLastEventId(), func(afterID int64, limit int) ([]events.Event, error) {
+		return s.svc.RootTaskEventsSince(ctx, rootTaskID, afterID, limit)
+	})
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer unsubscribe()
+
+	// Отправляем заголовки сразу после подписки: получив их, клиент знает, что события не будут пропущены
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	// Часть пропущенных событий недоступна - клиенту нужно заново загрузить дерево через GetTree
+	if !complete {
+		if err := stream.Send(&taskmanagerv1.WatchTreeResponse{
+			Payload: &taskmanagerv1.WatchTreeResponse_Resync{Resync: &taskmanagerv1.Resync{
+				Reason: "some events since last_event_id are no longer available, reload the task tree",
+			}},
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, event := range replay {
+		if err := stream.Send(eventResponse(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, "subscription closed, reconnect with last_event_id")
+			}
+			if err := stream.Send(eventResponse(event)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
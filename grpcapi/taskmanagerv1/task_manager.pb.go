@@ -0,0 +1,1663 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: taskmanager/v1/task_manager.proto
+
+// Сервис TaskManager повторяет операции REST API над задачами.
+// Аутентификация: метаданные authorization со значением "Bearer <JWT>", как в заголовке Authorization
+
+package taskmanagerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ServiceCredentials переменные окружения одного сервиса
+type ServiceCredentials struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Env           map[string]string      `protobuf:"bytes,1,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceCredentials) Reset() {
+	*x = ServiceCredentials{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceCredentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceCredentials) ProtoMessage() {}
+
+func (x *ServiceCredentials) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceCredentials.ProtoReflect.Descriptor instead.
+func (*ServiceCredentials) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServiceCredentials) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+// Task задача. Статусы: submitted, working, waiting, input-required, completed, failed, canceled, rejected, dead-lettered
+type Task struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DeleteAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=delete_at,json=deleteAt,proto3" json:"delete_at,omitempty"`
+	CreatedBy    string                 `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Assignee     string                 `protobuf:"bytes,5,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Description  string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	RootTaskId   string                 `protobuf:"bytes,7,opt,name=root_task_id,json=rootTaskId,proto3" json:"root_task_id,omitempty"`
+	ParentTaskId string                 `protobuf:"bytes,8,opt,name=parent_task_id,json=parentTaskId,proto3" json:"parent_task_id,omitempty"`
+	Result       string                 `protobuf:"bytes,9,opt,name=result,proto3" json:"result,omitempty"`
+	// Credentials по сервисам (собственные и унаследованные от родителя) заполняются только в ответе Claim
+	Credentials map[string]*ServiceCredentials `protobuf:"bytes,10,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status      string                         `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`
+	Priority    int32                          `protobuf:"varint,12,opt,name=priority,proto3" json:"priority,omitempty"`
+	RunAt       *timestamppb.Timestamp         `protobuf:"bytes,13,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	// Задача в статусе submitted, но её время run_at или повторной попытки not_before еще не наступило
+	Scheduled      bool                   `protobuf:"varint,14,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	LeaseExpiresAt *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"`
+	RequeueCount   int32                  `protobuf:"varint,16,opt,name=requeue_count,json=requeueCount,proto3" json:"requeue_count,omitempty"`
+	MaxAttempts    int32                  `protobuf:"varint,17,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	Attempts       int32                  `protobuf:"varint,18,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	NotBefore      *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	InputQuestion  string                 `protobuf:"bytes,20,opt,name=input_question,json=inputQuestion,proto3" json:"input_question,omitempty"`
+	InputAnswer    string                 `protobuf:"bytes,21,opt,name=input_answer,json=inputAnswer,proto3" json:"input_answer,omitempty"`
+	// Задачи, от которых зависит задача; заполняется в Create и GetTree
+	DependsOn []string `protobuf:"bytes,22,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// Реакция на окончательную неудачу подзадачи: wait, resubmit_parent, fail_parent или cancel_siblings
+	OnChildFailure string `protobuf:"bytes,23,opt,name=on_child_failure,json=onChildFailure,proto3" json:"on_child_failure,omitempty"`
+	// Структурированные входные данные, результат и их JSON Schema в виде JSON-текста
+	Input         string `protobuf:"bytes,24,opt,name=input,proto3" json:"input,omitempty"`
+	Output        string `protobuf:"bytes,25,opt,name=output,proto3" json:"output,omitempty"`
+	InputSchema   string `protobuf:"bytes,26,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	OutputSchema  string `protobuf:"bytes,27,opt,name=output_schema,json=outputSchema,proto3" json:"output_schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetDeleteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeleteAt
+	}
+	return nil
+}
+
+func (x *Task) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Task) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *Task) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Task) GetRootTaskId() string {
+	if x != nil {
+		return x.RootTaskId
+	}
+	return ""
+}
+
+func (x *Task) GetParentTaskId() string {
+	if x != nil {
+		return x.ParentTaskId
+	}
+	return ""
+}
+
+func (x *Task) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *Task) GetCredentials() map[string]*ServiceCredentials {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Task) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *Task) GetScheduled() bool {
+	if x != nil {
+		return x.Scheduled
+	}
+	return false
+}
+
+func (x *Task) GetLeaseExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpiresAt
+	}
+	return nil
+}
+
+func (x *Task) GetRequeueCount() int32 {
+	if x != nil {
+		return x.RequeueCount
+	}
+	return 0
+}
+
+func (x *Task) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *Task) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Task) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *Task) GetInputQuestion() string {
+	if x != nil {
+		return x.InputQuestion
+	}
+	return ""
+}
+
+func (x *Task) GetInputAnswer() string {
+	if x != nil {
+		return x.InputAnswer
+	}
+	return ""
+}
+
+func (x *Task) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *Task) GetOnChildFailure() string {
+	if x != nil {
+		return x.OnChildFailure
+	}
+	return ""
+}
+
+func (x *Task) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *Task) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *Task) GetInputSchema() string {
+	if x != nil {
+		return x.InputSchema
+	}
+	return ""
+}
+
+func (x *Task) GetOutputSchema() string {
+	if x != nil {
+		return x.OutputSchema
+	}
+	return ""
+}
+
+// RetryPolicy политика повторных попыток выполнения задачи
+type RetryPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Общее количество попыток, включая первую
+	MaxAttempts int32 `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// Задержка перед первым повтором, удваивается с каждой попыткой
+	BackoffBaseSeconds int32 `protobuf:"varint,2,opt,name=backoff_base_seconds,json=backoffBaseSeconds,proto3" json:"backoff_base_seconds,omitempty"`
+	// Максимальная задержка между попытками
+	MaxDelaySeconds int32 `protobuf:"varint,3,opt,name=max_delay_seconds,json=maxDelaySeconds,proto3" json:"max_delay_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBackoffBaseSeconds() int32 {
+	if x != nil {
+		return x.BackoffBaseSeconds
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxDelaySeconds() int32 {
+	if x != nil {
+		return x.MaxDelaySeconds
+	}
+	return 0
+}
+
+type CreateRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Description string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Assignee    string                 `protobuf:"bytes,2,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	// Родительская задача переходит в waiting, пока её подзадачи не завершатся
+	ParentTaskId string `protobuf:"bytes,3,opt,name=parent_task_id,json=parentTaskId,proto3" json:"parent_task_id,omitempty"`
+	// По умолчанию через 3 месяца
+	DeleteAt    *timestamppb.Timestamp         `protobuf:"bytes,4,opt,name=delete_at,json=deleteAt,proto3" json:"delete_at,omitempty"`
+	Credentials map[string]*ServiceCredentials `protobuf:"bytes,5,rep,name=credentials,proto3" json:"credentials,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RetryPolicy *RetryPolicy                   `protobuf:"bytes,6,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	// От -100 до 100; если не указан, подзадача наследует приоритет родителя
+	Priority *int32                 `protobuf:"varint,7,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	RunAt    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	// Задача ждет в статусе waiting, пока все эти задачи не будут завершены
+	DependsOn []string `protobuf:"bytes,9,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// Реакция на окончательную неудачу подзадачи: wait (по умолчанию), resubmit_parent, fail_parent или cancel_siblings
+	OnChildFailure string `protobuf:"bytes,10,opt,name=on_child_failure,json=onChildFailure,proto3" json:"on_child_failure,omitempty"`
+	// Входные данные (JSON-текст), проверяются по input_schema
+	Input string `protobuf:"bytes,11,opt,name=input,proto3" json:"input,omitempty"`
+	// JSON Schema входных данных и результата (JSON-текст); внешние $ref не загружаются
+	InputSchema  string `protobuf:"bytes,12,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	OutputSchema string `protobuf:"bytes,13,opt,name=output_schema,json=outputSchema,proto3" json:"output_schema,omitempty"`
+	// Сервисы из credentials родителя, которые получает подзадача; передает исполнитель или создатель родителя
+	InheritCredentials []string `protobuf:"bytes,14,rep,name=inherit_credentials,json=inheritCredentials,proto3" json:"inherit_credentials,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CreateRequest) Reset() {
+	*x = CreateRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRequest) ProtoMessage() {}
+
+func (x *CreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetParentTaskId() string {
+	if x != nil {
+		return x.ParentTaskId
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetDeleteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeleteAt
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetCredentials() map[string]*ServiceCredentials {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetOnChildFailure() string {
+	if x != nil {
+		return x.OnChildFailure
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetInputSchema() string {
+	if x != nil {
+		return x.InputSchema
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetOutputSchema() string {
+	if x != nil {
+		return x.OutputSchema
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetInheritCredentials() []string {
+	if x != nil {
+		return x.InheritCredentials
+	}
+	return nil
+}
+
+type ClaimRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Сколько секунд ждать появления задачи, не больше LONG_POLL_MAX_WAIT. 0 - не ждать
+	WaitSeconds int32 `protobuf:"varint,1,opt,name=wait_seconds,json=waitSeconds,proto3" json:"wait_seconds,omitempty"`
+	// Сколько уровней подзадач любых статусов вернуть в children: 0 - не возвращать, 1 - прямые подзадачи, до 10
+	ChildrenDepth int32 `protobuf:"varint,2,opt,name=children_depth,json=childrenDepth,proto3" json:"children_depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimRequest) Reset() {
+	*x = ClaimRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimRequest) ProtoMessage() {}
+
+func (x *ClaimRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimRequest.ProtoReflect.Descriptor instead.
+func (*ClaimRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ClaimRequest) GetWaitSeconds() int32 {
+	if x != nil {
+		return x.WaitSeconds
+	}
+	return 0
+}
+
+func (x *ClaimRequest) GetChildrenDepth() int32 {
+	if x != nil {
+		return x.ChildrenDepth
+	}
+	return 0
+}
+
+type ClaimResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Task              *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	CompletedSubtasks []*Task                `protobuf:"bytes,2,rep,name=completed_subtasks,json=completedSubtasks,proto3" json:"completed_subtasks,omitempty"`
+	RejectedSubtasks  []*Task                `protobuf:"bytes,3,rep,name=rejected_subtasks,json=rejectedSubtasks,proto3" json:"rejected_subtasks,omitempty"`
+	// Подзадачи в статусах failed и dead-lettered, причина неудачи в result
+	FailedSubtasks []*Task `protobuf:"bytes,4,rep,name=failed_subtasks,json=failedSubtasks,proto3" json:"failed_subtasks,omitempty"`
+	// Подзадачи всех статусов без credentials, если задан children_depth
+	Children      []*TaskNode `protobuf:"bytes,5,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimResponse) Reset() {
+	*x = ClaimResponse{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimResponse) ProtoMessage() {}
+
+func (x *ClaimResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimResponse.ProtoReflect.Descriptor instead.
+func (*ClaimResponse) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClaimResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *ClaimResponse) GetCompletedSubtasks() []*Task {
+	if x != nil {
+		return x.CompletedSubtasks
+	}
+	return nil
+}
+
+func (x *ClaimResponse) GetRejectedSubtasks() []*Task {
+	if x != nil {
+		return x.RejectedSubtasks
+	}
+	return nil
+}
+
+func (x *ClaimResponse) GetFailedSubtasks() []*Task {
+	if x != nil {
+		return x.FailedSubtasks
+	}
+	return nil
+}
+
+func (x *ClaimResponse) GetChildren() []*TaskNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+// TaskNode подзадача с вложенными подзадачами
+type TaskNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Children      []*TaskNode            `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskNode) Reset() {
+	*x = TaskNode{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskNode) ProtoMessage() {}
+
+func (x *TaskNode) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskNode.ProtoReflect.Descriptor instead.
+func (*TaskNode) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskNode) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *TaskNode) GetChildren() []*TaskNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HeartbeatRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type CompleteRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TaskId string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// Результат задачи
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	DeleteAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=delete_at,json=deleteAt,proto3" json:"delete_at,omitempty"`
+	// Структурированный результат (JSON-текст); обязателен и проверяется, если у задачи есть output_schema
+	Output        string `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteRequest) Reset() {
+	*x = CompleteRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteRequest) ProtoMessage() {}
+
+func (x *CompleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteRequest.ProtoReflect.Descriptor instead.
+func (*CompleteRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CompleteRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *CompleteRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CompleteRequest) GetDeleteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeleteAt
+	}
+	return nil
+}
+
+func (x *CompleteRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type FailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailRequest) Reset() {
+	*x = FailRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailRequest) ProtoMessage() {}
+
+func (x *FailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailRequest.ProtoReflect.Descriptor instead.
+func (*FailRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FailRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *FailRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CancelRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type ListRootTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRootTasksRequest) Reset() {
+	*x = ListRootTasksRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRootTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRootTasksRequest) ProtoMessage() {}
+
+func (x *ListRootTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRootTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListRootTasksRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{11}
+}
+
+type ListRootTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRootTasksResponse) Reset() {
+	*x = ListRootTasksResponse{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRootTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRootTasksResponse) ProtoMessage() {}
+
+func (x *ListRootTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRootTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListRootTasksResponse) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListRootTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type GetTreeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RootTaskId    string                 `protobuf:"bytes,1,opt,name=root_task_id,json=rootTaskId,proto3" json:"root_task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTreeRequest) Reset() {
+	*x = GetTreeRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeRequest) ProtoMessage() {}
+
+func (x *GetTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeRequest.ProtoReflect.Descriptor instead.
+func (*GetTreeRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTreeRequest) GetRootTaskId() string {
+	if x != nil {
+		return x.RootTaskId
+	}
+	return ""
+}
+
+type GetTreeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTreeResponse) Reset() {
+	*x = GetTreeResponse{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTreeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeResponse) ProtoMessage() {}
+
+func (x *GetTreeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeResponse.ProtoReflect.Descriptor instead.
+func (*GetTreeResponse) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTreeResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type WatchTreeRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	RootTaskId string                 `protobuf:"bytes,1,opt,name=root_task_id,json=rootTaskId,proto3" json:"root_task_id,omitempty"`
+	// ID последнего полученного события для возобновления потока, 0 - только новые события
+	LastEventId   int64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTreeRequest) Reset() {
+	*x = WatchTreeRequest{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTreeRequest) ProtoMessage() {}
+
+func (x *WatchTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTreeRequest.ProtoReflect.Descriptor instead.
+func (*WatchTreeRequest) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchTreeRequest) GetRootTaskId() string {
+	if x != nil {
+		return x.RootTaskId
+	}
+	return ""
+}
+
+func (x *WatchTreeRequest) GetLastEventId() int64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+// TaskEvent событие изменения задачи в дереве
+type TaskEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// created, claimed, completed, failed, canceled, waiting, rejected, input-required, input-provided,
+	// resubmitted, requeued, redriven
+	Type       string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	RootTaskId string `protobuf:"bytes,3,opt,name=root_task_id,json=rootTaskId,proto3" json:"root_task_id,omitempty"`
+	TaskId     string `protobuf:"bytes,4,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Status     string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	// Статус до перехода, пусто для создания
+	FromStatus string `protobuf:"bytes,6,opt,name=from_status,json=fromStatus,proto3" json:"from_status,omitempty"`
+	// user_id инициатора или system
+	Actor         string                 `protobuf:"bytes,7,opt,name=actor,proto3" json:"actor,omitempty"`
+	Task          *Task                  `protobuf:"bytes,8,opt,name=task,proto3" json:"task,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TaskEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TaskEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetRootTaskId() string {
+	if x != nil {
+		return x.RootTaskId
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetFromStatus() string {
+	if x != nil {
+		return x.FromStatus
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// Resync сообщает, что часть событий после last_event_id недоступна и дерево нужно загрузить заново через GetTree
+type Resync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resync) Reset() {
+	*x = Resync{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resync) ProtoMessage() {}
+
+func (x *Resync) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resync.ProtoReflect.Descriptor instead.
+func (*Resync) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Resync) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type WatchTreeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*WatchTreeResponse_Event
+	//	*WatchTreeResponse_Resync
+	Payload       isWatchTreeResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTreeResponse) Reset() {
+	*x = WatchTreeResponse{}
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTreeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTreeResponse) ProtoMessage() {}
+
+func (x *WatchTreeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskmanager_v1_task_manager_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTreeResponse.ProtoReflect.Descriptor instead.
+func (*WatchTreeResponse) Descriptor() ([]byte, []int) {
+	return file_taskmanager_v1_task_manager_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchTreeResponse) GetPayload() isWatchTreeResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *WatchTreeResponse) GetEvent() *TaskEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*WatchTreeResponse_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *WatchTreeResponse) GetResync() *Resync {
+	if x != nil {
+		if x, ok := x.Payload.(*WatchTreeResponse_Resync); ok {
+			return x.Resync
+		}
+	}
+	return nil
+}
+
+type isWatchTreeResponse_Payload interface {
+	isWatchTreeResponse_Payload()
+}
+
+type WatchTreeResponse_Event struct {
+	Event *TaskEvent `protobuf:"bytes,1,opt,name=event,proto3,oneof"`
+}
+
+type WatchTreeResponse_Resync struct {
+	Resync *Resync `protobuf:"bytes,2,opt,name=resync,proto3,oneof"`
+}
+
+func (*WatchTreeResponse_Event) isWatchTreeResponse_Payload() {}
+
+func (*WatchTreeResponse_Resync) isWatchTreeResponse_Payload() {}
+
+var File_taskmanager_v1_task_manager_proto protoreflect.FileDescriptor
+
+const file_taskmanager_v1_task_manager_proto_rawDesc = "" +
+	"\n" +
+	"!taskmanager/v1/task_manager.proto\x12\x0etaskmanager.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8b\x01\n" +
+	"\x12ServiceCredentials\x12=\n" +
+	"\x03env\x18\x01 \x03(\v2+.taskmanager.v1.ServiceCredentials.EnvEntryR\x03env\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe7\b\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tdelete_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bdeleteAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x04 \x01(\tR\tcreatedBy\x12\x1a\n" +
+	"\bassignee\x18\x05 \x01(\tR\bassignee\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12 \n" +
+	"\froot_task_id\x18\a \x01(\tR\n" +
+	"rootTaskId\x12$\n" +
+	"\x0eparent_task_id\x18\b \x01(\tR\fparentTaskId\x12\x16\n" +
+	"\x06result\x18\t \x01(\tR\x06result\x12G\n" +
+	"\vcredentials\x18\n" +
+	" \x03(\v2%.taskmanager.v1.Task.CredentialsEntryR\vcredentials\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\f \x01(\x05R\bpriority\x121\n" +
+	"\x06run_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x12\x1c\n" +
+	"\tscheduled\x18\x0e \x01(\bR\tscheduled\x12D\n" +
+	"\x10lease_expires_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\x0eleaseExpiresAt\x12#\n" +
+	"\rrequeue_count\x18\x10 \x01(\x05R\frequeueCount\x12!\n" +
+	"\fmax_attempts\x18\x11 \x01(\x05R\vmaxAttempts\x12\x1a\n" +
+	"\battempts\x18\x12 \x01(\x05R\battempts\x129\n" +
+	"\n" +
+	"not_before\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x12%\n" +
+	"\x0einput_question\x18\x14 \x01(\tR\rinputQuestion\x12!\n" +
+	"\finput_answer\x18\x15 \x01(\tR\vinputAnswer\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\x16 \x03(\tR\tdependsOn\x12(\n" +
+	"\x10on_child_failure\x18\x17 \x01(\tR\x0eonChildFailure\x12\x14\n" +
+	"\x05input\x18\x18 \x01(\tR\x05input\x12\x16\n" +
+	"\x06output\x18\x19 \x01(\tR\x06output\x12!\n" +
+	"\finput_schema\x18\x1a \x01(\tR\vinputSchema\x12#\n" +
+	"\routput_schema\x18\x1b \x01(\tR\foutputSchema\x1ab\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x128\n" +
+	"\x05value\x18\x02 \x01(\v2\".taskmanager.v1.ServiceCredentialsR\x05value:\x028\x01\"\x8e\x01\n" +
+	"\vRetryPolicy\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x120\n" +
+	"\x14backoff_base_seconds\x18\x02 \x01(\x05R\x12backoffBaseSeconds\x12*\n" +
+	"\x11max_delay_seconds\x18\x03 \x01(\x05R\x0fmaxDelaySeconds\"\xdb\x05\n" +
+	"\rCreateRequest\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bassignee\x18\x02 \x01(\tR\bassignee\x12$\n" +
+	"\x0eparent_task_id\x18\x03 \x01(\tR\fparentTaskId\x127\n" +
+	"\tdelete_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bdeleteAt\x12P\n" +
+	"\vcredentials\x18\x05 \x03(\v2..taskmanager.v1.CreateRequest.CredentialsEntryR\vcredentials\x12>\n" +
+	"\fretry_policy\x18\x06 \x01(\v2\x1b.taskmanager.v1.RetryPolicyR\vretryPolicy\x12\x1f\n" +
+	"\bpriority\x18\a \x01(\x05H\x00R\bpriority\x88\x01\x01\x121\n" +
+	"\x06run_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\t \x03(\tR\tdependsOn\x12(\n" +
+	"\x10on_child_failure\x18\n" +
+	" \x01(\tR\x0eonChildFailure\x12\x14\n" +
+	"\x05input\x18\v \x01(\tR\x05input\x12!\n" +
+	"\finput_schema\x18\f \x01(\tR\vinputSchema\x12#\n" +
+	"\routput_schema\x18\r \x01(\tR\foutputSchema\x12/\n" +
+	"\x13inherit_credentials\x18\x0e \x03(\tR\x12inheritCredentials\x1ab\n" +
+	"\x10CredentialsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x128\n" +
+	"\x05value\x18\x02 \x01(\v2\".taskmanager.v1.ServiceCredentialsR\x05value:\x028\x01B\v\n" +
+	"\t_priority\"X\n" +
+	"\fClaimRequest\x12!\n" +
+	"\fwait_seconds\x18\x01 \x01(\x05R\vwaitSeconds\x12%\n" +
+	"\x0echildren_depth\x18\x02 \x01(\x05R\rchildrenDepth\"\xb6\x02\n" +
+	"\rClaimResponse\x12(\n" +
+	"\x04task\x18\x01 \x01(\v2\x14.taskmanager.v1.TaskR\x04task\x12C\n" +
+	"\x12completed_subtasks\x18\x02 \x03(\v2\x14.taskmanager.v1.TaskR\x11completedSubtasks\x12A\n" +
+	"\x11rejected_subtasks\x18\x03 \x03(\v2\x14.taskmanager.v1.TaskR\x10rejectedSubtasks\x12=\n" +
+	"\x0ffailed_subtasks\x18\x04 \x03(\v2\x14.taskmanager.v1.TaskR\x0efailedSubtasks\x124\n" +
+	"\bchildren\x18\x05 \x03(\v2\x18.taskmanager.v1.TaskNodeR\bchildren\"j\n" +
+	"\bTaskNode\x12(\n" +
+	"\x04task\x18\x01 \x01(\v2\x14.taskmanager.v1.TaskR\x04task\x124\n" +
+	"\bchildren\x18\x02 \x03(\v2\x18.taskmanager.v1.TaskNodeR\bchildren\"+\n" +
+	"\x10HeartbeatRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x9d\x01\n" +
+	"\x0fCompleteRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x127\n" +
+	"\tdelete_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bdeleteAt\x12\x16\n" +
+	"\x06output\x18\x04 \x01(\tR\x06output\">\n" +
+	"\vFailRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"(\n" +
+	"\rCancelRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x16\n" +
+	"\x14ListRootTasksRequest\"C\n" +
+	"\x15ListRootTasksResponse\x12*\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x14.taskmanager.v1.TaskR\x05tasks\"2\n" +
+	"\x0eGetTreeRequest\x12 \n" +
+	"\froot_task_id\x18\x01 \x01(\tR\n" +
+	"rootTaskId\"=\n" +
+	"\x0fGetTreeResponse\x12*\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x14.taskmanager.v1.TaskR\x05tasks\"X\n" +
+	"\x10WatchTreeRequest\x12 \n" +
+	"\froot_task_id\x18\x01 \x01(\tR\n" +
+	"rootTaskId\x12\"\n" +
+	"\rlast_event_id\x18\x02 \x01(\x03R\vlastEventId\"\xa0\x02\n" +
+	"\tTaskEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
+	"\froot_task_id\x18\x03 \x01(\tR\n" +
+	"rootTaskId\x12\x17\n" +
+	"\atask_id\x18\x04 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1f\n" +
+	"\vfrom_status\x18\x06 \x01(\tR\n" +
+	"fromStatus\x12\x14\n" +
+	"\x05actor\x18\a \x01(\tR\x05actor\x12(\n" +
+	"\x04task\x18\b \x01(\v2\x14.taskmanager.v1.TaskR\x04task\x12;\n" +
+	"\voccurred_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\" \n" +
+	"\x06Resync\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\x83\x01\n" +
+	"\x11WatchTreeResponse\x121\n" +
+	"\x05event\x18\x01 \x01(\v2\x19.taskmanager.v1.TaskEventH\x00R\x05event\x120\n" +
+	"\x06resync\x18\x02 \x01(\v2\x16.taskmanager.v1.ResyncH\x00R\x06resyncB\t\n" +
+	"\apayload2\x92\x05\n" +
+	"\vTaskManager\x12=\n" +
+	"\x06Create\x12\x1d.taskmanager.v1.CreateRequest\x1a\x14.taskmanager.v1.Task\x12D\n" +
+	"\x05Claim\x12\x1c.taskmanager.v1.ClaimRequest\x1a\x1d.taskmanager.v1.ClaimResponse\x12C\n" +
+	"\tHeartbeat\x12 .taskmanager.v1.HeartbeatRequest\x1a\x14.taskmanager.v1.Task\x12A\n" +
+	"\bComplete\x12\x1f.taskmanager.v1.CompleteRequest\x1a\x14.taskmanager.v1.Task\x129\n" +
+	"\x04Fail\x12\x1b.taskmanager.v1.FailRequest\x1a\x14.taskmanager.v1.Task\x12=\n" +
+	"\x06Cancel\x12\x1d.taskmanager.v1.CancelRequest\x1a\x14.taskmanager.v1.Task\x12\\\n" +
+	"\rListRootTasks\x12$.taskmanager.v1.ListRootTasksRequest\x1a%.taskmanager.v1.ListRootTasksResponse\x12J\n" +
+	"\aGetTree\x12\x1e.taskmanager.v1.GetTreeRequest\x1a\x1f.taskmanager.v1.GetTreeResponse\x12R\n" +
+	"\tWatchTree\x12 .taskmanager.v1.WatchTreeRequest\x1a!.taskmanager.v1.WatchTreeResponse0\x01B8Z6agent-task-manager/grpcapi/taskmanagerv1;taskmanagerv1b\x06proto3"
+
+var (
+	file_taskmanager_v1_task_manager_proto_rawDescOnce sync.Once
+	file_taskmanager_v1_task_manager_proto_rawDescData []byte
+)
+
+func file_taskmanager_v1_task_manager_proto_rawDescGZIP() []byte {
+	file_taskmanager_v1_task_manager_proto_rawDescOnce.Do(func() {
+		file_taskmanager_v1_task_manager_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_taskmanager_v1_task_manager_proto_rawDesc), len(file_taskmanager_v1_task_manager_proto_rawDesc)))
+	})
+	return file_taskmanager_v1_task_manager_proto_rawDescData
+}
+
+var file_taskmanager_v1_task_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_taskmanager_v1_task_manager_proto_goTypes = []any{
+	(*ServiceCredentials)(nil),    // 0: taskmanager.v1.ServiceCredentials
+	(*Task)(nil),                  // 1: taskmanager.v1.Task
+	(*RetryPolicy)(nil),           // 2: taskmanager.v1.RetryPolicy
+	(*CreateRequest)(nil),         // 3: taskmanager.v1.CreateRequest
+	(*ClaimRequest)(nil),          // 4: taskmanager.v1.ClaimRequest
+	(*ClaimResponse)(nil),         // 5: taskmanager.v1.ClaimResponse
+	(*TaskNode)(nil),              // 6: taskmanager.v1.TaskNode
+	(*HeartbeatRequest)(nil),      // 7: taskmanager.v1.HeartbeatRequest
+	(*CompleteRequest)(nil),       // 8: taskmanager.v1.CompleteRequest
+	(*FailRequest)(nil),           // 9: taskmanager.v1.FailRequest
+	(*CancelRequest)(nil),         // 10: taskmanager.v1.CancelRequest
+	(*ListRootTasksRequest)(nil),  // 11: taskmanager.v1.ListRootTasksRequest
+	(*ListRootTasksResponse)(nil), // 12: taskmanager.v1.ListRootTasksResponse
+	(*GetTreeRequest)(nil),        // 13: taskmanager.v1.GetTreeRequest
+	(*GetTreeResponse)(nil),       // 14: taskmanager.v1.GetTreeResponse
+	(*WatchTreeRequest)(nil),      // 15: taskmanager.v1.WatchTreeRequest
+	(*TaskEvent)(nil),             // 16: taskmanager.v1.TaskEvent
+	(*Resync)(nil),                // 17: taskmanager.v1.Resync
+	(*WatchTreeResponse)(nil),     // 18: taskmanager.v1.WatchTreeResponse
+	nil,                           // 19: taskmanager.v1.ServiceCredentials.EnvEntry
+	nil,                           // 20: taskmanager.v1.Task.CredentialsEntry
+	nil,                           // 21: taskmanager.v1.CreateRequest.CredentialsEntry
+	(*timestamppb.Timestamp)(nil), // 22: google.protobuf.Timestamp
+}
+var file_taskmanager_v1_task_manager_proto_depIdxs = []int32{
+	19, // 0: taskmanager.v1.ServiceCredentials.env:type_name -> taskmanager.v1.ServiceCredentials.EnvEntry
+	22, // 1: taskmanager.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	22, // 2: taskmanager.v1.Task.delete_at:type_name -> google.protobuf.Timestamp
+	20, // 3: taskmanager.v1.Task.credentials:type_name -> taskmanager.v1.Task.CredentialsEntry
+	22, // 4: taskmanager.v1.Task.run_at:type_name -> google.protobuf.Timestamp
+	22, // 5: taskmanager.v1.Task.lease_expires_at:type_name -> google.protobuf.Timestamp
+	22, // 6: taskmanager.v1.Task.not_before:type_name -> google.protobuf.Timestamp
+	22, // 7: taskmanager.v1.CreateRequest.delete_at:type_name -> google.protobuf.Timestamp
+	21, // 8: taskmanager.v1.CreateRequest.credentials:type_name -> taskmanager.v1.CreateRequest.CredentialsEntry
+	2,  // 9: taskmanager.v1.CreateRequest.retry_policy:type_name -> taskmanager.v1.RetryPolicy
+	22, // 10: taskmanager.v1.CreateRequest.run_at:type_name -> google.protobuf.Timestamp
+	1,  // 11: taskmanager.v1.ClaimResponse.task:type_name -> taskmanager.v1.Task
+	1,  // 12: taskmanager.v1.ClaimResponse.completed_subtasks:type_name -> taskmanager.v1.Task
+	1,  // 13: taskmanager.v1.ClaimResponse.rejected_subtasks:type_name -> taskmanager.v1.Task
+	1,  // 14: taskmanager.v1.ClaimResponse.failed_subtasks:type_name -> taskmanager.v1.Task
+	6,  // 15: taskmanager.v1.ClaimResponse.children:type_name -> taskmanager.v1.TaskNode
+	1,  // 16: taskmanager.v1.TaskNode.task:type_name -> taskmanager.v1.Task
+	6,  // 17: taskmanager.v1.TaskNode.children:type_name -> taskmanager.v1.TaskNode
+	22, // 18: taskmanager.v1.CompleteRequest.delete_at:type_name -> google.protobuf.Timestamp
+	1,  // 19: taskmanager.v1.ListRootTasksResponse.tasks:type_name -> taskmanager.v1.Task
+	1,  // 20: taskmanager.v1.GetTreeResponse.tasks:type_name -> taskmanager.v1.Task
+	1,  // 21: taskmanager.v1.TaskEvent.task:type_name -> taskmanager.v1.Task
+	22, // 22: taskmanager.v1.TaskEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	16, // 23: taskmanager.v1.WatchTreeResponse.event:type_name -> taskmanager.v1.TaskEvent
+	17, // 24: taskmanager.v1.WatchTreeResponse.resync:type_name -> taskmanager.v1.Resync
+	0,  // 25: taskmanager.v1.Task.CredentialsEntry.value:type_name -> taskmanager.v1.ServiceCredentials
+	0,  // 26: taskmanager.v1.CreateRequest.CredentialsEntry.value:type_name -> taskmanager.v1.ServiceCredentials
+	3,  // 27: taskmanager.v1.TaskManager.Create:input_type -> taskmanager.v1.CreateRequest
+	4,  // 28: taskmanager.v1.TaskManager.Claim:input_type -> taskmanager.v1.ClaimRequest
+	7,  // 29: taskmanager.v1.TaskManager.Heartbeat:input_type -> taskmanager.v1.HeartbeatRequest
+	8,  // 30: taskmanager.v1.TaskManager.Complete:input_type -> taskmanager.v1.CompleteRequest
+	9,  // 31: taskmanager.v1.TaskManager.Fail:input_type -> taskmanager.v1.FailRequest
+	10, // 32: taskmanager.v1.TaskManager.Cancel:input_type -> taskmanager.v1.CancelRequest
+	11, // 33: taskmanager.v1.TaskManager.ListRootTasks:input_type -> taskmanager.v1.ListRootTasksRequest
+	13, // 34: taskmanager.v1.TaskManager.GetTree:input_type -> taskmanager.v1.GetTreeRequest
+	15, // 35: taskmanager.v1.TaskManager.WatchTree:input_type -> taskmanager.v1.WatchTreeRequest
+	1,  // 36: taskmanager.v1.TaskManager.Create:output_type -> taskmanager.v1.Task
+	5,  // 37: taskmanager.v1.TaskManager.Claim:output_type -> taskmanager.v1.ClaimResponse
+	1,  // 38: taskmanager.v1.TaskManager.Heartbeat:output_type -> taskmanager.v1.Task
+	1,  // 39: taskmanager.v1.TaskManager.Complete:output_type -> taskmanager.v1.Task
+	1,  // 40: taskmanager.v1.TaskManager.Fail:output_type -> taskmanager.v1.Task
+	1,  // 41: taskmanager.v1.TaskManager.Cancel:output_type -> taskmanager.v1.Task
+	12, // 42: taskmanager.v1.TaskManager.ListRootTasks:output_type -> taskmanager.v1.ListRootTasksResponse
+	14, // 43: taskmanager.v1.TaskManager.GetTree:output_type -> taskmanager.v1.GetTreeResponse
+	18, // 44: taskmanager.v1.TaskManager.WatchTree:output_type -> taskmanager.v1.WatchTreeResponse
+	36, // [36:45] is the sub-list for method output_type
+	27, // [27:36] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
+}
+
+func init() { file_taskmanager_v1_task_manager_proto_init() }
+func file_taskmanager_v1_task_manager_proto_init() {
+	if File_taskmanager_v1_task_manager_proto != nil {
+		return
+	}
+	file_taskmanager_v1_task_manager_proto_msgTypes[3].OneofWrappers = []any{}
+	file_taskmanager_v1_task_manager_proto_msgTypes[18].OneofWrappers = []any{
+		(*WatchTreeResponse_Event)(nil),
+		(*WatchTreeResponse_Resync)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_taskmanager_v1_task_manager_proto_rawDesc), len(file_taskmanager_v1_task_manager_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_taskmanager_v1_task_manager_proto_goTypes,
+		DependencyIndexes: file_taskmanager_v1_task_manager_proto_depIdxs,
+		MessageInfos:      file_taskmanager_v1_task_manager_proto_msgTypes,
+	}.Build()
+	File_taskmanager_v1_task_manager_proto = out.File
+	file_taskmanager_v1_task_manager_proto_goTypes = nil
+	file_taskmanager_v1_task_manager_proto_depIdxs = nil
+}
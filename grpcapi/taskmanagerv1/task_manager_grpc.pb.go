@@ -0,0 +1,452 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: taskmanager/v1/task_manager.proto
+
+// Сервис TaskManager повторяет операции REST API над задачами.
+// Аутентификация: метаданные authorization со значением "Bearer <JWT>", как в заголовке Authorization
+
+package taskmanagerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TaskManager_Create_FullMethodName        = "/taskmanager.v1.TaskManager/Create"
+	TaskManager_Claim_FullMethodName         = "/taskmanager.v1.TaskManager/Claim"
+	TaskManager_Heartbeat_FullMethodName     = "/taskmanager.v1.TaskManager/Heartbeat"
+	TaskManager_Complete_FullMethodName      = "/taskmanager.v1.TaskManager/Complete"
+	TaskManager_Fail_FullMethodName          = "/taskmanager.v1.TaskManager/Fail"
+	TaskManager_Cancel_FullMethodName        = "/taskmanager.v1.TaskManager/Cancel"
+	TaskManager_ListRootTasks_FullMethodName = "/taskmanager.v1.TaskManager/ListRootTasks"
+	TaskManager_GetTree_FullMethodName       = "/taskmanager.v1.TaskManager/GetTree"
+	TaskManager_WatchTree_FullMethodName     = "/taskmanager.v1.TaskManager/WatchTree"
+)
+
+// TaskManagerClient is the client API for TaskManager service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TaskManagerClient interface {
+	// Create создает задачу или подзадачу (POST /task)
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Task, error)
+	// Claim берет в работу следующую задачу пользователя (GET /task?wait=...)
+	Claim(ctx context.Context, in *ClaimRequest, opts ...grpc.CallOption) (*ClaimResponse, error)
+	// Heartbeat продлевает аренду задачи в работе на LEASE_TTL (POST /task/:id/heartbeat)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Task, error)
+	// Complete завершает задачу в работе (POST /task/:id/complete)
+	Complete(ctx context.Context, in *CompleteRequest, opts ...grpc.CallOption) (*Task, error)
+	// Fail помечает задачу в работе как неудачную (POST /tasks/:id/fail)
+	Fail(ctx context.Context, in *FailRequest, opts ...grpc.CallOption) (*Task, error)
+	// Cancel отменяет задачу и её активные подзадачи (POST /task/:id/cancel)
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Task, error)
+	// ListRootTasks возвращает корневые задачи пользователя (GET /root-task)
+	ListRootTasks(ctx context.Context, in *ListRootTasksRequest, opts ...grpc.CallOption) (*ListRootTasksResponse, error)
+	// GetTree возвращает все задачи дерева корневой задачи без credentials (GET /root-task/:id/tasks)
+	GetTree(ctx context.Context, in *GetTreeRequest, opts ...grpc.CallOption) (*GetTreeResponse, error)
+	// WatchTree транслирует события задач дерева корневой задачи (GET /root-task/:id/events).
+	// Заголовки ответа отправляются сразу после подписки
+	WatchTree(ctx context.Context, in *WatchTreeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchTreeResponse], error)
+}
+
+type taskManagerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTaskManagerClient(cc grpc.ClientConnInterface) TaskManagerClient {
+	return &taskManagerClient{cc}
+}
+
+func (c *taskManagerClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TaskManager_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) Claim(ctx context.Context, in *ClaimRequest, opts ...grpc.CallOption) (*ClaimResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClaimResponse)
+	err := c.cc.Invoke(ctx, TaskManager_Claim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TaskManager_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) Complete(ctx context.Context, in *CompleteRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TaskManager_Complete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) Fail(ctx context.Context, in *FailRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TaskManager_Fail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TaskManager_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) ListRootTasks(ctx context.Context, in *ListRootTasksRequest, opts ...grpc.CallOption) (*ListRootTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRootTasksResponse)
+	err := c.cc.Invoke(ctx, TaskManager_ListRootTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) GetTree(ctx context.Context, in *GetTreeRequest, opts ...grpc.CallOption) (*GetTreeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTreeResponse)
+	err := c.cc.Invoke(ctx, TaskManager_GetTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskManagerClient) WatchTree(ctx context.Context, in *WatchTreeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchTreeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskManager_ServiceDesc.Streams[0], TaskManager_WatchTree_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTreeRequest, WatchTreeResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskManager_WatchTreeClient = grpc.ServerStreamingClient[WatchTreeResponse]
+
+// TaskManagerServer is the server API for TaskManager service.
+// All implementations must embed UnimplementedTaskManagerServer
+// for forward compatibility.
+type TaskManagerServer interface {
+	// Create создает задачу или подзадачу (POST /task)
+	Create(context.Context, *CreateRequest) (*Task, error)
+	// Claim берет в работу следующую задачу пользователя (GET /task?wait=...)
+	Claim(context.Context, *ClaimRequest) (*ClaimResponse, error)
+	// Heartbeat продлевает аренду задачи в работе на LEASE_TTL (POST /task/:id/heartbeat)
+	Heartbeat(context.Context, *HeartbeatRequest) (*Task, error)
+	// Complete завершает задачу в работе (POST /task/:id/complete)
+	Complete(context.Context, *CompleteRequest) (*Task, error)
+	// Fail помечает задачу в работе как неудачную (POST /tasks/:id/fail)
+	Fail(context.Context, *FailRequest) (*Task, error)
+	// Cancel отменяет задачу и её активные подзадачи (POST /task/:id/cancel)
+	Cancel(context.Context, *CancelRequest) (*Task, error)
+	// ListRootTasks возвращает корневые задачи пользователя (GET /root-task)
+	ListRootTasks(context.Context, *ListRootTasksRequest) (*ListRootTasksResponse, error)
+	// GetTree возвращает все задачи дерева корневой задачи без credentials (GET /root-task/:id/tasks)
+	GetTree(context.Context, *GetTreeRequest) (*GetTreeResponse, error)
+	// WatchTree транслирует события задач дерева корневой задачи (GET /root-task/:id/events).
+	// Заголовки ответа отправляются сразу после подписки
+	WatchTree(*WatchTreeRequest, grpc.ServerStreamingServer[WatchTreeResponse]) error
+	mustEmbedUnimplementedTaskManagerServer()
+}
+
+// UnimplementedTaskManagerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTaskManagerServer struct{}
+
+func (UnimplementedTaskManagerServer) Create(context.Context, *CreateRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedTaskManagerServer) Claim(context.Context, *ClaimRequest) (*ClaimResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Claim not implemented")
+}
+func (UnimplementedTaskManagerServer) Heartbeat(context.Context, *HeartbeatRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedTaskManagerServer) Complete(context.Context, *CompleteRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Complete not implemented")
+}
+func (UnimplementedTaskManagerServer) Fail(context.Context, *FailRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fail not implemented")
+}
+func (UnimplementedTaskManagerServer) Cancel(context.Context, *CancelRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedTaskManagerServer) ListRootTasks(context.Context, *ListRootTasksRequest) (*ListRootTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRootTasks not implemented")
+}
+func (UnimplementedTaskManagerServer) GetTree(context.Context, *GetTreeRequest) (*GetTreeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTree not implemented")
+}
+func (UnimplementedTaskManagerServer) WatchTree(*WatchTreeRequest, grpc.ServerStreamingServer[WatchTreeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTree not implemented")
+}
+func (UnimplementedTaskManagerServer) mustEmbedUnimplementedTaskManagerServer() {}
+func (UnimplementedTaskManagerServer) testEmbeddedByValue()                     {}
+
+// UnsafeTaskManagerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TaskManagerServer will
+// result in compilation errors.
+type UnsafeTaskManagerServer interface {
+	mustEmbedUnimplementedTaskManagerServer()
+}
+
+func RegisterTaskManagerServer(s grpc.ServiceRegistrar, srv TaskManagerServer) {
+	// If the following call pancis, it indicates UnimplementedTaskManagerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TaskManager_ServiceDesc, srv)
+}
+
+func _TaskManager_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_Claim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Claim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Claim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Claim(ctx, req.(*ClaimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_Complete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Complete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Complete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Complete(ctx, req.(*CompleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_Fail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Fail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Fail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Fail(ctx, req.(*FailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_ListRootTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRootTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).ListRootTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_ListRootTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).ListRootTasks(ctx, req.(*ListRootTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_GetTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskManagerServer).GetTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskManager_GetTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskManagerServer).GetTree(ctx, req.(*GetTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskManager_WatchTree_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTreeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskManagerServer).WatchTree(m, &grpc.GenericServerStream[WatchTreeRequest, WatchTreeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskManager_WatchTreeServer = grpc.ServerStreamingServer[WatchTreeResponse]
+
+// TaskManager_ServiceDesc is the grpc.ServiceDesc for TaskManager service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TaskManager_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "taskmanager.v1.TaskManager",
+	HandlerType: (*TaskManagerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _TaskManager_Create_Handler,
+		},
+		{
+			MethodName: "Claim",
+			Handler:    _TaskManager_Claim_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _TaskManager_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Complete",
+			Handler:    _TaskManager_Complete_Handler,
+		},
+		{
+			MethodName: "Fail",
+			Handler:    _TaskManager_Fail_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _TaskManager_Cancel_Handler,
+		},
+		{
+			MethodName: "ListRootTasks",
+			Handler:    _TaskManager_ListRootTasks_Handler,
+		},
+		{
+			MethodName: "GetTree",
+			Handler:    _TaskManager_GetTree_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTree",
+			Handler:       _TaskManager_WatchTree_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "taskmanager/v1/task_manager.proto",
+}
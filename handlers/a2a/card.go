@@ -0,0 +1,50 @@
+package a2a
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// agentCardVersion версия A2A интерфейса сервиса
+const agentCardVersion = "1.0.0"
+
+// AgentCardHandler обработчик для получения карточки агента /.well-known/agent.json.
+// Адрес JSON-RPC эндпоинта строится по заголовкам запроса, чтобы карточка была верна за прокси
+func AgentCardHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		if forwardedProto := c.GetHeader("X-Forwarded-Proto"); forwardedProto != "" {
+			scheme = forwardedProto
+		}
+
+		c.JSON(http.StatusOK, AgentCard{
+			Name:        "Agent Task Manager",
+			Description: "Task delegation service for AI agents. Each A2A task is stored as a task for the assignee given in params.metadata.",
+			URL:         scheme + "://" + c.Request.Host + "/a2a",
+			Version:     agentCardVersion,
+			Capabilities: AgentCapabilities{
+				Streaming:              true,
+				PushNotifications:      false,
+				StateTransitionHistory: false,
+			},
+			Authentication: AgentAuth{
+				Schemes: []string{"Bearer"},
+			},
+			DefaultInputModes:  []string{"text"},
+			DefaultOutputModes: []string{"text"},
+			Skills: []AgentSkill{
+				{
+					ID:          "task-delegation",
+					Name:        "Task delegation",
+					Description: "Delegates a task to another agent. Pass the assignee (and optionally parent_task_id) in params.metadata; the task result is returned as the \"result\" artifact.",
+					Tags:        []string{"tasks", "delegation"},
+					Examples:    []string{"Summarize the attached report"},
+				},
+			},
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package a2a
+
+import (
+	"agent-task-manager/models"
+	"time"
+)
+
+// taskState переводит статус задачи в состояние A2A.
+// waiting (ожидание подзадач) для клиента выглядит как продолжающаяся работа, dead-lettered - как неудача
+func taskState(status models.TaskStatus) string {
+	switch status {
+	case models.StatusWaiting:
+		return stateWorking
+	case models.StatusDeadLettered:
+		return stateFailed
+	case models.StatusSubmitted, models.StatusWorking, models.StatusInputRequired,
+		models.StatusCompleted, models.StatusCanceled, models.StatusFailed, models.StatusRejected:
+		return string(status)
+	default:
+		return stateUnknown
+	}
+}
+
+// isFinalState сообщает, завершает ли состояние поток tasks/sendSubscribe.
+// input-required тоже завершает поток: клиент продолжит диалог новым tasks/sendSubscribe
+func isFinalState(state string) bool {
+	switch state {
+	case string(models.StatusCompleted), string(models.StatusCanceled), string(models.StatusFailed),
+		string(models.StatusRejected), string(models.StatusInputRequired):
+		return true
+	default:
+		return false
+	}
+}
+
+// textMessage создает сообщение из одной текстовой части
+func textMessage(role, text string) *Message {
+	return &Message{
+		Role:  role,
+		Parts: []Part{{Type: "text", Text: text}},
+	}
+}
+
+// taskStatus формирует состояние A2A с сообщением агента: вопросом для input-required и причиной для неудач
+func taskStatus(task models.Task, timestamp time.Time) TaskStatus {
+	status := TaskStatus{
+		State:     taskState(task.Status),
+		Timestamp: timestamp,
+	}
+
+	switch task.Status {
+	case models.StatusInputRequired:
+		status.Message = textMessage("agent", task.InputQuestion)
+	case models.StatusFailed, models.StatusDeadLettered, models.StatusRejected:
+		if task.Result != "" {
+			status.Message = textMessage("agent", task.Result)
+		}
+	}
+
+	return status
+}
+
+// resultArtifact возвращает результат завершенной задачи как артефакт
+func resultArtifact(task models.Task) (Artifact, bool) {
+	if task.Status != models.StatusCompleted {
+		return Artifact{}, false
+	}
+	return Artifact{
+		Name:  "result",
+		Parts: []Part{{Type: "text", Text: task.Result}},
+		Index: 0,
+	}, true
+}
+
+// toA2ATask переводит задачу в формат A2A. historyLength ограничивает число последних сообщений истории
+func toA2ATask(task models.Task, timestamp time.Time, historyLength *int) Task {
+	result := Task{
+		ID:     task.ID.String(),
+		Status: taskStatus(task, timestamp),
+		Metadata: map[string]interface{}{
+			"assignee":   task.Assignee,
+			"created_by": task.CreatedBy,
+			"status":     task.Status,
+			"priority":   task.Priority,
+		},
+	}
+
+	if task.RootTaskID != nil {
+		result.SessionID = task.RootTaskID.String()
+	}
+	if task.ParentTaskID != nil {
+		result.Metadata["parent_task_id"] = task.ParentTaskID.String()
+	}
+
+	if artifact, ok := resultArtifact(task); ok {
+		result.Artifacts = []Artifact{artifact}
+	}
+
+	// История диалога: описание задачи, вопрос исполнителя и ответ создателя
+	history := []Message{*textMessage("user", task.Description)}
+	if task.InputQuestion != "" {
+		history = append(history, *textMessage("agent", task.InputQuestion))
+	}
+	if task.InputAnswer != "" {
+		history = append(history, *textMessage("user", task.InputAnswer))
+	}
+
+	if historyLength != nil {
+		if *historyLength <= 0 {
+			history = nil
+		} else if *historyLength < len(history) {
+			history = history[len(history)-*historyLength:]
+		}
+	}
+	result.History = history
+
+	return result
+}
@@ -0,0 +1,305 @@
+package a2a
+
+import (
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newRPCError создает ошибку JSON-RPC
+func newRPCError(code int, message string) *RPCError {
+	return &RPCError{Code: code, Message: message}
+}
+
+// writeResponse пишет ответ JSON-RPC. По спецификации ошибки передаются в теле со статусом 200
+func writeResponse(c *gin.Context, id json.RawMessage, result interface{}, rpcErr *RPCError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	response := RPCResponse{
+		JSONRPC: jsonRPCVersion,
+		ID:      id,
+	}
+	// Результат и ошибка взаимоисключающие; типизированный nil результата не должен попасть в ответ
+	if rpcErr != nil {
+		response.Error = rpcErr
+	} else {
+		response.Result = result
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// toRPCError переводит ошибку операции над задачей в ошибку JSON-RPC
+func toRPCError(err error) *RPCError {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		return newRPCError(codeInternalError, err.Error())
+	}
+
+	rpcErr := newRPCError(codeInternalError, serviceErr.Message)
+	switch serviceErr.Kind {
+	case service.KindNotFound:
+		rpcErr.Code = codeTaskNotFound
+	case service.KindInvalid, service.KindConflict:
+		rpcErr.Code = codeInvalidParams
+	case service.KindForbidden:
+		rpcErr.Code = codeInvalidRequest
+	}
+	if len(serviceErr.Fields) > 0 {
+		rpcErr.Data = serviceErr.Fields
+	}
+	return rpcErr
+}
+
+// parseTaskID разбирает ID задачи A2A. Задачи хранятся в tasks, поэтому ID должен быть UUID
+func parseTaskID(id string) (uuid.UUID, *RPCError) {
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, newRPCError(codeInvalidParams, "task id must be a UUID")
+	}
+	return taskID, nil
+}
+
+// decodeParams разбирает параметры метода
+func decodeParams(params json.RawMessage, target interface{}) *RPCError {
+	if len(params) == 0 {
+		return newRPCError(codeInvalidParams, "params are required")
+	}
+	if err := json.Unmarshal(params, target); err != nil {
+		return newRPCError(codeInvalidParams, "invalid params: "+err.Error())
+	}
+	return nil
+}
+
+// messageText собирает текст сообщения: текстовые части как есть, структурированные данные как JSON.
+// Файловые части не поддерживаются
+func messageText(message Message) (string, *RPCError) {
+	var texts []string
+	for _, part := range message.Parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "data":
+			data, err := json.Marshal(part.Data)
+			if err != nil {
+				return "", newRPCError(codeInvalidParams, "invalid data part: "+err.Error())
+			}
+			texts = append(texts, string(data))
+		default:
+			return "", newRPCError(codeInvalidParams, "unsupported message part type: "+part.Type)
+		}
+	}
+
+	text := strings.Join(texts, "\n")
+	if strings.TrimSpace(text) == "" {
+		return "", newRPCError(codeInvalidParams, "message must contain text")
+	}
+	return text, nil
+}
+
+// metadataString возвращает строковое значение из metadata
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
+
+// lastTransitionAt возвращает время последнего перехода задачи по журналу событий
+func lastTransitionAt(ctx context.Context, svc *service.TaskService, userID string, task models.Task) time.Time {
+	_, taskEvents, err := svc.History(ctx, userID, task.ID)
+	if err != nil || len(taskEvents) == 0 {
+		return task.CreatedAt
+	}
+	return taskEvents[len(taskEvents)-1].CreatedAt
+}
+
+// RPCHandler обработчик JSON-RPC эндпоинта A2A.
+// Поддерживает tasks/send, tasks/get, tasks/cancel и потоковые tasks/sendSubscribe, tasks/resubscribe
+func RPCHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			writeResponse(c, nil, nil, newRPCError(codeParseError, "failed to read request body"))
+			return
+		}
+
+		// Пакетные запросы не поддерживаются
+		if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+			writeResponse(c, nil, nil, newRPCError(codeInvalidRequest, "batch requests are not supported"))
+			return
+		}
+
+		var req RPCRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeResponse(c, nil, nil, newRPCError(codeParseError, "invalid JSON: "+err.Error()))
+			return
+		}
+
+		if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+			writeResponse(c, req.ID, nil, newRPCError(codeInvalidRequest, "invalid JSON-RPC 2.0 request"))
+			return
+		}
+
+		switch req.Method {
+		case methodTasksSend:
+			result, rpcErr := sendTask(c.Request.Context(), svc, userID.(string), req.Params)
+			writeResponse(c, req.ID, result, rpcErr)
+		case methodTasksGet:
+			result, rpcErr := getTask(c.Request.Context(), svc, userID.(string), req.Params)
+			writeResponse(c, req.ID, result, rpcErr)
+		case methodTasksCancel:
+			result, rpcErr := cancelTask(c.Request.Context(), svc, userID.(string), req.Params)
+			writeResponse(c, req.ID, result, rpcErr)
+		case methodTasksSendSubscribe:
+			streamSendTask(c, svc, userID.(string), req)
+		case methodTasksResubscribe:
+			streamResubscribe(c, svc, userID.(string), req)
+		case methodPushNotificationSet, methodPushNotificationGet:
+			writeResponse(c, req.ID, nil, newRPCError(codePushNotSupported, "push notifications are not supported, use webhooks instead"))
+		default:
+			writeResponse(c, req.ID, nil, newRPCError(codeMethodNotFound, "method not found: "+req.Method))
+		}
+	}
+}
+
+// sendTask создает задачу или передает ответ на вопрос исполнителя задаче в статусе input-required
+func sendTask(ctx context.Context, svc *service.TaskService, userID string, rawParams json.RawMessage) (*Task, *RPCError) {
+	var params TaskSendParams
+	if rpcErr := decodeParams(rawParams, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	task, rpcErr := applySend(ctx, svc, userID, params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	result := toA2ATask(*task, lastTransitionAt(ctx, svc, userID, *task), params.HistoryLength)
+	return &result, nil
+}
+
+// applySend выполняет tasks/send и возвращает задачу после изменения
+func applySend(ctx context.Context, svc *service.TaskService, userID string, params TaskSendParams) (*models.Task, *RPCError) {
+	taskID, rpcErr := parseTaskID(params.ID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	text, rpcErr := messageText(params.Message)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	task, err := svc.GetAccessibleTask(ctx, userID, taskID)
+	var serviceErr *service.Error
+	isNew := errors.As(err, &serviceErr) && serviceErr.Kind == service.KindNotFound
+	if err != nil && !isNew {
+		return nil, toRPCError(err)
+	}
+
+	// Новая задача
+	if isNew {
+		assignee := metadataString(params.Metadata, "assignee")
+		if assignee == "" {
+			return nil, newRPCError(codeInvalidParams, "metadata.assignee is required to create a task")
+		}
+
+		req := service.CreateTaskRequest{
+			TaskID:      &taskID,
+			Description: text,
+			Assignee:    assignee,
+		}
+
+		if parentTaskIDStr := metadataString(params.Metadata, "parent_task_id"); parentTaskIDStr != "" {
+			parentTaskID, err := uuid.Parse(parentTaskIDStr)
+			if err != nil {
+				return nil, newRPCError(codeInvalidParams, "metadata.parent_task_id must be a UUID")
+			}
+			req.ParentTaskID = &parentTaskID
+		}
+
+		task, err := svc.Create(ctx, userID, req)
+		if err != nil {
+			return nil, toRPCError(err)
+		}
+		return task, nil
+	}
+
+	// Существующая задача: продолжение диалога возможно только ответом на вопрос исполнителя
+	if task.Status != models.StatusInputRequired {
+		rpcErr := newRPCError(codeUnsupportedOperation, fmt.Sprintf("task already exists with status %s, new messages are accepted only in input-required state", task.Status))
+		rpcErr.Data = gin.H{"current_status": task.Status}
+		return nil, rpcErr
+	}
+
+	task, err = svc.ProvideInput(ctx, userID, taskID, text)
+	if err != nil {
+		return nil, toRPCError(err)
+	}
+	return task, nil
+}
+
+// getTask возвращает задачу в формате A2A
+func getTask(ctx context.Context, svc *service.TaskService, userID string, rawParams json.RawMessage) (*Task, *RPCError) {
+	var params TaskQueryParams
+	if rpcErr := decodeParams(rawParams, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	taskID, rpcErr := parseTaskID(params.ID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	task, err := svc.GetAccessibleTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, toRPCError(err)
+	}
+
+	result := toA2ATask(*task, lastTransitionAt(ctx, svc, userID, *task), params.HistoryLength)
+	return &result, nil
+}
+
+// cancelTask отменяет задачу и её подзадачи
+func cancelTask(ctx context.Context, svc *service.TaskService, userID string, rawParams json.RawMessage) (*Task, *RPCError) {
+	var params TaskIDParams
+	if rpcErr := decodeParams(rawParams, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	taskID, rpcErr := parseTaskID(params.ID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	task, err := svc.Cancel(ctx, userID, taskID)
+	if err != nil {
+		rpcErr := toRPCError(err)
+		// Задача уже в финальном статусе
+		if rpcErr.Code == codeInvalidParams {
+			rpcErr.Code = codeTaskNotCancelable
+		}
+		return nil, rpcErr
+	}
+
+	result := toA2ATask(*task, lastTransitionAt(ctx, svc, userID, *task), nil)
+	return &result, nil
+}
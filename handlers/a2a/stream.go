@@ -0,0 +1,161 @@
+package a2a
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAliveInterval интервал отправки комментариев, не дающих прокси закрыть простаивающее соединение
+const sseKeepAliveInterval = 15 * time.Second
+
+// writeStreamResponse записывает ответ JSON-RPC как событие Server-Sent Events
+func writeStreamResponse(c *gin.Context, id json.RawMessage, result interface{}) error {
+	payload, err := json.Marshal(RPCResponse{
+		JSONRPC: jsonRPCVersion,
+		ID:      id,
+		Result:  result,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// writeTaskUpdate отправляет результат завершенной задачи и её состояние.
+// Возвращает true, если состояние финальное и поток нужно закрыть
+func writeTaskUpdate(c *gin.Context, id json.RawMessage, task models.Task, timestamp time.Time) (bool, error) {
+	if artifact, ok := resultArtifact(task); ok {
+		artifact.LastChunk = true
+		if err := writeStreamResponse(c, id, TaskArtifactUpdateEvent{
+			ID:       task.ID.String(),
+			Artifact: artifact,
+		}); err != nil {
+			return false, err
+		}
+	}
+
+	status := taskStatus(task, timestamp)
+	final := isFinalState(status.State)
+	if err := writeStreamResponse(c, id, TaskStatusUpdateEvent{
+		ID:     task.ID.String(),
+		Status: status,
+		Final:  final,
+	}); err != nil {
+		return false, err
+	}
+	return final, nil
+}
+
+// streamSendTask выполняет tasks/sendSubscribe: создает задачу или передает ответ и транслирует изменения статуса
+func streamSendTask(c *gin.Context, svc *service.TaskService, userID string, req RPCRequest) {
+	var params TaskSendParams
+	if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+		writeResponse(c, req.ID, nil, rpcErr)
+		return
+	}
+
+	task, rpcErr := applySend(c.Request.Context(), svc, userID, params)
+	if rpcErr != nil {
+		writeResponse(c, req.ID, nil, rpcErr)
+		return
+	}
+
+	streamTask(c, svc, userID, req.ID, *task)
+}
+
+// streamResubscribe выполняет tasks/resubscribe: транслирует изменения статуса существующей задачи
+func streamResubscribe(c *gin.Context, svc *service.TaskService, userID string, req RPCRequest) {
+	var params TaskIDParams
+	if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+		writeResponse(c, req.ID, nil, rpcErr)
+		return
+	}
+
+	taskID, rpcErr := parseTaskID(params.ID)
+	if rpcErr != nil {
+		writeResponse(c, req.ID, nil, rpcErr)
+		return
+	}
+
+	task, err := svc.GetAccessibleTask(c.Request.Context(), userID, taskID)
+	if err != nil {
+		writeResponse(c, req.ID, nil, toRPCError(err))
+		return
+	}
+
+	streamTask(c, svc, userID, req.ID, *task)
+}
+
+// streamTask транслирует изменения статуса задачи, пока она не перейдет в финальное состояние
+// или клиент не отключится
+func streamTask(c *gin.Context, svc *service.TaskService, userID string, id json.RawMessage, task models.Task) {
+	rootTaskID := task.ID
+	if task.RootTaskID != nil {
+		rootTaskID = *task.RootTaskID
+	}
+
+	// Подписываемся до чтения текущего состояния, чтобы не пропустить переход между чтением и подпиской
+	_, _, stream, unsubscribe, err := events.Subscribe(rootTaskID, 0, nil)
+	if err != nil {
+		writeResponse(c, id, nil, toRPCError(err))
+		return
+	}
+	defer unsubscribe()
+
+	current, err := svc.GetAccessibleTask(c.Request.Context(), userID, task.ID)
+	if err != nil {
+		writeResponse(c, id, nil, toRPCError(err))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Сначала отправляем текущее состояние задачи
+	final, err := writeTaskUpdate(c, id, *current, lastTransitionAt(c.Request.Context(), svc, userID, *current))
+	if err != nil || final {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				// Подписка закрыта из-за переполнения или переподключения к Postgres - клиент продолжит через tasks/resubscribe
+				return
+			}
+			if event.TaskID != task.ID {
+				continue
+			}
+			final, err := writeTaskUpdate(c, id, event.Task, event.OccurredAt)
+			if err != nil || final {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package a2a
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Коды ошибок JSON-RPC 2.0 и протокола A2A
+const (
+	codeParseError           = -32700
+	codeInvalidRequest       = -32600
+	codeMethodNotFound       = -32601
+	codeInvalidParams        = -32602
+	codeInternalError        = -32603
+	codeTaskNotFound         = -32001
+	codeTaskNotCancelable    = -32002
+	codePushNotSupported     = -32003
+	codeUnsupportedOperation = -32004
+)
+
+// Методы A2A
+const (
+	jsonRPCVersion            = "2.0"
+	methodTasksSend           = "tasks/send"
+	methodTasksGet            = "tasks/get"
+	methodTasksCancel         = "tasks/cancel"
+	methodTasksSendSubscribe  = "tasks/sendSubscribe"
+	methodTasksResubscribe    = "tasks/resubscribe"
+	methodPushNotificationSet = "tasks/pushNotification/set"
+	methodPushNotificationGet = "tasks/pushNotification/get"
+)
+
+// Состояния задачи A2A, не совпадающие с models.TaskStatus
+const (
+	stateWorking = "working"
+	stateFailed  = "failed"
+	stateUnknown = "unknown"
+)
+
+// RPCRequest запрос JSON-RPC 2.0
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// RPCResponse ответ JSON-RPC 2.0
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError ошибка JSON-RPC 2.0
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Part часть сообщения A2A. Поддерживаются текстовые части и структурированные данные
+type Part struct {
+	Type     string                 `json:"type"` // text, data или file
+	Text     string                 `json:"text,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Message сообщение A2A от пользователя или агента
+type Message struct {
+	Role     string                 `json:"role"` // user или agent
+	Parts    []Part                 `json:"parts"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskStatus состояние задачи A2A
+type TaskStatus struct {
+	State     string    `json:"state"`
+	Message   *Message  `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Artifact результат задачи A2A
+type Artifact struct {
+	Name      string `json:"name,omitempty"`
+	Parts     []Part `json:"parts"`
+	Index     int    `json:"index"`
+	LastChunk bool   `json:"lastChunk,omitempty"`
+}
+
+// Task задача A2A поверх models.Task
+type Task struct {
+	ID        string                 `json:"id"`
+	SessionID string                 `json:"sessionId,omitempty"` // ID корневой задачи
+	Status    TaskStatus             `json:"status"`
+	Artifacts []Artifact             `json:"artifacts,omitempty"`
+	History   []Message              `json:"history,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskSendParams параметры tasks/send и tasks/sendSubscribe.
+// В metadata передаются assignee (обязателен для новой задачи) и parent_task_id
+type TaskSendParams struct {
+	ID            string                 `json:"id"`
+	SessionID     string                 `json:"sessionId,omitempty"`
+	Message       Message                `json:"message"`
+	HistoryLength *int                   `json:"historyLength,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskQueryParams параметры tasks/get
+type TaskQueryParams struct {
+	ID            string `json:"id"`
+	HistoryLength *int   `json:"historyLength,omitempty"`
+}
+
+// TaskIDParams параметры tasks/cancel и tasks/resubscribe
+type TaskIDParams struct {
+	ID string `json:"id"`
+}
+
+// TaskStatusUpdateEvent событие изменения статуса в потоке tasks/sendSubscribe
+type TaskStatusUpdateEvent struct {
+	ID     string     `json:"id"`
+	Status TaskStatus `json:"status"`
+	Final  bool       `json:"final"`
+}
+
+// TaskArtifactUpdateEvent событие появления результата в потоке tasks/sendSubscribe
+type TaskArtifactUpdateEvent struct {
+	ID       string   `json:"id"`
+	Artifact Artifact `json:"artifact"`
+}
+
+// AgentCard описание агента для /.well-known/agent.json
+type AgentCard struct {
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	URL                string            `json:"url"`
+	Version            string            `json:"version"`
+	DocumentationURL   string            `json:"documentationUrl,omitempty"`
+	Capabilities       AgentCapabilities `json:"capabilities"`
+	Authentication     AgentAuth         `json:"authentication"`
+	DefaultInputModes  []string          `json:"defaultInputModes"`
+	DefaultOutputModes []string          `json:"defaultOutputModes"`
+	Skills             []AgentSkill      `json:"skills"`
+}
+
+// AgentCapabilities возможности агента
+type AgentCapabilities struct {
+	Streaming              bool `json:"streaming"`
+	PushNotifications      bool `json:"pushNotifications"`
+	StateTransitionHistory bool `json:"stateTransitionHistory"`
+}
+
+// AgentAuth схемы аутентификации агента
+type AgentAuth struct {
+	Schemes []string `json:"schemes"`
+}
+
+// AgentSkill навык агента
+type AgentSkill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
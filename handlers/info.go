@@ -78,7 +78,7 @@ func InfoHandler() gin.HandlerFunc {
 					{
 						Method:      "POST",
 						Path:        "/generate-jwt",
-						Description: "Generate JWT token for authentication (Rate limit: 5 requests per minute per IP)",
+						Description: "Generate JWT token for authentication (Rate limit: 5 requests per minute per IP). With JWT_SIGNING_KEYS the token is signed with the active RS256/EdDSA key and carries its kid; otherwise it is signed with SECRET_KEY (HS256)",
 						Auth:        false,
 						Request: map[string]interface{}{
 							"body": map[string]interface{}{
@@ -103,6 +103,18 @@ func InfoHandler() gin.HandlerFunc {
 							{Code: 429, Description: "Rate limit exceeded"},
 						},
 					},
+					{
+						Method:      "GET",
+						Path:        "/.well-known/jwks.json",
+						Description: "Public JWT verification keys (JWKS) so other services can verify tokens without the signing secret; empty when only SECRET_KEY (HMAC) is used",
+						Auth:        false,
+						Response: map[string]interface{}{
+							"keys": []map[string]string{
+								{"kty": "OKP", "kid": "2024-12", "use": "sig", "alg": "EdDSA", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+								{"kty": "RSA", "kid": "2024-06", "use": "sig", "alg": "RS256", "n": "0vx7agoebGcQSuuPiLJXZpt...", "e": "AQAB"},
+							},
+						},
+					},
 					{
 						Method:      "GET",
 						Path:        "/me",
@@ -141,6 +153,24 @@ func InfoHandler() gin.HandlerFunc {
 										"REDIS_URL": "redis://localhost:6379",
 									},
 								},
+								"references": map[string]string{
+									"GITHUB_TOKEN": "secretref://github.token",
+									"DB_PASSWORD":  "file:///run/secrets/db_password",
+								},
+								"_note": "Encrypted at rest when CREDENTIALS_ENCRYPTION_KEYS is set; never returned in task responses and handed out only to the assignee by GET /task. Values may be secretref://<name> (SECRET_STORE: file, env or vault) or file:///path (inside SECRET_FILE_DIR) references permitted to the creator by SECRET_ACCESS_POLICY, checked again and resolved only by GET /task; REQUIRE_CREDENTIAL_REFERENCES rejects raw values",
+							},
+							"inherit_credentials": "List of the parent's credential services copied into the subtask, e.g. [\"github\"] (optional, requires parent_task_id; only the parent's assignee or creator may grant them, secret references stay references)",
+							"run_at":              "Task is not handed out before this time, ISO 8601 (optional)",
+							"input":               "Structured task input, any JSON value (optional; required if input_schema is set)",
+							"input_schema":        "JSON Schema the input must match, draft 2020-12 by default; external $ref is not resolved (optional)",
+							"output_schema":       "JSON Schema the output must match when the task is completed (optional)",
+							"on_child_failure":    "Reaction to a finally failed subtask: wait (default, keep waiting), resubmit_parent (return this task to 'submitted'), fail_parent (cancel other subtasks and fail this task, cascading up), cancel_siblings (cancel other subtasks and return this task to 'submitted')",
+							"depends_on":          "List of task UUIDs that must be completed first; the task stays 'waiting' until then (optional, up to 100, cycles and already failed, canceled or rejected dependencies are rejected)",
+							"priority":            "Task priority from -100 to 100, higher is handed out first (optional, default 0; subtasks inherit parent's priority)",
+							"retry_policy": map[string]interface{}{
+								"max_attempts":         "Total number of attempts including the first one (optional, 1-100, default 1 - no retries)",
+								"backoff_base_seconds": "Delay before the first retry, doubled on each next attempt (optional, default 30)",
+								"max_delay_seconds":    "Maximum delay between attempts (optional, default 3600)",
 							},
 						},
 						Response: map[string]interface{}{
@@ -153,24 +183,36 @@ func InfoHandler() gin.HandlerFunc {
 							"root_task_id":   "123e4567-e89b-12d3-a456-426614174000",
 							"parent_task_id": nil,
 							"result":         "",
-							"credentials":    "{}",
 							"status":         "submitted",
 						},
 						Errors: []ErrorInfo{
-							{Code: 400, Description: "Invalid data format or parent task in invalid status"},
+							{Code: 400, Description: "Invalid data format, parent task in invalid status or inherited service missing from the parent's credentials"},
 							{Code: 401, Description: "Authorization required"},
+							{Code: 403, Description: "inherit_credentials given by a user who is neither the parent's assignee nor its creator"},
 						},
 					},
 					{
 						Method:      "GET",
 						Path:        "/task",
-						Description: "Get task for work (takes first available task where assignee = current user and status = submitted)",
+						Description: "Get task for work (takes available task where assignee = current user and status = submitted with the highest priority, oldest first)",
 						Auth:        true,
+						Request: map[string]interface{}{
+							"query_params": map[string]string{
+								"wait":           "Long-polling timeout in seconds or as duration like 30s (optional, capped by LONG_POLL_MAX_WAIT). Request is held open until a task becomes available or timeout expires",
+								"children_depth": "Levels of subtasks of any status to return in 'children' with status and result (optional, 1-10; 1 - direct subtasks only, more - nested tree; credentials are not included)",
+							},
+						},
 						Response: map[string]interface{}{
-							"id":          "123e4567-e89b-12d3-a456-426614174000",
-							"status":      "working",
-							"description": "Analyze data",
-							"_note":       "Status automatically changes to 'working'",
+							"id":               "123e4567-e89b-12d3-a456-426614174000",
+							"status":           "working",
+							"description":      "Analyze data",
+							"_note":            "Status automatically changes to 'working' and a lease is granted for LEASE_TTL",
+							"lease_expires_at": "2024-01-20T11:00:00Z",
+							"requeue_count":    0,
+							"credentials": map[string]interface{}{
+								"postgres": map[string]string{"DB_PASSWORD": "secret123"},
+								"_note":    "Decrypted, with secret references resolved, only for this response to the assignee",
+							},
 							"completed_subtasks": []map[string]interface{}{
 								{
 									"id":          "456e7890-e89b-12d3-a456-426614174001",
@@ -179,9 +221,168 @@ func InfoHandler() gin.HandlerFunc {
 									"result":      "Subtask completed successfully",
 								},
 							},
+							"rejected_subtasks": []map[string]interface{}{
+								{
+									"id":          "456e7890-e89b-12d3-a456-426614174002",
+									"description": "Subtask 2",
+									"status":      "rejected",
+									"result":      "REJECTION REASON: Task is outside of my capabilities",
+								},
+							},
+							"children": []map[string]interface{}{
+								{
+									"id":          "456e7890-e89b-12d3-a456-426614174004",
+									"description": "Subtask 4",
+									"status":      "canceled",
+									"result":      "",
+									"children":    []map[string]interface{}{},
+									"_note":       "Only with children_depth: all subtasks of any status, nested up to the requested depth",
+								},
+							},
+							"failed_subtasks": []map[string]interface{}{
+								{
+									"id":          "456e7890-e89b-12d3-a456-426614174003",
+									"description": "Subtask 3",
+									"status":      "failed",
+									"result":      "FAILURE REASON: Could not connect to database",
+								},
+							},
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid wait or children_depth parameter"},
+							{Code: 404, Description: "No available tasks for this user (after waiting, if wait is specified)"},
+							{Code: 401, Description: "Authorization required"},
+							{Code: 500, Description: "The secret store is unavailable; the task is not handed out. Tasks whose credentials cannot be decrypted or resolved are dead-lettered with FAILURE REASON and the next task is claimed"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/task/:id/history",
+						Description: "Get audit log of task status transitions: who changed what and when (available to task creator, assignee and root task creator)",
+						Auth:        true,
+						Response: map[string]interface{}{
+							"task_id": "123e4567-e89b-12d3-a456-426614174000",
+							"status":  "completed",
+							"events": []map[string]interface{}{
+								{
+									"id":           1,
+									"created_at":   "2024-01-20T10:30:00Z",
+									"task_id":      "123e4567-e89b-12d3-a456-426614174000",
+									"root_task_id": "123e4567-e89b-12d3-a456-426614174000",
+									"type":         "created",
+									"to_status":    "submitted",
+									"actor":        "manager",
+								},
+								{
+									"id":           2,
+									"created_at":   "2024-01-20T10:31:00Z",
+									"task_id":      "123e4567-e89b-12d3-a456-426614174000",
+									"root_task_id": "123e4567-e89b-12d3-a456-426614174000",
+									"type":         "claimed",
+									"from_status":  "submitted",
+									"to_status":    "working",
+									"actor":        "agent1",
+								},
+							},
+							"_note": "Event types are the same as in GET /root-task/:id/events plus 'waiting' (parent moved to waiting by subtask creation); actor is 'system' for scheduler transitions (lease requeue, recurring spawn)",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format"},
+							{Code: 403, Description: "Access denied: you are not the creator or assignee of this task"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/task/:id/credential-access",
+						Description: "Get the append-only log of credentials handed out with this task, newest first (available to the task creator, also after the task is deleted, and to ADMIN_USERS)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"query_params": map[string]string{
+								"limit": "Maximum number of records (optional, default 100, max 1000)",
+							},
+						},
+						Response: map[string]interface{}{
+							"task_id": "123e4567-e89b-12d3-a456-426614174000",
+							"accesses": []map[string]interface{}{
+								{
+									"id":              42,
+									"accessed_at":     "2024-01-20T10:31:00Z",
+									"task_id":         "123e4567-e89b-12d3-a456-426614174000",
+									"root_task_id":    "123e4567-e89b-12d3-a456-426614174000",
+									"task_created_by": "manager",
+									"assignee":        "agent1",
+									"services":        []string{"github"},
+									"references":      []string{"secretref://github.token"},
+									"client_ip":       "10.0.0.7",
+									"protocol":        "http",
+								},
+							},
+							"_note": "A record is written in the claim transaction whenever a claim (GET /task, MCP get_task, gRPC Claim) hands out credentials; services lists the credential services exposed, references the secret references resolved. Values are never logged",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format or limit"},
+							{Code: 403, Description: "Access denied: only the task creator and admins can view its credential access log"},
+							{Code: 404, Description: "Task not found and no records of yours for it"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/credential-access",
+						Description: "Search the credential access log, newest first: admins (ADMIN_USERS) see records of all tasks, other users only of tasks they created",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"query_params": map[string]string{
+								"task_id":   "Only records of this task (optional)",
+								"assignee":  "Only credentials handed to this assignee (optional)",
+								"service":   "Only records that exposed this credential service (optional)",
+								"reference": "Only records that resolved this secret reference, e.g. secretref://github.token (optional)",
+								"since":     "Only records at or after this time, RFC 3339 (optional)",
+								"until":     "Only records before this time, RFC 3339 (optional)",
+								"limit":     "Maximum number of records (optional, default 100, max 1000)",
+							},
+						},
+						Response: []map[string]interface{}{
+							{
+								"id":              42,
+								"accessed_at":     "2024-01-20T10:31:00Z",
+								"task_id":         "123e4567-e89b-12d3-a456-426614174000",
+								"root_task_id":    "123e4567-e89b-12d3-a456-426614174000",
+								"task_created_by": "manager",
+								"assignee":        "agent1",
+								"services":        []string{"github"},
+								"references":      []string{"secretref://github.token"},
+								"client_ip":       "10.0.0.7",
+								"protocol":        "http",
+							},
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid task_id, since, until or limit"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "POST",
+						Path:        "/task/:id/heartbeat",
+						Description: "Extend lease of a task in work (only assignee can send heartbeat)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"_note": "Request body not required",
+						},
+						Response: map[string]interface{}{
+							"id":               "123e4567-e89b-12d3-a456-426614174000",
+							"status":           "working",
+							"lease_expires_at": "2024-01-20T11:00:00Z",
+							"requeue_count":    0,
+							"_note":            "Lease is extended by LEASE_TTL from the moment of the heartbeat",
 						},
 						Errors: []ErrorInfo{
-							{Code: 404, Description: "No available tasks for this user"},
+							{Code: 400, Description: "Invalid ID format"},
+							{Code: 403, Description: "Only assignee can send heartbeat"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 409, Description: "Task is not in 'working' status (e.g. lease already expired and task was requeued)"},
 							{Code: 401, Description: "Authorization required"},
 						},
 					},
@@ -192,6 +393,7 @@ func InfoHandler() gin.HandlerFunc {
 						Auth:        true,
 						Request: map[string]interface{}{
 							"description": "Task execution result (required)",
+							"output":      "Structured task result, any JSON value (optional; required and validated if the task has an output_schema)",
 							"delete_at":   "New deletion date ISO 8601 (optional)",
 						},
 						Response: map[string]interface{}{
@@ -201,7 +403,7 @@ func InfoHandler() gin.HandlerFunc {
 							"_note":  "When completing a task, all active subtasks (submitted, working, waiting) are automatically canceled. If all subtasks are completed or canceled, parent task is moved to status = submitted",
 						},
 						Errors: []ErrorInfo{
-							{Code: 400, Description: "Task not in 'working' status or invalid data format"},
+							{Code: 400, Description: "Task not in 'working' status, invalid data format or output does not match output_schema"},
 							{Code: 403, Description: "Only assignee can complete task"},
 							{Code: 404, Description: "Task not found"},
 							{Code: 401, Description: "Authorization required"},
@@ -218,15 +420,79 @@ func InfoHandler() gin.HandlerFunc {
 						Response: map[string]interface{}{
 							"id":     "123e4567-e89b-12d3-a456-426614174000",
 							"status": "canceled",
-							"_note":  "When canceling a task, all active subtasks (submitted, working, waiting) are recursively canceled. If all parent's subtasks are now completed or canceled, parent is moved to status = submitted",
+							"_note":  "When canceling a task, all active subtasks (submitted, working, waiting, input-required) are recursively canceled. If all parent's subtasks are now completed, canceled or rejected, parent is moved to status = submitted",
 						},
 						Errors: []ErrorInfo{
-							{Code: 400, Description: "Cannot cancel task with status completed, canceled or failed"},
+							{Code: 400, Description: "Cannot cancel task with status completed, canceled, failed, dead-lettered or rejected"},
 							{Code: 403, Description: "Only assignee or creator can cancel task"},
 							{Code: 404, Description: "Task not found"},
 							{Code: 401, Description: "Authorization required"},
 						},
 					},
+					{
+						Method:      "POST",
+						Path:        "/task/:id/reject",
+						Description: "Decline a submitted task before taking it to work (only assignee can reject task)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"reason": "Rejection reason (required)",
+						},
+						Response: map[string]interface{}{
+							"id":     "123e4567-e89b-12d3-a456-426614174000",
+							"status": "rejected",
+							"result": "REJECTION REASON: Task is outside of my capabilities",
+							"_note":  "If all parent's subtasks are now completed, canceled or rejected, parent is moved to status = submitted and receives rejected subtasks in GET /task response",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Task not in 'submitted' status or invalid data format"},
+							{Code: 403, Description: "Only assignee can reject task"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "POST",
+						Path:        "/task/:id/request-input",
+						Description: "Pause a task in work with a question to its creator (only assignee can request input)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"question": "Question for the task creator (required)",
+						},
+						Response: map[string]interface{}{
+							"id":             "123e4567-e89b-12d3-a456-426614174000",
+							"status":         "input-required",
+							"input_question": "Which environment should be deployed?",
+							"_note":          "Lease is released; task is not handed out by GET /task until the creator provides input",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Task not in 'working' status or invalid data format"},
+							{Code: 403, Description: "Only assignee can request input"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "POST",
+						Path:        "/task/:id/provide-input",
+						Description: "Answer assignee's question and return the task to the queue (only creator can provide input)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"answer": "Answer to the question (required)",
+						},
+						Response: map[string]interface{}{
+							"id":             "123e4567-e89b-12d3-a456-426614174000",
+							"status":         "submitted",
+							"input_question": "Which environment should be deployed?",
+							"input_answer":   "staging",
+							"_note":          "Assignee gets the task with input_question and input_answer from GET /task",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Task not in 'input-required' status or invalid data format"},
+							{Code: 403, Description: "Only creator can provide input"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
 					{
 						Method:      "POST",
 						Path:        "/tasks/:id/fail",
@@ -239,7 +505,7 @@ func InfoHandler() gin.HandlerFunc {
 							"id":     "123e4567-e89b-12d3-a456-426614174000",
 							"status": "failed",
 							"result": "FAILURE REASON: Could not connect to database",
-							"_note":  "Parent task remains in waiting status and waits for completion of other subtasks",
+							"_note":  "When the task finally fails (failed or dead-lettered), the parent reacts by its on_child_failure policy; with the default 'wait' it remains in waiting status. If task has a retry policy with attempts left, it returns to 'submitted' with not_before set by exponential backoff; when attempts run out it becomes 'dead-lettered'",
 						},
 						Errors: []ErrorInfo{
 							{Code: 400, Description: "Task not in 'working' status or invalid data format"},
@@ -248,6 +514,45 @@ func InfoHandler() gin.HandlerFunc {
 							{Code: 401, Description: "Authorization required"},
 						},
 					},
+					{
+						Method:      "GET",
+						Path:        "/dead-letter",
+						Description: "Get dead-lettered tasks created by or assigned to current user",
+						Auth:        true,
+						Response: []map[string]interface{}{
+							{
+								"id":           "123e4567-e89b-12d3-a456-426614174000",
+								"status":       "dead-lettered",
+								"result":       "FAILURE REASON: Could not connect to database",
+								"max_attempts": 3,
+								"attempts":     3,
+								"_note":        "Credentials field excluded from output",
+							},
+						},
+						Errors: []ErrorInfo{
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "POST",
+						Path:        "/task/:id/redrive",
+						Description: "Return dead-lettered task to the queue with reset attempts counter (only creator can redrive task); the parent task goes back to waiting",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"_note": "Request body not required",
+						},
+						Response: map[string]interface{}{
+							"id":       "123e4567-e89b-12d3-a456-426614174000",
+							"status":   "submitted",
+							"attempts": 0,
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Task not in 'dead-lettered' status or parent task no longer waits for it"},
+							{Code: 403, Description: "Only creator can redrive task"},
+							{Code: 404, Description: "Task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
 					{
 						Method:      "GET",
 						Path:        "/root-task/:id/tasks",
@@ -264,7 +569,8 @@ func InfoHandler() gin.HandlerFunc {
 								"parent_task_id": nil,
 								"result":         "",
 								"status":         "submitted",
-								"_note":          "Credentials field excluded from output",
+								"scheduled":      false,
+								"_note":          "Credentials field excluded from output. scheduled = true for submitted tasks whose run_at or retry not_before has not arrived yet",
 							},
 							{
 								"id":             "456e7890-e89b-12d3-a456-426614174001",
@@ -285,6 +591,43 @@ func InfoHandler() gin.HandlerFunc {
 							{Code: 401, Description: "Authorization required"},
 						},
 					},
+					{
+						Method:      "GET",
+						Path:        "/root-task/:id/events",
+						Description: "Server-Sent Events stream of task changes in the root task tree (available only to root task creator)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"headers": map[string]string{
+								"Last-Event-ID": "ID of the last received event to resume the stream (optional)",
+							},
+							"query_params": map[string]string{
+								"last_event_id": "Same as Last-Event-ID header, for clients that cannot set headers (optional)",
+							},
+						},
+						Response: map[string]interface{}{
+							"_format": "text/event-stream",
+							"id":      4812,
+							"event":   "completed",
+							"data": map[string]interface{}{
+								"id":           4812,
+								"type":         "completed",
+								"root_task_id": "123e4567-e89b-12d3-a456-426614174000",
+								"task_id":      "456e7890-e89b-12d3-a456-426614174001",
+								"status":       "completed",
+								"from_status":  "working",
+								"actor":        "agent1",
+								"task":         "Task object without credentials",
+								"occurred_at":  "2024-01-20T10:40:00Z",
+							},
+							"_note": "Event types: created, claimed, completed, failed, canceled, waiting, rejected, input-required, input-provided, resubmitted, requeued, redriven, unblocked. Event IDs are task_events audit log IDs; missed events since Last-Event-ID are read from the audit log. If more than 1000 events were missed, a 'resync' event is sent instead and the tree should be reloaded via GET /root-task/:id/tasks",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID or last event ID format"},
+							{Code: 403, Description: "Access denied: you are not the creator of the root task"},
+							{Code: 404, Description: "Root task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
 					{
 						Method:      "GET",
 						Path:        "/root-task",
@@ -314,6 +657,321 @@ func InfoHandler() gin.HandlerFunc {
 						},
 					},
 				},
+				"Recurring Tasks": {
+					{
+						Method:      "POST",
+						Path:        "/recurring-task",
+						Description: "Create recurring task definition that spawns a new root task on each cron tick",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"name":                 "Definition name (required)",
+							"cron_expression":      "Standard 5-field cron expression or descriptor like @hourly (required)",
+							"timezone":             "IANA timezone for the schedule (optional, default UTC)",
+							"assignee":             "Assignee ID for spawned tasks (optional)",
+							"description_template": "Go text/template for task description with fields .Name, .ScheduledAt, .RunNumber (required)",
+							"credentials":          "Credentials for spawned tasks, same format as in POST /task (optional)",
+							"priority":             "Priority for spawned tasks from -100 to 100 (optional, default 0)",
+							"overlap_policy":       "What to do when previous instance is still active: skip or queue (optional, default skip)",
+							"enabled":              "Whether the schedule is active (optional, default true)",
+						},
+						Response: map[string]interface{}{
+							"id":                   "123e4567-e89b-12d3-a456-426614174000",
+							"name":                 "Daily report",
+							"cron_expression":      "0 9 * * 1-5",
+							"timezone":             "Europe/Moscow",
+							"assignee":             "agent1",
+							"description_template": "Prepare report for {{.ScheduledAt.Format \"2006-01-02\"}}",
+							"overlap_policy":       "skip",
+							"enabled":              true,
+							"next_run_at":          "2024-01-22T09:00:00+03:00",
+							"run_count":            0,
+							"pending_runs":         0,
+							"_note":                "Credentials field excluded from output",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid cron expression, timezone, template, credentials or overlap policy"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/recurring-task",
+						Description: "Get list of current user's recurring task definitions",
+						Auth:        true,
+						Errors: []ErrorInfo{
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/recurring-task/:id",
+						Description: "Get recurring task definition (available only to its creator)",
+						Auth:        true,
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format"},
+							{Code: 403, Description: "Access denied: you are not the creator of this recurring task"},
+							{Code: 404, Description: "Recurring task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "PATCH",
+						Path:        "/recurring-task/:id",
+						Description: "Partially update recurring task definition (available only to its creator); next run is recalculated",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"_note": "Same fields as in POST /recurring-task, all optional",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format or invalid field values"},
+							{Code: 403, Description: "Access denied: you are not the creator of this recurring task"},
+							{Code: 404, Description: "Recurring task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "DELETE",
+						Path:        "/recurring-task/:id",
+						Description: "Delete recurring task definition (available only to its creator); already spawned tasks are kept",
+						Auth:        true,
+						Response: map[string]interface{}{
+							"id":      "123e4567-e89b-12d3-a456-426614174000",
+							"deleted": true,
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format"},
+							{Code: 403, Description: "Access denied: you are not the creator of this recurring task"},
+							{Code: 404, Description: "Recurring task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+				},
+				"Webhooks": {
+					{
+						Method:      "POST",
+						Path:        "/webhook",
+						Description: "Subscribe to task lifecycle events; matching events are POSTed as signed JSON to the URL",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"url":          "Absolute http(s) URL that receives events; must not resolve to loopback, private or link-local addresses unless listed in WEBHOOK_ALLOWED_PRIVATE_TARGETS (required)",
+							"filter_type":  "Which tasks to watch: creator, assignee or root_task (required)",
+							"filter_value": "Current user ID for creator/assignee (optional, defaults to current user) or root task ID for root_task (the root task must be created by current user)",
+							"event_types":  "List of event types: created, claimed, completed, failed, canceled, waiting, rejected, input-required, input-provided, resubmitted, requeued, redriven, unblocked (optional, default all)",
+							"secret":       "HMAC signing secret, at least 16 characters (optional, generated if omitted)",
+						},
+						Response: map[string]interface{}{
+							"id":           "123e4567-e89b-12d3-a456-426614174000",
+							"url":          "https://orchestrator.example.com/hooks/tasks",
+							"filter_type":  "root_task",
+							"filter_value": "223e4567-e89b-12d3-a456-426614174000",
+							"event_types":  []string{"completed", "failed"},
+							"active":       true,
+							"secret":       "9f86d081884c7d659a2feaa0c55ad015...",
+							"_note":        "Secret is returned only on creation",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid URL, URL resolving to an internal address, filter type, event type or secret"},
+							{Code: 403, Description: "Access denied: subscription to other user's tasks"},
+							{Code: 404, Description: "Root task not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/webhook",
+						Description: "Get list of current user's webhook subscriptions (without secrets)",
+						Auth:        true,
+						Errors: []ErrorInfo{
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "DELETE",
+						Path:        "/webhook/:id",
+						Description: "Delete webhook subscription together with its pending deliveries",
+						Auth:        true,
+						Response: map[string]interface{}{
+							"id":      "123e4567-e89b-12d3-a456-426614174000",
+							"deleted": true,
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format"},
+							{Code: 403, Description: "Access denied: you are not the creator of this webhook"},
+							{Code: 404, Description: "Webhook not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "GET",
+						Path:        "/webhook/:id/deliveries",
+						Description: "Get last 100 deliveries of webhook subscription",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"query_params": map[string]string{
+								"status": "Filter by delivery status: pending, delivered, failed (optional)",
+							},
+						},
+						Response: []map[string]interface{}{
+							{
+								"id":              "323e4567-e89b-12d3-a456-426614174000",
+								"subscription_id": "123e4567-e89b-12d3-a456-426614174000",
+								"event_id":        1718000000000001,
+								"event_type":      "completed",
+								"payload":         map[string]interface{}{"type": "completed", "task_id": "..."},
+								"status":          "pending",
+								"attempts":        2,
+								"next_attempt_at": "2024-01-15T10:32:00Z",
+								"last_error":      "unexpected response status: 502 Bad Gateway",
+							},
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid ID format or status"},
+							{Code: 403, Description: "Access denied: you are not the creator of this webhook"},
+							{Code: 404, Description: "Webhook not found"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "INFO",
+						Path:        "",
+						Description: "Webhook delivery format",
+						Auth:        false,
+						Response: map[string]interface{}{
+							"body": "Same JSON as GET /root-task/:id/events data: id (task_events row ID), type, root_task_id, task_id, status, from_status, actor, task (without credentials), occurred_at",
+							"headers": map[string]string{
+								"X-Webhook-Event":     "Event type",
+								"X-Webhook-Delivery":  "Delivery ID, the same for all retries of one delivery",
+								"X-Webhook-Timestamp": "Unix timestamp of the attempt",
+								"X-Webhook-Signature": "sha256=hex(HMAC-SHA256(secret, timestamp + \".\" + body))",
+							},
+							"retries": "Any non-2xx response or network error is retried with exponential backoff (30s, 1m, 2m, ... up to 6h); after 10 attempts the delivery is marked failed",
+						},
+					},
+				},
+				"A2A": {
+					{
+						Method:      "GET",
+						Path:        "/.well-known/agent.json",
+						Description: "A2A agent card describing the JSON-RPC endpoint, capabilities and authentication",
+						Auth:        false,
+						Response: map[string]interface{}{
+							"name":         "Agent Task Manager",
+							"url":          "https://tasks.example.com/a2a",
+							"version":      "1.0.0",
+							"capabilities": map[string]bool{"streaming": true, "pushNotifications": false, "stateTransitionHistory": false},
+							"authentication": map[string]interface{}{
+								"schemes": []string{"Bearer"},
+							},
+						},
+					},
+					{
+						Method:      "POST",
+						Path:        "/a2a",
+						Description: "A2A JSON-RPC 2.0 endpoint over the task store: tasks/send, tasks/get, tasks/cancel, tasks/sendSubscribe and tasks/resubscribe (SSE)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"jsonrpc": "2.0",
+							"id":      1,
+							"method":  "tasks/send",
+							"params": map[string]interface{}{
+								"id":       "123e4567-e89b-12d3-a456-426614174000",
+								"message":  map[string]interface{}{"role": "user", "parts": []map[string]string{{"type": "text", "text": "Summarize the report"}}},
+								"metadata": map[string]string{"assignee": "agent1", "parent_task_id": "Optional parent task ID"},
+							},
+							"_note": "Task ID must be a UUID. An unknown ID creates a task for metadata.assignee; a known ID in input-required state passes the message text as the answer",
+						},
+						Response: map[string]interface{}{
+							"jsonrpc": "2.0",
+							"id":      1,
+							"result": map[string]interface{}{
+								"id":        "123e4567-e89b-12d3-a456-426614174000",
+								"sessionId": "Root task ID",
+								"status":    map[string]interface{}{"state": "submitted", "timestamp": "2024-01-15T10:30:00Z"},
+								"artifacts": []map[string]interface{}{{"name": "result", "parts": []map[string]string{{"type": "text", "text": "Task result"}}, "index": 0}},
+							},
+							"_note": "Status waiting is reported as working and dead-lettered as failed; the result of a completed task is returned as the \"result\" artifact",
+						},
+						Errors: []ErrorInfo{
+							{Code: -32600, Description: "Invalid JSON-RPC request, batch request or access denied"},
+							{Code: -32601, Description: "Method not found"},
+							{Code: -32602, Description: "Invalid params: non-UUID task ID, missing assignee, file parts"},
+							{Code: -32001, Description: "Task not found"},
+							{Code: -32002, Description: "Task cannot be canceled in its current status"},
+							{Code: -32003, Description: "Push notifications are not supported (use webhooks)"},
+							{Code: -32004, Description: "Message sent to an existing task that is not in input-required state"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+				},
+				"MCP": {
+					{
+						Method:      "POST",
+						Path:        "/mcp",
+						Description: "Model Context Protocol server (streamable HTTP transport) exposing task operations as tools: create_task, get_task, complete_task, fail_task, cancel_task",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"jsonrpc": "2.0",
+							"id":      1,
+							"method":  "tools/call",
+							"params": map[string]interface{}{
+								"name":      "complete_task",
+								"arguments": map[string]string{"task_id": "123e4567-e89b-12d3-a456-426614174000", "description": "Task result"},
+							},
+							"_note": "Supports initialize, ping, tools/list and tools/call, single messages or batches. Tool input schemas are generated from the same request types as the REST API",
+						},
+						Response: map[string]interface{}{
+							"jsonrpc": "2.0",
+							"id":      1,
+							"result": map[string]interface{}{
+								"content":           []map[string]string{{"type": "text", "text": "{\"id\":\"123e4567-...\",\"status\":\"completed\",...}"}},
+								"structuredContent": map[string]string{"id": "123e4567-e89b-12d3-a456-426614174000", "status": "completed"},
+							},
+							"_note": "Operation errors (access denied, wrong status) are returned as tool results with isError: true and the same JSON body as REST errors. Notifications are answered with 202",
+						},
+						Errors: []ErrorInfo{
+							{Code: 400, Description: "Invalid JSON"},
+							{Code: 401, Description: "Authorization required"},
+						},
+					},
+					{
+						Method:      "INFO",
+						Path:        "",
+						Description: "MCP over stdio",
+						Auth:        false,
+						Response: map[string]interface{}{
+							"command": "agent-task-manager mcp",
+							"_note":   "Reads newline-delimited JSON-RPC from stdin and writes responses to stdout on behalf of the user of the MCP_TOKEN JWT; the token is re-checked for every message. Requires SECRET_KEY and POSTGRES_URL",
+						},
+					},
+				},
+				"gRPC": {
+					{
+						Method:      "INFO",
+						Path:        "",
+						Description: "TaskManager gRPC service on GRPC_PORT (proto/taskmanager/v1/task_manager.proto)",
+						Auth:        true,
+						Request: map[string]interface{}{
+							"metadata": map[string]string{"authorization": "Bearer <token>"},
+							"methods": map[string]string{
+								"Create":        "POST /task",
+								"Claim":         "GET /task?wait=N (wait_seconds)",
+								"Heartbeat":     "POST /task/:id/heartbeat",
+								"Complete":      "POST /task/:id/complete",
+								"Fail":          "POST /tasks/:id/fail",
+								"Cancel":        "POST /task/:id/cancel",
+								"ListRootTasks": "GET /root-task",
+								"GetTree":       "GET /root-task/:id/tasks",
+								"WatchTree":     "GET /root-task/:id/events (server stream, optional last_event_id)",
+							},
+						},
+						Response: map[string]interface{}{
+							"_note": "Operation errors map to INVALID_ARGUMENT, PERMISSION_DENIED, NOT_FOUND and FAILED_PRECONDITION with extra fields in google.rpc.ErrorInfo details. WatchTree sends headers once subscribed, missed events after last_event_id from the task_events audit log or a resync message if more than 1000 were missed, and ends with UNAVAILABLE if the client falls behind",
+						},
+						Errors: []ErrorInfo{
+							{Code: 16, Description: "UNAUTHENTICATED - missing, invalid or expired token, or blacklisted user"},
+						},
+					},
+				},
 				"Statistics": {
 					{
 						Method:      "GET",
@@ -331,7 +989,7 @@ func InfoHandler() gin.HandlerFunc {
 							"in_progress":   3,
 							"new_tasks":     25,
 							"failed_tasks":  2,
-							"_note":         "pending_tasks and in_progress show current state, new_tasks and failed_tasks are counted for specified period",
+							"_note":         "pending_tasks and in_progress show current state, new_tasks are counted by creation time and failed_tasks by failure time (from task history; tasks failed before the history was introduced are counted by creation time) for specified period",
 						},
 						Errors: []ErrorInfo{
 							{Code: 400, Description: "Invalid period parameter"},
@@ -354,7 +1012,10 @@ func InfoHandler() gin.HandlerFunc {
 						Response: map[string]interface{}{
 							"users": []string{"user1", "user2", "user3"},
 							"count": 3,
-							"_note": "If filter parameter is specified, only users from the list who have active tasks are returned",
+							"scheduled_users": []map[string]interface{}{
+								{"user_id": "user4", "next_run_at": "2024-01-21T09:00:00Z"},
+							},
+							"_note": "If filter parameter is specified, only users from the list who have active tasks are returned. Users whose only tasks are scheduled for the future (run_at or retry not_before) are returned in scheduled_users",
 						},
 						Errors: []ErrorInfo{
 							{Code: 401, Description: "Authorization required"},
@@ -380,6 +1041,7 @@ func InfoHandler() gin.HandlerFunc {
 					"canceled":       "Task canceled",
 					"rejected":       "Task rejected",
 					"input-required": "Additional input required",
+					"dead-lettered":  "All retry attempts failed, task can be redriven by creator",
 				},
 			},
 		}
@@ -395,18 +1057,42 @@ func InfoHandler() gin.HandlerFunc {
 					"rules": []string{
 						"1. When creating a subtask, parent task is automatically moved to 'waiting' status",
 						"2. Subtasks can only be created for tasks in statuses: waiting, working, submitted",
-						"3. When all subtasks are completed or canceled, parent task is automatically moved to 'submitted' status",
-						"4. When completing or canceling a task, all its active subtasks (submitted, working, waiting) are recursively canceled",
+						"3. When all subtasks are completed, canceled or rejected, parent task is automatically moved to 'submitted' status",
+						"4. When completing, canceling or rejecting a task, all its active subtasks (submitted, working, waiting, input-required) are recursively canceled",
 						"5. Only assignee can take task for work, complete or fail it",
 						"6. Assignee or task creator can cancel task",
 						"7. Tasks are automatically deleted after 3 months (can be changed during creation)",
 						"8. Each task has root_task_id for hierarchy tracking",
-						"9. When getting task (GET /task), response includes completed first-level subtasks",
+						"9. When getting task (GET /task), response includes completed, rejected and failed first-level subtasks; children_depth adds the full subtree of any status",
 						"10. Only root task creator can view all tasks in its hierarchy (GET /root-task/:id/tasks)",
 						"11. In-memory cache is used to store list of users with active tasks",
 						"12. Cache is synchronized with database on application startup",
 						"13. Cache is automatically synchronized with DB every 10 minutes (configurable via CACHE_SYNC_INTERVAL)",
 						"14. Automatic cleanup of tasks with expired DeleteAt runs every hour (configurable via CLEANUP_INTERVAL)",
+						"15. Taking a task (GET /task) grants a lease for LEASE_TTL; assignee extends it via POST /task/:id/heartbeat",
+						"16. Tasks in 'working' status with expired lease are returned to 'submitted' (checked every LEASE_CHECK_INTERVAL), requeue_count is incremented",
+						"17. Failed task with retry policy returns to 'submitted' and is not handed out before not_before until max_attempts run out, then it becomes 'dead-lettered'",
+						"18. GET /task hands out tasks by priority (higher first), then by created_at; with PRIORITY_AGING_INTERVAL set, effective priority grows by 1 for each interval of waiting",
+						"19. Subtask without explicit priority inherits parent's priority",
+						"20. Task with run_at in the future is not handed out by GET /task and is shown as scheduled",
+						"21. Recurring task definitions spawn a new root task on each cron tick (checked every RECURRING_CHECK_INTERVAL); if previous instance is still active, the run is skipped or queued according to overlap_policy",
+						"22. GET /task?wait=N holds the request until a task for the user becomes submitted (notified on create, parent resubmit, retry, redrive, lease requeue and recurring spawn; across replicas via Postgres LISTEN/NOTIFY)",
+						"23. Every create, claim, complete, fail and cancel (including cascaded cancels) is pushed to GET /root-task/:id/events subscribers of the tree",
+						"24. Every status transition is written to the task_events audit log in the same transaction and can be read via GET /task/:id/history",
+						"25. Assignee can pause a task in work with a question (input-required); the creator answers via provide-input and the task returns to 'submitted' with the answer attached",
+						"26. Assignee can reject a submitted task with a reason; rejected subtasks are returned to the parent's assignee in GET /task",
+						"27. The same events are stored in a persistent delivery queue for matching webhook subscriptions in the transition transaction and POSTed with HMAC signature (checked every WEBHOOK_DELIVERY_INTERVAL); internal addresses are rejected unless allowed by WEBHOOK_ALLOWED_PRIVATE_TARGETS",
+						"28. A2A clients use POST /a2a: each A2A task is a task in the same store, tasks/sendSubscribe streams status updates until a final or input-required state",
+						"29. LLM agents can use the same operations as MCP tools via POST /mcp or the 'mcp' stdio command",
+						"30. The TaskManager gRPC service on GRPC_PORT exposes the same operations and streams tree events via WatchTree, with the same JWT checks as the HTTP API",
+						"31. A task with depends_on waits until all its dependencies are completed and is then moved to 'submitted' with an 'unblocked' event; if a dependency fails, is dead-lettered, canceled or rejected, the waiting task is moved to 'failed' with the dependency in the failure reason",
+						"32. A finally failed subtask (failed or dead-lettered) is handled by the parent's on_child_failure policy: wait, resubmit_parent, fail_parent (cascades up the tree) or cancel_siblings",
+						"33. Structured input is validated against input_schema at creation and output against output_schema at completion",
+						"34. Task and recurring task credentials are encrypted at rest (AES-256-GCM envelope encryption, key ID stored per row) and decrypted only when the task is handed to its assignee or a recurring definition spawns a task; keys are rotated with the reencrypt-credentials command",
+						"35. Credential values may be secretref:// or file:// references; they are validated at creation and resolved only when the task is handed to its assignee",
+						"36. A subtask inherits only the parent's credential services listed in inherit_credentials; credentials are returned only in the GET /task response to the assignee and omitted from every other task response, event and webhook",
+						"37. Every claim that hands out credentials is appended to the credential_access log (task, assignee, services, resolved references, time, client IP, protocol) in the claim transaction; the log outlives the task and is readable by the task creator and ADMIN_USERS",
+						"38. With JWT_SIGNING_KEYS tokens are signed with the active RS256/EdDSA key and carry its kid; any configured key verifies tokens, so keys are rotated without invalidating issued tokens, and HMAC tokens are rejected unless JWT_ALLOW_HMAC is set",
 					},
 				},
 			},
@@ -425,13 +1111,29 @@ func InfoHandler() gin.HandlerFunc {
 						"2. Rate limiting on /generate-jwt endpoint (5 requests per minute per IP)",
 						"3. User blacklist via BLACKLISTED_USERS environment variable (comma-separated)",
 						"4. Secret key passed through POST body, not URL",
-						"5. JWT signature algorithm verification to protect against algorithm confusion attacks",
+						"5. JWT signature algorithm verification to protect against algorithm confusion attacks: an asymmetric token must match the algorithm of the key named by its kid",
 						"6. All tokens of blacklisted user are automatically blocked",
+						"7. Asymmetric JWT keys (JWT_SIGNING_KEYS): SECRET_KEY only authorizes /generate-jwt and cannot forge tokens; public keys are published at /.well-known/jwks.json",
 					},
 					"environment_variables": map[string]string{
-						"SECRET_KEY":          "Secret key for JWT token signing (required)",
-						"BLACKLISTED_USERS":   "Comma-separated list of blacklisted users (optional)",
-						"CACHE_SYNC_INTERVAL": "Cache synchronization interval with DB (optional, default 10m)",
+						"SECRET_KEY":                      "Secret for /generate-jwt and the HS256 signing key when JWT_SIGNING_KEYS is not set (required)",
+						"JWT_SIGNING_KEYS":                "Comma-separated kid:/path/private.pem RSA or Ed25519 signing keys (optional, enables RS256/EdDSA tokens)",
+						"JWT_SIGNING_KEY_ID":              "kid of the key that signs new tokens (optional, default the first of JWT_SIGNING_KEYS)",
+						"JWT_VERIFICATION_KEYS":           "Comma-separated kid:/path/public.pem keys accepted for verification only, e.g. retired signing keys (optional)",
+						"JWT_ALLOW_HMAC":                  "Keep accepting SECRET_KEY-signed tokens while JWT_SIGNING_KEYS is set (optional, default false)",
+						"BLACKLISTED_USERS":               "Comma-separated list of blacklisted users (optional)",
+						"TRUSTED_PROXIES":                 "Comma-separated IPs or CIDRs of proxies whose X-Forwarded-For is trusted for the client IP (optional, default none - the connection address is used)",
+						"CACHE_SYNC_INTERVAL":             "Cache synchronization interval with DB (optional, default 10m)",
+						"LEASE_TTL":                       "Lease duration for a task taken to work (optional, default 30m)",
+						"LEASE_CHECK_INTERVAL":            "Interval for requeueing tasks with expired lease (optional, default 1m)",
+						"PRIORITY_AGING_INTERVAL":         "Waiting time that raises effective task priority by 1 (optional, default 0 - aging disabled)",
+						"RECURRING_CHECK_INTERVAL":        "Interval for checking due recurring tasks (optional, default 30s)",
+						"LONG_POLL_MAX_WAIT":              "Maximum wait for GET /task?wait=... (optional, default 60s)",
+						"PG_NOTIFY_ENABLED":               "Deliver new task notifications and task tree events across replicas via Postgres LISTEN/NOTIFY (optional, default true)",
+						"WEBHOOK_DELIVERY_INTERVAL":       "Interval for sending due webhook deliveries (optional, default 5s)",
+						"WEBHOOK_ALLOWED_PRIVATE_TARGETS": "Comma-separated host names and CIDR networks webhooks may target even though they are loopback, private or link-local (optional, default none)",
+						"MCP_TOKEN":                       "JWT of the user the stdio MCP server acts for (required only for 'agent-task-manager mcp')",
+						"GRPC_PORT":                       "Port of the gRPC server (optional, default 9090)",
 					},
 				},
 			},
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/signing"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler публикует открытые ключи проверки JWT в формате JWKS, чтобы другие сервисы
+// проверяли токены без секрета подписи. Без JWT_SIGNING_KEYS набор пуст: HMAC ключ не публикуется
+func JWKSHandler(cfg *config.Config) gin.HandlerFunc {
+	jwks := signing.JWKS{Keys: []signing.JWK{}}
+	if cfg.JWTKeys != nil {
+		jwks = cfg.JWTKeys.JWKS()
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwks)
+	}
+}
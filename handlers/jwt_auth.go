@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"agent-task-manager/config"
+	"agent-task-manager/signing"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -36,14 +40,68 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// JwtAuthMiddleware middleware для проверки JWT токена
-func JwtAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
-	// Создаем карту для быстрой проверки blacklist пользователей
-	blacklistedUsers := make(map[string]bool)
-	for _, user := range cfg.BlacklistedUsers {
-		blacklistedUsers[user] = true
+// ErrUserBlocked ошибка проверки токена заблокированного пользователя
+var ErrUserBlocked = errors.New("user has been blocked")
+
+// ParseToken проверяет подпись и срок действия JWT токена и возвращает его claims.
+// Используется как HTTP middleware, так и MCP сервером в режиме stdio
+func ParseToken(cfg *config.Config, tokenString string) (*Claims, error) {
+	// Парсим и валидируем токен
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// HMAC токены принимаются, только если асимметричные ключи не заданы или включен JWT_ALLOW_HMAC
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			if cfg.JWTKeys != nil && !cfg.JWTAllowHMAC {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(cfg.SecretKey), nil
+		}
+		if cfg.JWTKeys == nil {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		// Алгоритм и открытый ключ определяются по kid из заголовка токена
+		return cfg.JWTKeys.VerificationKey(token)
+	}, jwt.WithValidMethods(validSigningMethods(cfg)))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
+	// Проверяем что токен валидный
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+
+	// Проверяем, не заблокирован ли пользователь
+	if slices.Contains(cfg.BlacklistedUsers, claims.UserID) {
+		return nil, ErrUserBlocked
+	}
+
+	return claims, nil
+}
+
+// validSigningMethods возвращает алгоритмы подписи, которые принимаются при проверке токенов
+func validSigningMethods(cfg *config.Config) []string {
+	var methods []string
+	if cfg.JWTKeys == nil || cfg.JWTAllowHMAC {
+		methods = append(methods, jwt.SigningMethodHS256.Alg(), jwt.SigningMethodHS384.Alg(), jwt.SigningMethodHS512.Alg())
+	}
+	if cfg.JWTKeys != nil {
+		methods = append(methods, signing.AlgorithmRS256, signing.AlgorithmEdDSA)
+	}
+	return methods
+}
+
+// SignToken подписывает claims активным ключом JWT_SIGNING_KEYS, а если ключи не заданы - HMAC ключом SECRET_KEY
+func SignToken(cfg *config.Config, claims jwt.Claims) (string, error) {
+	if cfg.JWTKeys != nil {
+		return cfg.JWTKeys.Sign(claims)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.SecretKey))
+}
+
+// JwtAuthMiddleware middleware для проверки JWT токена
+func JwtAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем токен из заголовка Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -65,39 +123,10 @@ func JwtAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Парсим и валидируем токен
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			// Проверяем алгоритм подписи
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(cfg.SecretKey), nil
-		})
-
+		claims, err := ParseToken(cfg, parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid token: " + err.Error(),
-			})
-			c.Abort()
-			return
-		}
-
-		// Проверяем что токен валидный
-		claims, ok := token.Claims.(*Claims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid token claims",
-			})
-			c.Abort()
-			return
-		}
-
-		// Проверяем, не заблокирован ли пользователь
-		if blacklistedUsers[claims.UserID] {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "user has been blocked",
+				"error": err.Error(),
 			})
 			c.Abort()
 			return
@@ -154,7 +183,8 @@ func GenerateJWTHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Проверяем что secret совпадает с секретом из конфига
+		// Проверяем что secret совпадает с секретом из конфига. При заданных JWT_SIGNING_KEYS
+		// SECRET_KEY остается только паролем выдачи токенов и не подписывает их
 		if req.Secret != cfg.SecretKey {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid secret",
@@ -184,11 +214,8 @@ func GenerateJWTHandler(cfg *config.Config) gin.HandlerFunc {
 			},
 		}
 
-		// Создаем токен
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-		// Подписываем токен секретным ключом
-		tokenString, err := token.SignedString([]byte(cfg.SecretKey))
+		// Подписываем токен активным ключом или секретным ключом
+		tokenString, err := SignToken(cfg, claims)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "failed to generate token",
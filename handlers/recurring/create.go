@@ -0,0 +1,90 @@
+package recurring
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateRecurringTaskHandler обработчик для создания периодической задачи
+func CreateRecurringTaskHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		var req CreateRecurringTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		recurringTask := &models.RecurringTask{
+			ID:                  uuid.New(), // Шифротекст credentials привязан к ID, поэтому он задается до сохранения
+			CreatedBy:           userID.(string),
+			Name:                req.Name,
+			CronExpression:      req.CronExpression,
+			Timezone:            req.Timezone,
+			Assignee:            req.Assignee,
+			DescriptionTemplate: req.DescriptionTemplate,
+			Credentials:         json.RawMessage("{}"),
+			OverlapPolicy:       req.OverlapPolicy,
+			Enabled:             true,
+		}
+
+		if recurringTask.Timezone == "" {
+			recurringTask.Timezone = "UTC"
+		}
+		if recurringTask.OverlapPolicy == "" {
+			recurringTask.OverlapPolicy = models.OverlapSkip
+		}
+		if len(req.Credentials) > 0 {
+			recurringTask.Credentials = req.Credentials
+		}
+		if req.Priority != nil {
+			recurringTask.Priority = *req.Priority
+		}
+		if req.Enabled != nil {
+			recurringTask.Enabled = *req.Enabled
+		}
+
+		if err := validateRecurringTask(cfg, recurringTask); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid recurring task: " + err.Error(),
+			})
+			return
+		}
+
+		if err := service.SealRecurringCredentials(cfg.CredentialsKeyring, recurringTask, recurringTask.Credentials); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to encrypt credentials: " + err.Error(),
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		// Select("*") нужен, чтобы GORM сохранил Enabled = false, а не подставил значение по умолчанию
+		if err := db.Select("*").Create(recurringTask).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to create recurring task: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, recurringTask)
+	}
+}
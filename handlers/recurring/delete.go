@@ -0,0 +1,43 @@
+package recurring
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteRecurringTaskHandler обработчик для удаления периодической задачи.
+// Уже созданные по расписанию задачи не затрагиваются
+func DeleteRecurringTaskHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		var recurringTask models.RecurringTask
+		if !findOwnedRecurringTask(c, db, userID.(string), &recurringTask) {
+			return
+		}
+
+		if err := db.Delete(&recurringTask).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to delete recurring task: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":      recurringTask.ID,
+			"deleted": true,
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package recurring
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// findOwnedRecurringTask загружает периодическую задачу по ID из пути и проверяет, что её создал текущий пользователь.
+// При ошибке пишет ответ и возвращает false
+func findOwnedRecurringTask(c *gin.Context, db *gorm.DB, userID string, recurringTask *models.RecurringTask) bool {
+	recurringTaskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid recurring task id format",
+		})
+		return false
+	}
+
+	if err := db.First(recurringTask, "id = ?", recurringTaskID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "recurring task not found",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to find recurring task: " + err.Error(),
+		})
+		return false
+	}
+
+	if recurringTask.CreatedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "access denied: you are not the creator of this recurring task",
+		})
+		return false
+	}
+
+	return true
+}
+
+// GetRecurringTaskHandler обработчик для получения периодической задачи
+func GetRecurringTaskHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		var recurringTask models.RecurringTask
+		if !findOwnedRecurringTask(c, database.GetDB(), userID.(string), &recurringTask) {
+			return
+		}
+
+		c.JSON(http.StatusOK, recurringTask)
+	}
+}
@@ -0,0 +1,37 @@
+package recurring
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRecurringTasksHandler обработчик для получения списка периодических задач текущего пользователя
+func ListRecurringTasksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		var recurringTasks []models.RecurringTask
+		if err := db.Where("created_by = ?", userID.(string)).
+			Order("created_at").
+			Find(&recurringTasks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get recurring tasks: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, recurringTasks)
+	}
+}
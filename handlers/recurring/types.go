@@ -0,0 +1,32 @@
+package recurring
+
+import (
+	"agent-task-manager/models"
+	"encoding/json"
+)
+
+// CreateRecurringTaskRequest структура для запроса создания периодической задачи
+type CreateRecurringTaskRequest struct {
+	Name                string               `json:"name" binding:"required"`
+	CronExpression      string               `json:"cron_expression" binding:"required"`
+	Timezone            string               `json:"timezone"`
+	Assignee            string               `json:"assignee"`
+	DescriptionTemplate string               `json:"description_template" binding:"required"`
+	Credentials         json.RawMessage      `json:"credentials"`
+	Priority            *int                 `json:"priority"`
+	OverlapPolicy       models.OverlapPolicy `json:"overlap_policy"`
+	Enabled             *bool                `json:"enabled"`
+}
+
+// UpdateRecurringTaskRequest структура для запроса частичного обновления периодической задачи
+type UpdateRecurringTaskRequest struct {
+	Name                *string               `json:"name"`
+	CronExpression      *string               `json:"cron_expression"`
+	Timezone            *string               `json:"timezone"`
+	Assignee            *string               `json:"assignee"`
+	DescriptionTemplate *string               `json:"description_template"`
+	Credentials         json.RawMessage       `json:"credentials"`
+	Priority            *int                  `json:"priority"`
+	OverlapPolicy       *models.OverlapPolicy `json:"overlap_policy"`
+	Enabled             *bool                 `json:"enabled"`
+}
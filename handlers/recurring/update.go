@@ -0,0 +1,108 @@
+package recurring
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errResponseWritten прерывает транзакцию обновления, когда ответ с ошибкой уже отправлен клиенту
+var errResponseWritten = errors.New("response already written")
+
+// UpdateRecurringTaskHandler обработчик для частичного обновления периодической задачи
+func UpdateRecurringTaskHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		var req UpdateRecurringTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		// Читаем определение под блокировкой до сохранения: планировщик обновляет NextRunAt, RunCount и PendingRuns
+		// той же строки, и запись без блокировки откатила бы запуск, запланированный между чтением и сохранением
+		var recurringTask models.RecurringTask
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if !findOwnedRecurringTask(c, tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID.(string), &recurringTask) {
+				return errResponseWritten
+			}
+
+			// Применяем только переданные поля
+			if req.Name != nil {
+				recurringTask.Name = *req.Name
+			}
+			if req.CronExpression != nil {
+				recurringTask.CronExpression = *req.CronExpression
+			}
+			if req.Timezone != nil {
+				recurringTask.Timezone = *req.Timezone
+			}
+			if req.Assignee != nil {
+				recurringTask.Assignee = *req.Assignee
+			}
+			if req.DescriptionTemplate != nil {
+				recurringTask.DescriptionTemplate = *req.DescriptionTemplate
+			}
+			if len(req.Credentials) > 0 {
+				recurringTask.Credentials = req.Credentials
+			}
+			if req.Priority != nil {
+				recurringTask.Priority = *req.Priority
+			}
+			if req.OverlapPolicy != nil {
+				recurringTask.OverlapPolicy = *req.OverlapPolicy
+			}
+			if req.Enabled != nil {
+				recurringTask.Enabled = *req.Enabled
+			}
+
+			// Валидация пересчитывает время следующего запуска по новому расписанию
+			if err := validateRecurringTask(cfg, &recurringTask); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "invalid recurring task: " + err.Error(),
+				})
+				return errResponseWritten
+			}
+
+			// Новые credentials заменяют прежние, в том числе зашифрованные
+			if len(req.Credentials) > 0 {
+				if err := service.SealRecurringCredentials(cfg.CredentialsKeyring, &recurringTask, recurringTask.Credentials); err != nil {
+					return fmt.Errorf("failed to encrypt credentials: %w", err)
+				}
+			}
+
+			return tx.Save(&recurringTask).Error
+		})
+		if errors.Is(err, errResponseWritten) {
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to update recurring task: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, recurringTask)
+	}
+}
@@ -0,0 +1,56 @@
+package recurring
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"errors"
+	"time"
+)
+
+// validateRecurringTask проверяет определение периодической задачи и вычисляет время следующего запуска
+func validateRecurringTask(cfg *config.Config, recurringTask *models.RecurringTask) error {
+	if recurringTask.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if recurringTask.DescriptionTemplate == "" {
+		return errors.New("description_template cannot be empty")
+	}
+
+	if recurringTask.OverlapPolicy != models.OverlapSkip && recurringTask.OverlapPolicy != models.OverlapQueue {
+		return errors.New("overlap_policy must be one of: skip, queue")
+	}
+
+	if err := service.ValidatePriority(&recurringTask.Priority); err != nil {
+		return err
+	}
+
+	if len(recurringTask.Credentials) > 0 {
+		if err := service.ValidateCredentialsPayload(recurringTask.Credentials); err != nil {
+			return err
+		}
+		// Созданные задачи получают credentials как есть, поэтому ссылки на секреты проверяются по тем же правилам
+		// для владельца определения, от имени которого создаются задачи
+		if err := service.CheckCredentialReferences(cfg, recurringTask.CreatedBy, recurringTask.Credentials); err != nil {
+			return errors.New("invalid credentials: " + err.Error())
+		}
+	}
+
+	// Проверяем шаблон на тестовых данных
+	if _, err := recurringTask.RenderDescription(models.RecurringTaskRun{
+		Name:        recurringTask.Name,
+		ScheduledAt: time.Now(),
+		RunNumber:   1,
+	}); err != nil {
+		return err
+	}
+
+	// Проверяем cron-выражение и часовой пояс, вычисляя следующий запуск
+	nextRunAt, err := recurringTask.NextRun(time.Now())
+	if err != nil {
+		return err
+	}
+	recurringTask.NextRunAt = &nextRunAt
+
+	return nil
+}
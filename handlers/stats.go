@@ -111,17 +111,21 @@ func StatsHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Считаем зафейленные задачи за период
-		// Поскольку у нас нет поля updated_at, считаем failed задачи по времени создания
-		// Это означает, что мы считаем задачи, которые были созданы и зафейлены в указанный период
+		// Считаем задачи, зафейленные за период, по журналу переходов:
+		// учитывается время неудачи, а не время создания задачи
 		var failedCount int64
-		failedQuery := db.Model(&models.Task{}).
-			Where("created_by = ? AND status = ?", userID.(string), models.StatusFailed)
+		failedQuery := db.Model(&models.TaskEvent{}).
+			Joins("JOIN tasks ON tasks.id = task_events.task_id").
+			Where("tasks.created_by = ? AND task_events.to_status IN ?", userID.(string), []models.TaskStatus{
+				models.StatusFailed,
+				models.StatusDeadLettered,
+			}).
+			Distinct("task_events.task_id")
 
 		if period == "yesterday" {
-			failedQuery = failedQuery.Where("created_at >= ? AND created_at < ?", startTime, now)
+			failedQuery = failedQuery.Where("task_events.created_at >= ? AND task_events.created_at < ?", startTime, now)
 		} else if period != "all-time" {
-			failedQuery = failedQuery.Where("created_at >= ?", startTime)
+			failedQuery = failedQuery.Where("task_events.created_at >= ?", startTime)
 		}
 
 		if err := failedQuery.Count(&failedCount).Error; err != nil {
@@ -131,6 +135,30 @@ func StatsHandler() gin.HandlerFunc {
 			return
 		}
 
+		// У задач, зафейленных до появления журнала переходов, событий нет:
+		// их считаем по текущему статусу и времени создания, как до журнала
+		var failedWithoutHistoryCount int64
+		failedWithoutHistoryQuery := db.Model(&models.Task{}).
+			Where("created_by = ? AND status IN ?", userID.(string), []models.TaskStatus{
+				models.StatusFailed,
+				models.StatusDeadLettered,
+			}).
+			Where("NOT EXISTS (SELECT 1 FROM task_events WHERE task_events.task_id = tasks.id)")
+
+		if period == "yesterday" {
+			failedWithoutHistoryQuery = failedWithoutHistoryQuery.Where("created_at >= ? AND created_at < ?", startTime, now)
+		} else if period != "all-time" {
+			failedWithoutHistoryQuery = failedWithoutHistoryQuery.Where("created_at >= ?", startTime)
+		}
+
+		if err := failedWithoutHistoryQuery.Count(&failedWithoutHistoryCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to count failed tasks: " + err.Error(),
+			})
+			return
+		}
+		failedCount += failedWithoutHistoryCount
+
 		// Формируем ответ
 		response := StatsResponse{
 			Period:       period,
@@ -0,0 +1,154 @@
+package tasks
+
+import (
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TaskCredentialAccessResponse структура для ответа с журналом выдачи credentials задачи
+type TaskCredentialAccessResponse struct {
+	TaskID   uuid.UUID                 `json:"task_id"`
+	Accesses []models.CredentialAccess `json:"accesses"`
+}
+
+// parseLimit разбирает параметр limit: количество записей в ответе, 0 - по умолчанию
+func parseLimit(limitStr string) (int, error) {
+	if limitStr == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive number")
+	}
+	return limit, nil
+}
+
+// parseTime разбирает необязательный параметр времени в формате RFC 3339
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New(name + " must be a time in RFC 3339 format")
+	}
+	return &parsed, nil
+}
+
+// GetTaskCredentialAccessHandler обработчик для получения журнала выдачи credentials задачи.
+// Доступен создателю задачи и администраторам, в том числе после удаления задачи
+func GetTaskCredentialAccessHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		// Получаем ID задачи из параметра пути
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid task id format",
+			})
+			return
+		}
+
+		limit, err := parseLimit(c.Query("limit"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid limit parameter: " + err.Error(),
+			})
+			return
+		}
+
+		accesses, err := svc.TaskCredentialAccess(c.Request.Context(), userID.(string), taskID, limit)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		if accesses == nil {
+			accesses = []models.CredentialAccess{}
+		}
+		c.JSON(http.StatusOK, TaskCredentialAccessResponse{
+			TaskID:   taskID,
+			Accesses: accesses,
+		})
+	}
+}
+
+// ListCredentialAccessHandler обработчик для поиска по журналу выдачи credentials.
+// Администраторы ищут по всем задачам, остальные пользователи - по задачам, которые они создали
+func ListCredentialAccessHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		filter := service.CredentialAccessFilter{
+			Assignee:  c.Query("assignee"),
+			Service:   c.Query("service"),
+			Reference: c.Query("reference"),
+		}
+
+		if taskIDStr := c.Query("task_id"); taskIDStr != "" {
+			taskID, err := uuid.Parse(taskIDStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "invalid task_id format",
+				})
+				return
+			}
+			filter.TaskID = &taskID
+		}
+
+		var err error
+		if filter.Since, err = parseTime("since", c.Query("since")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid since parameter: " + err.Error(),
+			})
+			return
+		}
+		if filter.Until, err = parseTime("until", c.Query("until")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid until parameter: " + err.Error(),
+			})
+			return
+		}
+		if filter.Limit, err = parseLimit(c.Query("limit")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid limit parameter: " + err.Error(),
+			})
+			return
+		}
+
+		accesses, err := svc.CredentialAccessLog(c.Request.Context(), userID.(string), filter)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		if accesses == nil {
+			accesses = []models.CredentialAccess{}
+		}
+		c.JSON(http.StatusOK, accesses)
+	}
+}
@@ -0,0 +1,38 @@
+package tasks
+
+import (
+	"agent-task-manager/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeadLetterTasksHandler обработчик для получения задач в dead-letter,
+// созданных текущим пользователем или назначенных на него
+func GetDeadLetterTasksHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		tasks, err := svc.ListDeadLettered(c.Request.Context(), userID.(string))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		now := time.Now()
+		views := make([]TaskView, len(tasks))
+		for i, task := range tasks {
+			views[i] = newTaskView(task, now)
+		}
+
+		c.JSON(http.StatusOK, views)
+	}
+}
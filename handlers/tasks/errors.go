@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"agent-task-manager/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatuses HTTP статусы видов ошибок сервиса задач
+var errorStatuses = map[service.ErrorKind]int{
+	service.KindInvalid:   http.StatusBadRequest,
+	service.KindForbidden: http.StatusForbidden,
+	service.KindNotFound:  http.StatusNotFound,
+	service.KindConflict:  http.StatusConflict,
+}
+
+// respondError пишет ошибку операции в ответ. Ошибки без вида считаются внутренними
+func respondError(c *gin.Context, err error) {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	status, ok := errorStatuses[serviceErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	response := gin.H{"error": serviceErr.Message}
+	for key, value := range serviceErr.Fields {
+		response[key] = value
+	}
+	c.JSON(status, response)
+}
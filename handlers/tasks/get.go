@@ -1,16 +1,55 @@
 package tasks
 
 import (
-	"agent-task-manager/database"
-	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-// GetTaskHandler обработчик для получения задачи в работу
-func GetTaskHandler() gin.HandlerFunc {
+// parseWait разбирает параметр wait: число секунд или длительность в формате Go ("30s", "2m")
+func parseWait(waitStr string) (time.Duration, error) {
+	if waitStr == "" {
+		return 0, nil
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(waitStr); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else {
+		parsed, err := time.ParseDuration(waitStr)
+		if err != nil {
+			return 0, errors.New("wait must be a number of seconds or a duration like 30s")
+		}
+		wait = parsed
+	}
+
+	if wait < 0 {
+		return 0, errors.New("wait cannot be negative")
+	}
+	return wait, nil
+}
+
+// parseChildrenDepth разбирает параметр children_depth: количество уровней подзадач в ответе
+func parseChildrenDepth(depthStr string) (int, error) {
+	if depthStr == "" {
+		return 0, nil
+	}
+
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil {
+		return 0, errors.New("children_depth must be a number")
+	}
+	return depth, nil
+}
+
+// GetTaskHandler обработчик для получения задачи в работу.
+// С параметром wait запрос удерживается, пока у исполнителя не появится задача или не истечет таймаут.
+// С параметром children_depth ответ содержит подзадачи всех статусов на указанную глубину
+func GetTaskHandler(svc *service.TaskService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем user_id из контекста (установлен в JWT middleware)
 		userID, exists := c.Get("user_id")
@@ -21,74 +60,37 @@ func GetTaskHandler() gin.HandlerFunc {
 			return
 		}
 
-		db := database.GetDB()
-
-		// Начинаем транзакцию для атомарного обновления
-		tx := db.Begin()
-		if tx.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to start transaction: " + tx.Error.Error(),
-			})
-			return
-		}
-
-		var task models.Task
-
-		// Ищем задачу где assignee == userID и status == submitted
-		// Используем FOR UPDATE для блокировки строки
-		err := tx.Set("gorm:query_option", "FOR UPDATE").
-			Where("assignee = ? AND status = ?", userID.(string), models.StatusSubmitted).
-			First(&task).Error
-
+		wait, err := parseWait(c.Query("wait"))
 		if err != nil {
-			tx.Rollback()
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{
-					"error": "no tasks available for assignment",
-				})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to find task: " + err.Error(),
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid wait parameter: " + err.Error(),
 			})
 			return
 		}
 
-		// Меняем статус на working
-		task.Status = models.StatusWorking
-		if err := tx.Save(&task).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to update task status: " + err.Error(),
+		childrenDepth, err := parseChildrenDepth(c.Query("children_depth"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid children_depth parameter: " + err.Error(),
 			})
 			return
 		}
 
-		// Загружаем завершенные подзадачи первого уровня
-		var completedSubtasks []models.Task
-		if err := tx.Where("parent_task_id = ? AND status = ?", task.ID, models.StatusCompleted).
-			Find(&completedSubtasks).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to load completed subtasks: " + err.Error(),
-			})
-			return
+		opts := service.ClaimOptions{
+			ChildrenDepth: childrenDepth,
+			ClientIP:      c.ClientIP(),
+			Protocol:      "http",
 		}
-
-		// Коммитим транзакцию
-		if err := tx.Commit().Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to commit transaction: " + err.Error(),
-			})
+		response, err := svc.WaitForTask(c.Request.Context(), userID.(string), wait, opts)
+		if err != nil {
+			// Клиент отключился, не дождавшись задачи
+			if c.Request.Context().Err() != nil {
+				return
+			}
+			respondError(c, err)
 			return
 		}
 
-		// Формируем ответ с подзадачами
-		response := TaskWithSubtasks{
-			Task:              task,
-			CompletedSubtasks: completedSubtasks,
-		}
-
 		c.JSON(http.StatusOK, response)
 	}
 }
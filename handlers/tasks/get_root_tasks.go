@@ -1,32 +1,32 @@
 package tasks
 
 import (
-	"agent-task-manager/database"
 	"agent-task-manager/models"
+	"agent-task-manager/service"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-// TaskWithoutCredentials представляет задачу без поля Credentials
-type TaskWithoutCredentials struct {
-	ID           uuid.UUID         `json:"id"`
-	CreatedAt    time.Time         `json:"created_at"`
-	DeleteAt     *time.Time        `json:"delete_at,omitempty"`
-	CreatedBy    string            `json:"created_by"`
-	Assignee     string            `json:"assignee"`
-	Description  string            `json:"description"`
-	RootTaskID   *uuid.UUID        `json:"root_task_id,omitempty"`
-	ParentTaskID *uuid.UUID        `json:"parent_task_id,omitempty"`
-	Result       string            `json:"result"`
-	Status       models.TaskStatus `json:"status"`
+// TaskView задача в ответах со списками задач: задача без credentials (models.Task.Redacted)
+// и признак отложенной задачи
+type TaskView struct {
+	models.Task
+	Scheduled bool `json:"scheduled"` // Задача в статусе submitted, но её время RunAt или NotBefore еще не наступило
+}
+
+// newTaskView создает представление задачи для ответа
+func newTaskView(task models.Task, now time.Time) TaskView {
+	return TaskView{
+		Task:      task.Redacted(),
+		Scheduled: task.IsScheduled(now),
+	}
 }
 
 // GetRootTasksHandler обработчик для получения всех задач по root_task_id
-func GetRootTasksHandler() gin.HandlerFunc {
+func GetRootTasksHandler(svc *service.TaskService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем user_id из контекста (установлен в JWT middleware)
 		userID, exists := c.Get("user_id")
@@ -47,57 +47,19 @@ func GetRootTasksHandler() gin.HandlerFunc {
 			return
 		}
 
-		db := database.GetDB()
-
-		// Сначала проверяем, что root задача существует и создана текущим пользователем
-		var rootTask models.Task
-		if err := db.First(&rootTask, "id = ?", rootTaskID).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{
-					"error": "root task not found",
-				})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to find root task: " + err.Error(),
-			})
-			return
-		}
-
-		// Проверяем, что текущий пользователь является создателем root задачи
-		if rootTask.CreatedBy != userID.(string) {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "access denied: you are not the creator of this root task",
-			})
-			return
-		}
-
-		// Получаем все задачи с данным root_task_id
-		var tasks []models.Task
-		if err := db.Where("root_task_id = ?", rootTaskID).Find(&tasks).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to get tasks: " + err.Error(),
-			})
+		// Проверяем, что root задача существует и создана текущим пользователем, и получаем задачи дерева
+		tasks, err := svc.GetTree(c.Request.Context(), userID.(string), rootTaskID)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		// Конвертируем задачи в структуры без Credentials
-		tasksWithoutCreds := make([]TaskWithoutCredentials, len(tasks))
+		now := time.Now()
+		views := make([]TaskView, len(tasks))
 		for i, task := range tasks {
-			tasksWithoutCreds[i] = TaskWithoutCredentials{
-				ID:           task.ID,
-				CreatedAt:    task.CreatedAt,
-				DeleteAt:     task.DeleteAt,
-				CreatedBy:    task.CreatedBy,
-				Assignee:     task.Assignee,
-				Description:  task.Description,
-				RootTaskID:   task.RootTaskID,
-				ParentTaskID: task.ParentTaskID,
-				Result:       task.Result,
-				Status:       task.Status,
-			}
+			views[i] = newTaskView(task, now)
 		}
 
-		c.JSON(http.StatusOK, tasksWithoutCreds)
+		c.JSON(http.StatusOK, views)
 	}
 }
@@ -1,15 +1,15 @@
 package tasks
 
 import (
-	"agent-task-manager/database"
-	"agent-task-manager/models"
+	"agent-task-manager/service"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetUserRootTasksHandler обработчик для получения списка корневых задач пользователя
-func GetUserRootTasksHandler() gin.HandlerFunc {
+func GetUserRootTasksHandler(svc *service.TaskService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем user_id из контекста (установлен в JWT middleware)
 		userID, exists := c.Get("user_id")
@@ -20,24 +20,16 @@ func GetUserRootTasksHandler() gin.HandlerFunc {
 			return
 		}
 
-		db := database.GetDB()
-
-		var tasks []models.Task
-
-		// Ищем задачи где created_by == userID и id == root_task_id (корневые задачи)
 		// Корневая задача - это задача где ID равен RootTaskID
-		err := db.Where("created_by = ? AND id = root_task_id", userID.(string)).
-			Find(&tasks).Error
-
+		tasks, err := svc.ListRootTasks(c.Request.Context(), userID.(string))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "failed to fetch root tasks: " + err.Error(),
-			})
+			respondError(c, err)
 			return
 		}
 
 		// Преобразуем задачи в формат RootTaskSummary
 		summaries := make([]RootTaskSummary, len(tasks))
+		now := time.Now()
 		for i, task := range tasks {
 			summaries[i] = RootTaskSummary{
 				RootTaskID:  task.ID,
@@ -46,6 +38,9 @@ func GetUserRootTasksHandler() gin.HandlerFunc {
 				Assignee:    task.Assignee,
 				Description: task.Description,
 				Status:      task.Status,
+				Priority:    task.Priority,
+				RunAt:       task.RunAt,
+				Scheduled:   task.IsScheduled(now),
 			}
 		}
 
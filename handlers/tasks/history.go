@@ -0,0 +1,54 @@
+package tasks
+
+import (
+	"agent-task-manager/models"
+	"agent-task-manager/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TaskHistoryResponse структура для ответа с историей задачи
+type TaskHistoryResponse struct {
+	TaskID uuid.UUID          `json:"task_id"`
+	Status models.TaskStatus  `json:"status"`
+	Events []models.TaskEvent `json:"events"`
+}
+
+// GetTaskHistoryHandler обработчик для получения журнала переходов задачи.
+// Доступен создателю и исполнителю задачи, а также создателю корневой задачи дерева
+func GetTaskHistoryHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		// Получаем ID задачи из параметра пути
+		taskIDStr := c.Param("id")
+		taskID, err := uuid.Parse(taskIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid task id format",
+			})
+			return
+		}
+
+		task, taskEvents, err := svc.History(c.Request.Context(), userID.(string), taskID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, TaskHistoryResponse{
+			TaskID: task.ID,
+			Status: task.Status,
+			Events: taskEvents,
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package tasks
+
+import (
+	"agent-task-manager/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ProvideInputHandler обработчик для ответа создателя на вопрос исполнителя.
+// Задача возвращается в submitted с приложенным ответом
+func ProvideInputHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		// Получаем ID задачи из параметра пути
+		taskIDStr := c.Param("id")
+		taskID, err := uuid.Parse(taskIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid task id format",
+			})
+			return
+		}
+
+		var req service.ProvideInputRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		task, err := svc.ProvideInput(c.Request.Context(), userID.(string), taskID, req.Answer)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
@@ -0,0 +1,143 @@
+package tasks
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sseKeepAliveInterval интервал отправки комментариев, не дающих прокси закрыть простаивающее соединение
+const sseKeepAliveInterval = 15 * time.Second
+
+// writeSSEEvent записывает событие в формате Server-Sent Events
+func writeSSEEvent(c *gin.Context, id int64, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id > 0 {
+		if _, err := fmt.Fprintf(c.Writer, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", eventType, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// GetRootTaskEventsHandler обработчик потока Server-Sent Events с изменениями задач дерева корневой задачи.
+// Поддерживает возобновление по заголовку Last-Event-ID или параметру last_event_id
+func GetRootTaskEventsHandler(svc *service.TaskService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		// Получаем ID root задачи из параметра пути
+		rootTaskIDStr := c.Param("id")
+		rootTaskID, err := uuid.Parse(rootTaskIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid root task id format",
+			})
+			return
+		}
+
+		// Получаем ID последнего полученного клиентом события
+		var lastEventID int64
+		lastEventIDStr := c.GetHeader("Last-Event-ID")
+		if lastEventIDStr == "" {
+			lastEventIDStr = c.Query("last_event_id")
+		}
+		if lastEventIDStr != "" {
+			lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+			if err != nil || lastEventID < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "invalid last event id format",
+				})
+				return
+			}
+		}
+
+		// Сначала проверяем, что root задача существует и создана текущим пользователем
+		if _, err := svc.GetRootTask(c.Request.Context(), userID.(string), rootTaskID); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		// Пропущенные события загружаются из журнала task_events
+		ctx := c.Request.Context()
+		replay, complete, stream, unsubscribe, err := events.Subscribe(rootTaskID, lastEventID, func(afterID int64, limit int) ([]events.Event, error) {
+			return svc.RootTaskEventsSince(ctx, rootTaskID, afterID, limit)
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		defer unsubscribe()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		// Интервал переподключения клиента
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		c.Writer.Flush()
+
+		// Часть пропущенных событий недоступна - клиенту нужно заново загрузить дерево через GET /root-task/:id/tasks
+		if !complete {
+			if err := writeSSEEvent(c, 0, "resync", gin.H{
+				"root_task_id": rootTaskID,
+				"reason":       "some events since last_event_id are no longer available, reload the task tree",
+			}); err != nil {
+				return
+			}
+		}
+
+		for _, event := range replay {
+			if err := writeSSEEvent(c, event.ID, string(event.Type), event); err != nil {
+				return
+			}
+		}
+
+		keepAlive := time.NewTicker(sseKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case event, ok := <-stream:
+				if !ok {
+					// Подписка закрыта из-за переполнения или переподключения к Postgres - клиент переподключится с Last-Event-ID
+					return
+				}
+				if err := writeSSEEvent(c, event.ID, string(event.Type), event); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}
+}
@@ -13,11 +13,13 @@ func GetUsersWithTasksHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Получаем список пользователей из кэша
 		allUsers := cache.GetUsersWithTasks()
+		allScheduledUsers := cache.GetScheduledUsers()
 
 		// Получаем параметр filter из query string
 		filterParam := c.Query("filter")
 
 		var filteredUsers []string = make([]string, 0)
+		var filteredScheduledUsers []cache.ScheduledUser = make([]cache.ScheduledUser, 0)
 
 		if filterParam != "" {
 			// Разбиваем filter по запятым и очищаем от пробелов
@@ -35,15 +37,23 @@ func GetUsersWithTasksHandler() gin.HandlerFunc {
 					filteredUsers = append(filteredUsers, user)
 				}
 			}
+			for _, user := range allScheduledUsers {
+				if filterUsers[user.UserID] {
+					filteredScheduledUsers = append(filteredScheduledUsers, user)
+				}
+			}
 		} else {
 			// Если фильтр не задан, возвращаем всех пользователей
 			filteredUsers = allUsers
+			filteredScheduledUsers = allScheduledUsers
 		}
 
-		// Возвращаем список пользователей
+		// Возвращаем список пользователей.
+		// Пользователи, у которых есть только отложенные задачи, возвращаются отдельно
 		c.JSON(http.StatusOK, gin.H{
-			"users": filteredUsers,
-			"count": len(filteredUsers),
+			"users":           filteredUsers,
+			"count":           len(filteredUsers),
+			"scheduled_users": filteredScheduledUsers,
 		})
 	}
 }
@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"agent-task-manager/webhooks"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhookHandler обработчик для создания подписки на вебхуки
+func CreateWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		var req CreateWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request body: " + err.Error(),
+			})
+			return
+		}
+
+		if err := validateWebhookRequest(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid webhook: " + err.Error(),
+			})
+			return
+		}
+
+		// Сервер отправляет вебхуки сам, поэтому URL не может указывать на его внутреннюю сеть
+		if err := webhooks.CheckURL(c.Request.Context(), req.URL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid webhook: " + err.Error(),
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		if !authorizeFilter(c, db, userID.(string), &req) {
+			return
+		}
+
+		secret := req.Secret
+		if secret == "" {
+			generated, err := webhooks.GenerateSecret()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to generate secret: " + err.Error(),
+				})
+				return
+			}
+			secret = generated
+		}
+
+		subscription := &models.WebhookSubscription{
+			CreatedBy:   userID.(string),
+			URL:         req.URL,
+			Secret:      secret,
+			FilterType:  req.FilterType,
+			FilterValue: req.FilterValue,
+			EventTypes:  models.StringList(req.EventTypes),
+			Active:      true,
+		}
+
+		if err := db.Create(subscription).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to create webhook: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusCreated, CreateWebhookResponse{
+			WebhookSubscription: *subscription,
+			Secret:              secret,
+		})
+	}
+}
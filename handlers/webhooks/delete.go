@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteWebhookHandler обработчик для удаления подписки вместе с её очередью доставки
+func DeleteWebhookHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		var subscription models.WebhookSubscription
+		if !findOwnedWebhook(c, db, userID.(string), &subscription) {
+			return
+		}
+
+		if err := db.Delete(&subscription).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to delete webhook: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":      subscription.ID,
+			"deleted": true,
+		})
+	}
+}
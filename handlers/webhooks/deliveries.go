@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDeliveriesLimit максимальное количество доставок в ответе
+const maxDeliveriesLimit = 100
+
+// GetWebhookDeliveriesHandler обработчик для получения последних доставок подписки.
+// Поддерживает фильтр по статусу через query параметр status
+func GetWebhookDeliveriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		var subscription models.WebhookSubscription
+		if !findOwnedWebhook(c, db, userID.(string), &subscription) {
+			return
+		}
+
+		query := db.Where("subscription_id = ?", subscription.ID)
+		if status := c.Query("status"); status != "" {
+			switch models.WebhookDeliveryStatus(status) {
+			case models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed:
+				query = query.Where("status = ?", status)
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "status must be one of: pending, delivered, failed",
+				})
+				return
+			}
+		}
+
+		var deliveries []models.WebhookDelivery
+		if err := query.Order("created_at DESC").
+			Limit(maxDeliveriesLimit).
+			Find(&deliveries).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get webhook deliveries: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListWebhooksHandler обработчик для получения списка подписок текущего пользователя
+func ListWebhooksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		db := database.GetDB()
+
+		var subscriptions []models.WebhookSubscription
+		if err := db.Where("created_by = ?", userID.(string)).
+			Order("created_at").
+			Find(&subscriptions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get webhooks: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, subscriptions)
+	}
+}
@@ -0,0 +1,18 @@
+package webhooks
+
+import "agent-task-manager/models"
+
+// CreateWebhookRequest структура для запроса создания подписки на вебхуки
+type CreateWebhookRequest struct {
+	URL         string                   `json:"url" binding:"required"`
+	FilterType  models.WebhookFilterType `json:"filter_type" binding:"required"`
+	FilterValue string                   `json:"filter_value"` // Для creator/assignee по умолчанию - текущий пользователь
+	EventTypes  []string                 `json:"event_types"`  // Пустой список - все события
+	Secret      string                   `json:"secret"`       // Если не указан, генерируется сервером
+}
+
+// CreateWebhookResponse ответ на создание подписки. Secret возвращается только здесь
+type CreateWebhookResponse struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
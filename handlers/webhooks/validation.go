@@ -0,0 +1,133 @@
+package webhooks
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// minSecretLength минимальная длина секрета, заданного пользователем
+const minSecretLength = 16
+
+// validateWebhookRequest проверяет URL, типы событий и секрет подписки
+func validateWebhookRequest(req *CreateWebhookRequest) error {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !events.IsValidType(eventType) {
+			return fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+
+	if req.Secret != "" && len(req.Secret) < minSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minSecretLength)
+	}
+
+	return nil
+}
+
+// authorizeFilter проверяет, что пользователь может подписаться на задачи по указанному фильтру.
+// Подписываться можно только на свои задачи (созданные или назначенные) и на свои корневые задачи.
+// При ошибке пишет ответ и возвращает false
+func authorizeFilter(c *gin.Context, db *gorm.DB, userID string, req *CreateWebhookRequest) bool {
+	switch req.FilterType {
+	case models.WebhookFilterCreator, models.WebhookFilterAssignee:
+		if req.FilterValue == "" {
+			req.FilterValue = userID
+		}
+		if req.FilterValue != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "access denied: you can subscribe only to your own tasks",
+			})
+			return false
+		}
+	case models.WebhookFilterRootTask:
+		rootTaskID, err := uuid.Parse(req.FilterValue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "filter_value must be a root task id",
+			})
+			return false
+		}
+
+		var rootTask models.Task
+		if err := db.First(&rootTask, "id = ?", rootTaskID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "root task not found",
+				})
+				return false
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to find root task: " + err.Error(),
+			})
+			return false
+		}
+
+		if rootTask.ParentTaskID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "task is not a root task",
+			})
+			return false
+		}
+
+		if rootTask.CreatedBy != userID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "access denied: you are not the creator of this root task",
+			})
+			return false
+		}
+		req.FilterValue = rootTaskID.String()
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "filter_type must be one of: creator, assignee, root_task",
+		})
+		return false
+	}
+
+	return true
+}
+
+// findOwnedWebhook загружает подписку по ID из пути и проверяет, что её создал текущий пользователь.
+// При ошибке пишет ответ и возвращает false
+func findOwnedWebhook(c *gin.Context, db *gorm.DB, userID string, subscription *models.WebhookSubscription) bool {
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid webhook id format",
+		})
+		return false
+	}
+
+	if err := db.First(subscription, "id = ?", subscriptionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "webhook not found",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to find webhook: " + err.Error(),
+		})
+		return false
+	}
+
+	if subscription.CreatedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "access denied: you are not the creator of this webhook",
+		})
+		return false
+	}
+
+	return true
+}
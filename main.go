@@ -2,33 +2,79 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"agent-task-manager/cache"
 	"agent-task-manager/config"
 	"agent-task-manager/database"
+	"agent-task-manager/events"
+	"agent-task-manager/grpcapi"
 	"agent-task-manager/handlers"
+	"agent-task-manager/handlers/a2a"
+	"agent-task-manager/handlers/recurring"
 	"agent-task-manager/handlers/tasks"
+	handlerswebhooks "agent-task-manager/handlers/webhooks"
+	"agent-task-manager/mcp"
+	"agent-task-manager/notify"
 	"agent-task-manager/scheduler"
+	"agent-task-manager/secrets"
+	"agent-task-manager/service"
+	"agent-task-manager/signing"
+	"agent-task-manager/webhooks"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/logger"
 )
 
 func main() {
-	// Создаем новый роутер Gin без дефолтного middleware
-	router := gin.New()
+	// Генерация ключа подписи JWT для JWT_SIGNING_KEYS не требует конфигурации: agent-task-manager generate-jwt-key > key.pem
+	if len(os.Args) > 1 && os.Args[1] == "generate-jwt-key" {
+		runGenerateJWTKey(os.Args[2:])
+		return
+	}
 
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	// Режим MCP сервера через stdio: agent-task-manager mcp
+	if len(os.Args) > 1 && os.Args[1] == "mcp" {
+		runMCPStdio(cfg)
+		return
+	}
+
+	// Перешифрование credentials задач активным ключом после ротации: agent-task-manager reencrypt-credentials
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt-credentials" {
+		runReencryptCredentials(cfg, os.Args[2:])
+		return
+	}
+
+	// Управление локальным хранилищем секретов (SECRET_STORE=file): agent-task-manager secrets put|delete|list
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		runSecrets(cfg, os.Args[2:])
+		return
+	}
+
+	// Создаем новый роутер Gin без дефолтного middleware
+	router := gin.New()
+
+	// IP клиента берется из X-Forwarded-For только за доверенными прокси, по умолчанию - из адреса соединения
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+
 	// Настраиваем CORS
 	corsConfig := cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
@@ -59,12 +105,25 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	// Сервис задач общий для HTTP API, A2A и MCP
+	taskService := service.NewTaskService(service.NewGormRepository(database.GetDB()), cfg)
+
 	// Инициализируем кэш пользователей
 	if err := cache.InitUsersCache(); err != nil {
 		log.Printf("Warning: failed to sync users cache: %v", err)
 		// Не прерываем выполнение, так как это не критично
 	}
 
+	// Запускаем слушатель Postgres LISTEN/NOTIFY для оповещений о новых задачах и событий деревьев между репликами.
+	// После переподключения потоки событий закрываются, и клиенты догружают пропущенное из журнала по Last-Event-ID
+	if cfg.PgNotifyEnabled {
+		events.EnablePgNotify()
+		pgListener := notify.NewPostgresListener(cfg.PostgresURL)
+		pgListener.Handle(events.NotifyChannel, events.NotificationHandler(taskService.TaskEventsByID), events.DropSubscribers)
+		go pgListener.Start()
+		defer pgListener.Stop()
+	}
+
 	// Запускаем периодическую синхронизацию кэша каждые 10 минут
 	cache.StartPeriodicSync(cfg.CacheSyncInterval)
 	defer cache.StopPeriodicSync()
@@ -74,12 +133,31 @@ func main() {
 	go taskCleanupScheduler.Start()
 	defer taskCleanupScheduler.Stop()
 
+	// Запускаем планировщик возврата задач с истекшей арендой
+	leaseExpirationScheduler := scheduler.NewLeaseExpirationScheduler(cfg.LeaseCheckInterval, taskService)
+	go leaseExpirationScheduler.Start()
+	defer leaseExpirationScheduler.Stop()
+
+	// Запускаем планировщик периодических задач
+	recurringTaskScheduler := scheduler.NewRecurringTaskScheduler(cfg.RecurringCheckInterval, taskService)
+	go recurringTaskScheduler.Start()
+	defer recurringTaskScheduler.Stop()
+
+	// Ставим события задач в очередь доставки вебхуков и запускаем планировщик доставки
+	webhooks.Register(cfg.WebhookTargets)
+	webhookDeliveryScheduler := scheduler.NewWebhookDeliveryScheduler(cfg.WebhookDeliveryInterval)
+	go webhookDeliveryScheduler.Start()
+	defer webhookDeliveryScheduler.Stop()
+
 	router.GET("/health", handlers.HealthHandler)
 	router.GET("/ready", handlers.ReadyHandler)
 	router.GET("/", handlers.InfoHandler())
 	router.GET("/info", handlers.InfoHandler())
 	router.GET("/users-with-tasks", tasks.GetUsersWithTasksHandler())
 
+	// Открытые ключи проверки JWT для других сервисов
+	router.GET("/.well-known/jwks.json", handlers.JWKSHandler(cfg))
+
 	// Эндпоинт для генерации JWT с rate limiting (5 попыток за 1 минуту с одного IP)
 	router.POST("/generate-jwt",
 		handlers.RateLimitMiddleware(5, time.Minute),
@@ -87,15 +165,47 @@ func main() {
 
 	// Защищенные роуты с JWT аутентификацией
 	router.GET("/me", handlers.JwtAuthMiddleware(cfg), handlers.MeHandler())
-	router.POST("/task", handlers.JwtAuthMiddleware(cfg), tasks.CreateTaskHandler())
-	router.GET("/task", handlers.JwtAuthMiddleware(cfg), tasks.GetTaskHandler())
-	router.POST("/task/:id/complete", handlers.JwtAuthMiddleware(cfg), tasks.CompleteTaskHandler())
-	router.POST("/task/:id/cancel", handlers.JwtAuthMiddleware(cfg), tasks.CancelTaskHandler())
-	router.POST("/tasks/:id/fail", handlers.JwtAuthMiddleware(cfg), tasks.FailTaskHandler())
-	router.GET("/root-task/:id/tasks", handlers.JwtAuthMiddleware(cfg), tasks.GetRootTasksHandler())
-	router.GET("/root-task", handlers.JwtAuthMiddleware(cfg), tasks.GetUserRootTasksHandler())
+	router.POST("/task", handlers.JwtAuthMiddleware(cfg), tasks.CreateTaskHandler(taskService))
+	router.GET("/task", handlers.JwtAuthMiddleware(cfg), tasks.GetTaskHandler(taskService))
+	router.GET("/task/:id/history", handlers.JwtAuthMiddleware(cfg), tasks.GetTaskHistoryHandler(taskService))
+	router.GET("/task/:id/credential-access", handlers.JwtAuthMiddleware(cfg), tasks.GetTaskCredentialAccessHandler(taskService))
+	router.GET("/credential-access", handlers.JwtAuthMiddleware(cfg), tasks.ListCredentialAccessHandler(taskService))
+	router.POST("/task/:id/heartbeat", handlers.JwtAuthMiddleware(cfg), tasks.HeartbeatTaskHandler(taskService))
+	router.POST("/task/:id/complete", handlers.JwtAuthMiddleware(cfg), tasks.CompleteTaskHandler(taskService))
+	router.POST("/task/:id/cancel", handlers.JwtAuthMiddleware(cfg), tasks.CancelTaskHandler(taskService))
+	router.POST("/task/:id/reject", handlers.JwtAuthMiddleware(cfg), tasks.RejectTaskHandler(taskService))
+	router.POST("/task/:id/request-input", handlers.JwtAuthMiddleware(cfg), tasks.RequestInputHandler(taskService))
+	router.POST("/task/:id/provide-input", handlers.JwtAuthMiddleware(cfg), tasks.ProvideInputHandler(taskService))
+	router.POST("/tasks/:id/fail", handlers.JwtAuthMiddleware(cfg), tasks.FailTaskHandler(taskService))
+	router.GET("/dead-letter", handlers.JwtAuthMiddleware(cfg), tasks.GetDeadLetterTasksHandler(taskService))
+	router.POST("/task/:id/redrive", handlers.JwtAuthMiddleware(cfg), tasks.RedriveTaskHandler(taskService))
+	router.GET("/root-task/:id/tasks", handlers.JwtAuthMiddleware(cfg), tasks.GetRootTasksHandler(taskService))
+	router.GET("/root-task/:id/events", handlers.JwtAuthMiddleware(cfg), tasks.GetRootTaskEventsHandler(taskService))
+	router.GET("/root-task", handlers.JwtAuthMiddleware(cfg), tasks.GetUserRootTasksHandler(taskService))
 	router.GET("/stat", handlers.JwtAuthMiddleware(cfg), handlers.StatsHandler())
 
+	// Периодические задачи
+	router.POST("/recurring-task", handlers.JwtAuthMiddleware(cfg), recurring.CreateRecurringTaskHandler(cfg))
+	router.GET("/recurring-task", handlers.JwtAuthMiddleware(cfg), recurring.ListRecurringTasksHandler())
+	router.GET("/recurring-task/:id", handlers.JwtAuthMiddleware(cfg), recurring.GetRecurringTaskHandler())
+	router.PATCH("/recurring-task/:id", handlers.JwtAuthMiddleware(cfg), recurring.UpdateRecurringTaskHandler(cfg))
+	router.DELETE("/recurring-task/:id", handlers.JwtAuthMiddleware(cfg), recurring.DeleteRecurringTaskHandler())
+
+	// Подписки на вебхуки
+	router.POST("/webhook", handlers.JwtAuthMiddleware(cfg), handlerswebhooks.CreateWebhookHandler())
+	router.GET("/webhook", handlers.JwtAuthMiddleware(cfg), handlerswebhooks.ListWebhooksHandler())
+	router.DELETE("/webhook/:id", handlers.JwtAuthMiddleware(cfg), handlerswebhooks.DeleteWebhookHandler())
+	router.GET("/webhook/:id/deliveries", handlers.JwtAuthMiddleware(cfg), handlerswebhooks.GetWebhookDeliveriesHandler())
+
+	// Протокол A2A: карточка агента публичная, JSON-RPC эндпоинт требует JWT
+	router.GET("/.well-known/agent.json", a2a.AgentCardHandler())
+	router.POST("/a2a", handlers.JwtAuthMiddleware(cfg), a2a.RPCHandler(taskService))
+
+	// MCP сервер по транспорту streamable HTTP
+	mcpServer := mcp.NewServer(cfg, taskService)
+	router.POST("/mcp", handlers.JwtAuthMiddleware(cfg), mcpServer.HTTPHandler())
+	router.GET("/mcp", mcp.StreamNotSupportedHandler())
+
 	// Создаем HTTP сервер
 	srv := &http.Server{
 		Addr:    ":" + cfg.Port,
@@ -110,6 +220,19 @@ func main() {
 		}
 	}()
 
+	// gRPC сервер TaskManager на отдельном порту
+	grpcServer := grpcapi.NewServer(cfg, taskService)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatal("Failed to listen gRPC port:", err)
+	}
+	go func() {
+		log.Println("Starting gRPC server on :" + cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal("Failed to start gRPC server:", err)
+		}
+	}()
+
 	// Канал для получения сигналов завершения
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -122,11 +245,24 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Корректно завершаем gRPC сервер; потоки WatchTree не завершаются сами, поэтому по таймауту закрываем их принудительно
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	// Корректно завершаем сервер
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
 	// Закрываем соединение с базой данных
 	if err := database.CloseDB(); err != nil {
 		log.Printf("Error closing database connection: %v", err)
@@ -136,3 +272,153 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// runMCPStdio запускает MCP сервер через stdin/stdout от имени пользователя из MCP_TOKEN и работает,
+// пока клиент не закроет stdin. stdout занят протоколом, поэтому все логи пишутся в stderr,
+// а HTTP сервер и планировщики не запускаются
+func runMCPStdio(cfg *config.Config) {
+	if cfg.MCPToken == "" {
+		log.Fatal("MCP_TOKEN environment variable is required in mcp mode")
+	}
+
+	// Проверяем токен до подключения к БД, чтобы сразу сообщить о неверной настройке
+	claims, err := handlers.ParseToken(cfg, cfg.MCPToken)
+	if err != nil {
+		log.Fatal("Invalid MCP_TOKEN: ", err)
+	}
+
+	// Логгер GORM по умолчанию пишет в stdout
+	logger.Default = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+	})
+
+	if err := database.InitDB(cfg.PostgresURL); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer database.CloseDB()
+
+	// Оповещения о новых задачах между процессами, чтобы get_task с ожиданием узнавал о задачах,
+	// созданных через HTTP API, а HTTP клиенты - о задачах, созданных через MCP.
+	// События деревьев, записанные MCP, доходят до подписчиков потоков на репликах HTTP сервера
+	if cfg.PgNotifyEnabled {
+		events.EnablePgNotify()
+		pgListener := notify.NewPostgresListener(cfg.PostgresURL)
+		go pgListener.Start()
+		defer pgListener.Stop()
+	}
+
+	// События задач попадают в очередь доставки вебхуков, которую отправляют реплики HTTP сервера
+	webhooks.Register(cfg.WebhookTargets)
+
+	log.Printf("MCP server started on stdio for user %s", claims.UserID)
+
+	taskService := service.NewTaskService(service.NewGormRepository(database.GetDB()), cfg)
+	// Сигнал остановки прерывает выполняющиеся вызовы, закрытие stdin - дожидается их ответов
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := mcp.NewServer(cfg, taskService).ServeStdio(ctx, cfg.MCPToken, os.Stdin, os.Stdout); err != nil {
+		log.Printf("MCP server stopped with error: %v", err)
+	}
+}
+
+// runReencryptCredentials переводит credentials всех задач, периодических задач и секреты локального хранилища (SECRET_STORE=file)
+// на активный ключ CREDENTIALS_ENCRYPTION_KEY_ID и шифрует открытые credentials записей, созданных до включения шифрования.
+// Прежние ключи можно убрать из CREDENTIALS_ENCRYPTION_KEYS после успешного завершения команды
+func runReencryptCredentials(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("reencrypt-credentials", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 100, "number of tasks or recurring tasks re-encrypted in one transaction")
+	fs.Parse(args)
+
+	if cfg.CredentialsKeyring == nil {
+		log.Fatal("CREDENTIALS_ENCRYPTION_KEYS environment variable is required to re-encrypt credentials")
+	}
+
+	if fileStore, ok := cfg.SecretStore.(*secrets.FileStore); ok {
+		rewrapped, err := fileStore.Rewrap()
+		if err != nil {
+			log.Fatal("Failed to re-encrypt secret store: ", err)
+		}
+		log.Printf("Re-wrapped data keys of %d secrets in the secret store", rewrapped)
+	}
+
+	if err := database.InitDB(cfg.PostgresURL); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer database.CloseDB()
+
+	log.Printf("Re-encrypting task credentials with key %s", cfg.CredentialsKeyring.ActiveKeyID())
+
+	taskService := service.NewTaskService(service.NewGormRepository(database.GetDB()), cfg)
+	result, err := taskService.ReencryptCredentials(context.Background(), *batchSize)
+	log.Printf("Re-wrapped data keys of %d tasks and %d recurring tasks, encrypted plaintext credentials of %d tasks and %d recurring tasks",
+		result.Rewrapped, result.RecurringRewrapped, result.Encrypted, result.RecurringEncrypted)
+	if err != nil {
+		log.Fatal("Failed to re-encrypt credentials: ", err)
+	}
+}
+
+// runSecrets добавляет, удаляет и перечисляет секреты локального хранилища, на которые ссылается secretref://<name>.
+// Значение секрета читается из stdin, чтобы не оставлять его в истории команд
+func runSecrets(cfg *config.Config, args []string) {
+	fileStore, ok := cfg.SecretStore.(*secrets.FileStore)
+	if !ok {
+		log.Fatal("secrets command manages the local secret store and requires SECRET_STORE=file")
+	}
+
+	usage := "usage: agent-task-manager secrets put NAME < value | delete NAME | list"
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+
+	switch {
+	case args[0] == "put" && len(args) == 2:
+		value, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal("Failed to read secret value from stdin: ", err)
+		}
+		secretValue := strings.TrimRight(string(value), "\r\n")
+		if secretValue == "" {
+			log.Fatal("Secret value is empty")
+		}
+		if err := fileStore.Put(args[1], secretValue); err != nil {
+			log.Fatal("Failed to save secret: ", err)
+		}
+		log.Printf("Secret %s saved, reference: %s%s", args[1], secrets.SecretRefPrefix, args[1])
+	case args[0] == "delete" && len(args) == 2:
+		deleted, err := fileStore.Delete(args[1])
+		if err != nil {
+			log.Fatal("Failed to delete secret: ", err)
+		}
+		if !deleted {
+			log.Fatalf("Secret %s not found", args[1])
+		}
+		log.Printf("Secret %s deleted", args[1])
+	case args[0] == "list" && len(args) == 1:
+		names, err := fileStore.List()
+		if err != nil {
+			log.Fatal("Failed to list secrets: ", err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// runGenerateJWTKey создает закрытый ключ подписи JWT и выводит его в stdout в формате PEM (PKCS #8).
+// Ключ подключается через JWT_SIGNING_KEYS=kid:/path/key.pem, открытая часть публикуется в /.well-known/jwks.json
+func runGenerateJWTKey(args []string) {
+	fs := flag.NewFlagSet("generate-jwt-key", flag.ExitOnError)
+	algorithm := fs.String("alg", signing.AlgorithmEdDSA, "signing algorithm: EdDSA (Ed25519) or RS256 (RSA)")
+	bits := fs.Int("bits", 3072, "RSA key size in bits, used with -alg RS256")
+	fs.Parse(args)
+
+	key, err := signing.GenerateKey(*algorithm, *bits)
+	if err != nil {
+		log.Fatal("Failed to generate JWT signing key: ", err)
+	}
+	os.Stdout.Write(key)
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIPKey ключ контекста с IP адресом клиента, вызвавшего MCP по HTTP
+type clientIPKey struct{}
+
+// clientIPFromContext возвращает IP адрес клиента MCP по HTTP, пустую строку - в режиме stdio
+func clientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPKey{}).(string)
+	return clientIP
+}
+
+// HTTPHandler обработчик MCP по транспорту streamable HTTP (POST /mcp).
+// Сервер не хранит сессий: каждый запрос аутентифицируется JWT middleware, ответы возвращаются как JSON
+func (s *Server) HTTPHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Получаем user_id из контекста (установлен в JWT middleware)
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "user_id not found in context",
+			})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(nil, newError(codeParseError, "failed to read request body")))
+			return
+		}
+
+		messages, batch, rpcErr := parseMessages(body)
+		if rpcErr != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(nil, rpcErr))
+			return
+		}
+
+		// IP адрес клиента попадает в журнал выдачи credentials
+		ctx := context.WithValue(c.Request.Context(), clientIPKey{}, c.ClientIP())
+
+		responses := make([]*Response, 0, len(messages))
+		for _, req := range messages {
+			if response := s.Handle(ctx, userID.(string), req); response != nil {
+				responses = append(responses, response)
+			}
+		}
+
+		// Только уведомления или ответы клиента - тело ответа не нужно
+		if len(responses) == 0 {
+			c.Status(http.StatusAccepted)
+			return
+		}
+
+		if batch {
+			c.JSON(http.StatusOK, responses)
+			return
+		}
+		c.JSON(http.StatusOK, responses[0])
+	}
+}
+
+// StreamNotSupportedHandler обработчик GET /mcp. Сервер не отправляет сообщений по своей инициативе,
+// поэтому отдельный SSE поток не поддерживается
+func StreamNotSupportedHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Allow", http.MethodPost)
+		c.JSON(http.StatusMethodNotAllowed, gin.H{
+			"error": "server-initiated stream is not supported, send requests with POST",
+		})
+	}
+}
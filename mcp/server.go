@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+)
+
+// serverInstructions подсказка модели о порядке работы с инструментами
+const serverInstructions = "Use get_task to take your next task, then finish it with complete_task or fail_task. " +
+	"Delegate parts of the work with create_task and parent_task_id set to your current task."
+
+// Server MCP сервер, предоставляющий операции над задачами как инструменты.
+// Транспорт (stdio или streamable HTTP) отвечает за аутентификацию и передает сюда user_id из JWT
+type Server struct {
+	cfg   *config.Config
+	svc   *service.TaskService
+	tools []tool
+}
+
+// NewServer создает MCP сервер поверх сервиса задач
+func NewServer(cfg *config.Config, svc *service.TaskService) *Server {
+	return &Server{
+		cfg:   cfg,
+		svc:   svc,
+		tools: newTools(),
+	}
+}
+
+// newError создает ошибку JSON-RPC
+func newError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// errorResponse создает ответ JSON-RPC с ошибкой
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: jsonRPCVersion, ID: id, Error: err}
+}
+
+// parseMessages разбирает тело с одним сообщением JSON-RPC или пакетом сообщений
+func parseMessages(body []byte) ([]Request, bool, *Error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, newError(codeInvalidRequest, "empty request")
+	}
+
+	if trimmed[0] == '[' {
+		var batch []Request
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return nil, true, newError(codeParseError, "invalid JSON: "+err.Error())
+		}
+		if len(batch) == 0 {
+			return nil, true, newError(codeInvalidRequest, "empty batch")
+		}
+		return batch, true, nil
+	}
+
+	var req Request
+	if err := json.Unmarshal(trimmed, &req); err != nil {
+		return nil, false, newError(codeParseError, "invalid JSON: "+err.Error())
+	}
+	return []Request{req}, false, nil
+}
+
+// Handle обрабатывает сообщение JSON-RPC от пользователя userID. Для уведомлений и ответов клиента возвращает nil
+func (s *Server) Handle(ctx context.Context, userID string, req Request) *Response {
+	// Сервер не отправляет запросов клиенту, поэтому ответы клиента игнорируются
+	if req.IsResponse() {
+		return nil
+	}
+
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		if req.IsNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, newError(codeInvalidRequest, "invalid JSON-RPC 2.0 request"))
+	}
+
+	// Уведомления (notifications/initialized, notifications/cancelled) не требуют ответа
+	if req.IsNotification() {
+		return nil
+	}
+
+	var result interface{}
+	var rpcErr *Error
+
+	switch req.Method {
+	case methodInitialize:
+		result, rpcErr = s.initialize(req.Params)
+	case methodPing:
+		result = struct{}{}
+	case methodToolsList:
+		result = s.listTools()
+	case methodToolsCall:
+		result, rpcErr = s.callTool(ctx, userID, req.Params)
+	default:
+		rpcErr = newError(codeMethodNotFound, "method not found: "+req.Method)
+	}
+
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+	return &Response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+}
+
+// initialize согласует версию протокола: запрошенная клиентом, если она поддерживается, иначе последняя
+func (s *Server) initialize(rawParams json.RawMessage) (*InitializeResult, *Error) {
+	var params InitializeParams
+	if len(rawParams) > 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, newError(codeInvalidParams, "invalid params: "+err.Error())
+		}
+	}
+
+	protocolVersion := supportedProtocolVersions[0]
+	if slices.Contains(supportedProtocolVersions, params.ProtocolVersion) {
+		protocolVersion = params.ProtocolVersion
+	}
+
+	return &InitializeResult{
+		ProtocolVersion: protocolVersion,
+		Capabilities: map[string]interface{}{
+			"tools": map[string]bool{"listChanged": false},
+		},
+		ServerInfo: ServerInfo{
+			Name:    serverName,
+			Version: serverVersion,
+		},
+		Instructions: serverInstructions,
+	}, nil
+}
+
+// listTools возвращает описания инструментов
+func (s *Server) listTools() *ListToolsResult {
+	result := &ListToolsResult{Tools: make([]Tool, 0, len(s.tools))}
+	for _, t := range s.tools {
+		result.Tools = append(result.Tools, t.Tool)
+	}
+	return result
+}
+
+// callTool выполняет инструмент. Ошибки операции возвращаются в результате с isError,
+// ошибками протокола считаются только неизвестный инструмент и неверные параметры вызова
+func (s *Server) callTool(ctx context.Context, userID string, rawParams json.RawMessage) (*CallToolResult, *Error) {
+	var params CallToolParams
+	if len(rawParams) == 0 {
+		return nil, newError(codeInvalidParams, "params are required")
+	}
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, newError(codeInvalidParams, "invalid params: "+err.Error())
+	}
+
+	for _, t := range s.tools {
+		if t.Name != params.Name {
+			continue
+		}
+
+		output, err := t.handler(ctx, s, userID, params.Arguments)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		result, err := toolResult(output)
+		if err != nil {
+			return nil, newError(codeInternalError, "failed to encode tool result: "+err.Error())
+		}
+		return result, nil
+	}
+
+	return nil, newError(codeInvalidParams, "unknown tool: "+params.Name)
+}
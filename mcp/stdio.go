@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"agent-task-manager/handlers"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+)
+
+// codeUnauthorized код ошибки JSON-RPC для недействительного токена в режиме stdio
+const codeUnauthorized = -32001
+
+// maxStdioMessageSize максимальный размер одного сообщения в режиме stdio
+const maxStdioMessageSize = 10 * 1024 * 1024
+
+// ServeStdio обслуживает MCP сессию через in/out: по одному сообщению JSON-RPC на строку.
+// Токен проверяется для каждого сообщения, чтобы истечение срока или блокировка пользователя
+// вступали в силу без перезапуска процесса. По окончании ввода дожидается ответов на уже принятые запросы,
+// так что клиент может передать запрос и сразу закрыть stdin. Отмена ctx (сигнал остановки) прерывает
+// выполняющиеся вызовы и завершает сессию, не дожидаясь окончания ввода
+func (s *Server) ServeStdio(ctx context.Context, token string, in io.Reader, out io.Writer) error {
+	var (
+		writeMu  sync.Mutex
+		inFlight sync.WaitGroup
+		// stopMu защищает stopped: после остановки новые запросы не запускаются, и inFlight.Wait не гонится с Add
+		stopMu  sync.Mutex
+		stopped bool
+	)
+	encoder := json.NewEncoder(out)
+
+	write := func(response *Response) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := encoder.Encode(response); err != nil {
+			log.Printf("Failed to write MCP response: %v", err)
+		}
+	}
+
+	// Запросы выполняются параллельно: get_task с ожиданием не блокирует ping и другие вызовы
+	dispatch := func(req Request, userID string) {
+		stopMu.Lock()
+		defer stopMu.Unlock()
+		if stopped {
+			return
+		}
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			if response := s.Handle(ctx, userID, req); response != nil {
+				write(response)
+			}
+		}()
+	}
+
+	// Ввод читается в отдельной горутине: чтение stdin нельзя прервать, а сессия должна завершаться по отмене ctx
+	readDone := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxStdioMessageSize)
+
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			messages, _, rpcErr := parseMessages(line)
+			if rpcErr != nil {
+				write(errorResponse(nil, rpcErr))
+				continue
+			}
+
+			for _, req := range messages {
+				claims, err := handlers.ParseToken(s.cfg, token)
+				if err != nil {
+					if !req.IsNotification() {
+						write(errorResponse(req.ID, newError(codeUnauthorized, err.Error())))
+					}
+					continue
+				}
+				dispatch(req, claims.UserID)
+			}
+		}
+		readDone <- scanner.Err()
+	}()
+
+	var err error
+	select {
+	case err = <-readDone:
+		// Клиент закрыл ввод - дожидаемся ответов на принятые запросы, не прерывая их
+	case <-ctx.Done():
+		// Остановка процесса - выполняющиеся вызовы прерываются через ctx
+	}
+
+	stopMu.Lock()
+	stopped = true
+	stopMu.Unlock()
+	inFlight.Wait()
+
+	return err
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"agent-task-manager/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// toolHandler выполняет инструмент от имени пользователя и возвращает результат для модели
+type toolHandler func(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error)
+
+// tool инструмент MCP с обработчиком
+type tool struct {
+	Tool
+	handler toolHandler
+}
+
+// getTaskArguments параметры инструмента get_task
+type getTaskArguments struct {
+	Wait          int `json:"wait" description:"Seconds to wait for a task if none is available right now (capped by LONG_POLL_MAX_WAIT)"`
+	ChildrenDepth int `json:"children_depth" description:"Levels of subtasks of any status to return in children: 1 - direct subtasks, up to 10 - nested tree (default 0 - none)"`
+}
+
+// taskIDArguments параметры инструментов, работающих с существующей задачей
+type taskIDArguments struct {
+	TaskID uuid.UUID `json:"task_id" binding:"required" description:"Task ID"`
+}
+
+// completeTaskArguments параметры инструмента complete_task
+type completeTaskArguments struct {
+	taskIDArguments
+	service.CompleteTaskRequest
+}
+
+// failTaskArguments параметры инструмента fail_task
+type failTaskArguments struct {
+	taskIDArguments
+	service.FailTaskRequest
+}
+
+// newTools возвращает инструменты сервера. Схемы параметров строятся по тем же типам запросов, что и в HTTP API
+func newTools() []tool {
+	return []tool{
+		{
+			Tool: Tool{
+				Name:        "create_task",
+				Description: "Create a task for another agent. Pass parent_task_id to create a subtask of your current task: the parent waits until all its subtasks finish and is then handed out again with their results.",
+				InputSchema: schemaFor(service.CreateTaskRequest{}),
+			},
+			handler: createTask,
+		},
+		{
+			Tool: Tool{
+				Name:        "get_task",
+				Description: "Take the next task assigned to you into work. The response includes results of completed and rejected subtasks. Complete or fail the task when done.",
+				InputSchema: schemaFor(getTaskArguments{}),
+			},
+			handler: getTask,
+		},
+		{
+			Tool: Tool{
+				Name:        "complete_task",
+				Description: "Complete a task you are working on with its result. Active subtasks are canceled.",
+				InputSchema: schemaFor(completeTaskArguments{}),
+			},
+			handler: completeTask,
+		},
+		{
+			Tool: Tool{
+				Name:        "fail_task",
+				Description: "Mark a task you are working on as failed. With a retry policy the task is retried later.",
+				InputSchema: schemaFor(failTaskArguments{}),
+			},
+			handler: failTask,
+		},
+		{
+			Tool: Tool{
+				Name:        "cancel_task",
+				Description: "Cancel a task you created or are assigned to, together with all its active subtasks.",
+				InputSchema: schemaFor(taskIDArguments{}),
+			},
+			handler: cancelTask,
+		},
+	}
+}
+
+// decodeArguments разбирает аргументы инструмента и проверяет их по тегам binding, как gin в HTTP API
+func decodeArguments(arguments json.RawMessage, target interface{}) error {
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, target); err != nil {
+			return errors.New("invalid arguments: " + err.Error())
+		}
+	}
+	if err := binding.Validator.ValidateStruct(target); err != nil {
+		return errors.New("invalid arguments: " + err.Error())
+	}
+	return nil
+}
+
+// createTask создает задачу
+func createTask(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error) {
+	var req service.CreateTaskRequest
+	if err := decodeArguments(arguments, &req); err != nil {
+		return nil, err
+	}
+	return s.svc.Create(ctx, userID, req)
+}
+
+// getTask берет в работу следующую задачу пользователя
+func getTask(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error) {
+	var args getTaskArguments
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	if args.Wait < 0 {
+		return nil, errors.New("invalid arguments: wait cannot be negative")
+	}
+	opts := service.ClaimOptions{
+		ChildrenDepth: args.ChildrenDepth,
+		ClientIP:      clientIPFromContext(ctx),
+		Protocol:      "mcp",
+	}
+	return s.svc.WaitForTask(ctx, userID, time.Duration(args.Wait)*time.Second, opts)
+}
+
+// completeTask завершает задачу
+func completeTask(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error) {
+	var args completeTaskArguments
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	return s.svc.Complete(ctx, userID, args.TaskID, args.CompleteTaskRequest)
+}
+
+// failTask помечает задачу как неудачную
+func failTask(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error) {
+	var args failTaskArguments
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	return s.svc.Fail(ctx, userID, args.TaskID, args.FailTaskRequest)
+}
+
+// cancelTask отменяет задачу
+func cancelTask(ctx context.Context, s *Server, userID string, arguments json.RawMessage) (interface{}, error) {
+	var args taskIDArguments
+	if err := decodeArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	return s.svc.Cancel(ctx, userID, args.TaskID)
+}
+
+// toolResult формирует результат инструмента: JSON объекта как текст и как структурированный ответ
+func toolResult(result interface{}) (*CallToolResult, error) {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &CallToolResult{
+		Content:           []Content{{Type: "text", Text: string(payload)}},
+		StructuredContent: json.RawMessage(payload),
+	}, nil
+}
+
+// toolError формирует результат с ошибкой инструмента в том же виде, что и тело ошибки HTTP API
+func toolError(err error) *CallToolResult {
+	body := gin.H{"error": err.Error()}
+
+	var serviceErr *service.Error
+	if errors.As(err, &serviceErr) {
+		for key, value := range serviceErr.Fields {
+			body[key] = value
+		}
+	}
+
+	payload, _ := json.Marshal(body)
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(payload)}},
+		IsError: true,
+	}
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import "encoding/json"
+
+// Коды ошибок JSON-RPC 2.0
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Методы MCP
+const (
+	jsonRPCVersion   = "2.0"
+	methodInitialize = "initialize"
+	methodPing       = "ping"
+	methodToolsList  = "tools/list"
+	methodToolsCall  = "tools/call"
+)
+
+// supportedProtocolVersions поддерживаемые версии протокола MCP, первая - предпочтительная
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// serverName и serverVersion имя и версия сервера, сообщаемые клиенту при инициализации
+const (
+	serverName    = "agent-task-manager"
+	serverVersion = "1.0.0"
+)
+
+// Request запрос или уведомление JSON-RPC 2.0. У уведомлений нет ID.
+// Result и Error заполнены, если клиент прислал ответ
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// IsNotification сообщает, что запрос не требует ответа
+func (r Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// IsResponse сообщает, что сообщение является ответом клиента
+func (r Request) IsResponse() bool {
+	return r.Method == "" && (len(r.Result) > 0 || len(r.Error) > 0)
+}
+
+// Response ответ JSON-RPC 2.0
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error ошибка JSON-RPC 2.0
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// InitializeParams параметры initialize
+type InitializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+// InitializeResult результат initialize
+type InitializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      ServerInfo             `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// ServerInfo имя и версия сервера
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Tool описание инструмента для tools/list
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// ListToolsResult результат tools/list
+type ListToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// CallToolParams параметры tools/call
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Content часть результата инструмента
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult результат tools/call. Ошибки выполнения инструмента передаются с IsError,
+// чтобы модель увидела их, а не как ошибки протокола
+type CallToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
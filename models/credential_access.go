@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CredentialAccess запись журнала выдачи credentials: какой исполнитель, когда, откуда и credentials каких сервисов
+// получил вместе с задачей. Журнал только пополняется и пишется в той же транзакции, что и выдача задачи.
+// Записи не связаны с задачей внешним ключом и не удаляются вместе с ней, чтобы после инцидента
+// можно было восстановить, кто видел ключ
+type CredentialAccess struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccessedAt    time.Time  `gorm:"not null;index" json:"accessed_at"`
+	TaskID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"task_id"`
+	RootTaskID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"root_task_id"`
+	TaskCreatedBy string     `gorm:"not null;index" json:"task_created_by"` // Создатель задачи: журнал доступен ему и после удаления задачи
+	Assignee      string     `gorm:"not null;index" json:"assignee"`
+	Services      StringList `gorm:"type:jsonb;not null" json:"services"`                                      // Сервисы credentials, переданные исполнителю
+	References    StringList `gorm:"column:secret_references;type:jsonb;not null" json:"references,omitempty"` // Ссылки на секреты (secretref://, file://), разрешенные при выдаче
+	ClientIP      string     `gorm:"type:varchar(64)" json:"client_ip,omitempty"`                              // Пусто для MCP в режиме stdio
+	Protocol      string     `gorm:"type:varchar(16);not null" json:"protocol"`                                // http, grpc или mcp
+}
+
+// TableName возвращает имя таблицы для модели
+func (CredentialAccess) TableName() string {
+	return "credential_access"
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+// OverlapPolicy определяет поведение, когда наступает время запуска, а предыдущий экземпляр еще активен
+type OverlapPolicy string
+
+const (
+	OverlapSkip  OverlapPolicy = "skip"  // Пропустить запуск
+	OverlapQueue OverlapPolicy = "queue" // Отложить запуск до завершения предыдущего экземпляра
+)
+
+// RecurringTask представляет периодическое определение задачи, по которому
+// планировщик создает новую корневую задачу по расписанию cron
+type RecurringTask struct {
+	ID                  uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	CreatedBy           string          `gorm:"not null;index" json:"created_by"`
+	Name                string          `gorm:"not null" json:"name"`
+	CronExpression      string          `gorm:"not null" json:"cron_expression"`
+	Timezone            string          `gorm:"not null;default:'UTC'" json:"timezone"`
+	Assignee            string          `json:"assignee"`
+	DescriptionTemplate string          `gorm:"type:text;not null" json:"description_template"`
+	Credentials         json.RawMessage `gorm:"type:jsonb" json:"-"` // Открытые credentials определений, созданных без ключей шифрования. Копируются в созданные задачи
+	Priority            int             `gorm:"not null;default:0" json:"priority"`
+	OverlapPolicy       OverlapPolicy   `gorm:"type:varchar(20);not null;default:'skip'" json:"overlap_policy"`
+	Enabled             bool            `gorm:"not null;default:true" json:"enabled"`
+
+	// Зашифрованные credentials (envelope encryption), как у задач. Расшифровываются только при создании экземпляра,
+	// который шифрует их заново со своим ID
+	CredentialsKeyID        string `gorm:"type:varchar(64);index" json:"-"`
+	CredentialsEncryptedKey []byte `gorm:"type:bytea" json:"-"`
+	CredentialsCiphertext   []byte `gorm:"type:bytea" json:"-"`
+
+	// Состояние планировщика
+	NextRunAt   *time.Time `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastTaskID  *uuid.UUID `gorm:"type:uuid" json:"last_task_id,omitempty"`
+	RunCount    int        `gorm:"not null;default:0" json:"run_count"`
+	PendingRuns int        `gorm:"not null;default:0" json:"pending_runs"` // Запуски, отложенные политикой queue
+}
+
+// BeforeCreate hook для генерации UUID перед созданием записи
+func (r *RecurringTask) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+	if r.OverlapPolicy == "" {
+		r.OverlapPolicy = OverlapSkip
+	}
+	return nil
+}
+
+// RecurringTaskRun данные, доступные в шаблоне описания задачи
+type RecurringTaskRun struct {
+	Name        string    // Имя периодической задачи
+	ScheduledAt time.Time // Время запуска по расписанию в часовом поясе периодической задачи
+	RunNumber   int       // Порядковый номер запуска, начиная с 1
+}
+
+// Location возвращает часовой пояс, в котором вычисляется расписание
+func (r *RecurringTask) Location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+	return loc, nil
+}
+
+// NextRun вычисляет ближайшее время запуска по cron-выражению после указанного момента
+func (r *RecurringTask) NextRun(after time.Time) (time.Time, error) {
+	loc, err := r.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	schedule, err := cron.ParseStandard(r.CronExpression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression never fires")
+	}
+	return next, nil
+}
+
+// RenderDescription формирует описание новой задачи по шаблону DescriptionTemplate
+func (r *RecurringTask) RenderDescription(run RecurringTaskRun) (string, error) {
+	tmpl, err := template.New("description").Option("missingkey=error").Parse(r.DescriptionTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid description template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, run); err != nil {
+		return "", fmt.Errorf("failed to render description template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// TableName возвращает имя таблицы для модели
+func (RecurringTask) TableName() string {
+	return "recurring_tasks"
+}
@@ -22,6 +22,7 @@ const (
 	StatusWorking       TaskStatus = "working"
 	StatusWaiting       TaskStatus = "waiting"
 	StatusInputRequired TaskStatus = "input-required"
+	StatusDeadLettered  TaskStatus = "dead-lettered"
 	StatusUnknown       TaskStatus = "unknown"
 )
 
@@ -49,6 +50,25 @@ func (s TaskStatus) Value() (driver.Value, error) {
 	return string(s), nil
 }
 
+// ChildFailurePolicy определяет реакцию родительской задачи на окончательную неудачу подзадачи
+// (failed или dead-lettered)
+type ChildFailurePolicy string
+
+const (
+	ChildFailureWait           ChildFailurePolicy = "wait"            // Родитель продолжает ждать, неудачная подзадача не считается завершенной
+	ChildFailureResubmitParent ChildFailurePolicy = "resubmit_parent" // Родитель возвращается в очередь, чтобы увидеть неудачу
+	ChildFailureFailParent     ChildFailurePolicy = "fail_parent"     // Неудача поднимается к родителю, его активные подзадачи отменяются
+	ChildFailureCancelSiblings ChildFailurePolicy = "cancel_siblings" // Остальные активные подзадачи отменяются, родитель возвращается в очередь
+)
+
+// ChildFailurePolicies допустимые значения ChildFailurePolicy
+var ChildFailurePolicies = []ChildFailurePolicy{
+	ChildFailureWait,
+	ChildFailureResubmitParent,
+	ChildFailureFailParent,
+	ChildFailureCancelSiblings,
+}
+
 // Task представляет модель задачи
 type Task struct {
 	ID           uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -60,8 +80,49 @@ type Task struct {
 	RootTaskID   *uuid.UUID      `gorm:"type:uuid;index;constraint:OnDelete:CASCADE" json:"root_task_id,omitempty"`
 	ParentTaskID *uuid.UUID      `gorm:"type:uuid;index;constraint:OnDelete:CASCADE" json:"parent_task_id,omitempty"`
 	Result       string          `gorm:"type:text" json:"result"`
-	Credentials  json.RawMessage `gorm:"type:jsonb" json:"credentials,omitempty"`
+	Credentials  json.RawMessage `gorm:"type:jsonb" json:"-"` // Открытые credentials задач, созданных без ключей шифрования. Не сериализуются, см. Redacted
 	Status       TaskStatus      `gorm:"type:varchar(20);not null;default:'submitted'" json:"status"`
+	Priority     int             `gorm:"not null;default:0;index" json:"priority"` // Чем больше значение, тем раньше задача выдается исполнителю
+	RunAt        *time.Time      `gorm:"index" json:"run_at,omitempty"`            // Время, раньше которого задача не выдается исполнителю
+
+	// Периодическое определение, по которому создана корневая задача
+	RecurringTaskID *uuid.UUID `gorm:"type:uuid;index" json:"recurring_task_id,omitempty"`
+
+	// Аренда задачи исполнителем: если исполнитель не продлил аренду вовремя, задача возвращается в submitted
+	LeaseExpiresAt *time.Time `gorm:"index" json:"lease_expires_at,omitempty"`
+	RequeueCount   int        `gorm:"not null;default:0" json:"requeue_count"`
+
+	// Политика повторных попыток: при неудаче задача возвращается в submitted не раньше NotBefore,
+	// пока не исчерпаны MaxAttempts, после чего переходит в dead-lettered
+	MaxAttempts        int        `gorm:"not null;default:1" json:"max_attempts"`
+	Attempts           int        `gorm:"not null;default:0" json:"attempts"`
+	BackoffBaseSeconds int        `gorm:"not null;default:0" json:"backoff_base_seconds,omitempty"`
+	MaxDelaySeconds    int        `gorm:"not null;default:0" json:"max_delay_seconds,omitempty"`
+	NotBefore          *time.Time `gorm:"index" json:"not_before,omitempty"`
+
+	// Запрос дополнительных данных: исполнитель задает вопрос (input-required),
+	// создатель отвечает, и задача возвращается в submitted с приложенным ответом
+	InputQuestion string `gorm:"type:text" json:"input_question,omitempty"`
+	InputAnswer   string `gorm:"type:text" json:"input_answer,omitempty"`
+
+	// Структурированные входные данные и результат задачи с необязательными JSON Schema:
+	// input проверяется по input_schema при создании, output по output_schema при завершении
+	Input        json.RawMessage `gorm:"type:jsonb" json:"input,omitempty"`
+	Output       json.RawMessage `gorm:"type:jsonb" json:"output,omitempty"`
+	InputSchema  json.RawMessage `gorm:"type:jsonb" json:"input_schema,omitempty"`
+	OutputSchema json.RawMessage `gorm:"type:jsonb" json:"output_schema,omitempty"`
+
+	// Зашифрованные credentials (envelope encryption): ключ данных зашифрован ключом CredentialsKeyID из конфигурации,
+	// credentials - ключом данных. Расшифровываются только при выдаче задачи исполнителю
+	CredentialsKeyID        string `gorm:"type:varchar(64);index" json:"-"`
+	CredentialsEncryptedKey []byte `gorm:"type:bytea" json:"-"`
+	CredentialsCiphertext   []byte `gorm:"type:bytea" json:"-"`
+
+	// Реакция задачи на окончательную неудачу одной из её подзадач
+	OnChildFailure ChildFailurePolicy `gorm:"type:varchar(20);not null;default:'wait'" json:"on_child_failure"`
+
+	// Задачи, от которых зависит задача (таблица task_dependencies). Заполняется при создании и в дереве задач
+	DependsOn []uuid.UUID `gorm:"-" json:"depends_on,omitempty"`
 
 	// Связи для каскадного удаления
 	RootTask   *Task `gorm:"foreignKey:RootTaskID;constraint:OnDelete:CASCADE" json:"-"`
@@ -76,9 +137,64 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.Status == "" {
 		t.Status = StatusSubmitted
 	}
+	if t.MaxAttempts < 1 {
+		t.MaxAttempts = 1
+	}
+	if t.OnChildFailure == "" {
+		t.OnChildFailure = ChildFailureWait
+	}
 	return nil
 }
 
+// Redacted возвращает копию задачи без открытых и зашифрованных credentials. Это единая политика раскрытия
+// credentials: задача в ответах API, событиях, вебхуках и дереве подзадач передается без них, а исполнитель
+// получает credentials только в ответе на взятие задачи в работу
+func (t Task) Redacted() Task {
+	t.Credentials = nil
+	t.CredentialsKeyID = ""
+	t.CredentialsEncryptedKey = nil
+	t.CredentialsCiphertext = nil
+	return t
+}
+
+// HasRetryPolicy сообщает, была ли задаче задана политика повторных попыток
+func (t *Task) HasRetryPolicy() bool {
+	return t.MaxAttempts > 1
+}
+
+// AvailableAt возвращает время, раньше которого задачу нельзя выдать исполнителю: более позднее из RunAt
+// и NotBefore повторной попытки. nil - ограничений нет
+func (t *Task) AvailableAt() *time.Time {
+	if t.NotBefore != nil && (t.RunAt == nil || t.NotBefore.After(*t.RunAt)) {
+		return t.NotBefore
+	}
+	return t.RunAt
+}
+
+// IsScheduled сообщает, что задача ожидает наступления времени RunAt или NotBefore и пока не может быть выдана исполнителю
+func (t *Task) IsScheduled(now time.Time) bool {
+	availableAt := t.AvailableAt()
+	return t.Status == StatusSubmitted && availableAt != nil && availableAt.After(now)
+}
+
+// RetryDelay вычисляет задержку перед следующей попыткой: BackoffBase * 2^(Attempts-1), но не больше MaxDelay
+func (t *Task) RetryDelay() time.Duration {
+	delay := time.Duration(t.BackoffBaseSeconds) * time.Second
+	maxDelay := time.Duration(t.MaxDelaySeconds) * time.Second
+
+	for i := 1; i < t.Attempts; i++ {
+		delay *= 2
+		if maxDelay > 0 && delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	if maxDelay > 0 && delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
 // TableName возвращает имя таблицы для модели
 func (Task) TableName() string {
 	return "tasks"
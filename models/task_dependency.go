@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency зависимость задачи от другой задачи (depends_on).
+// Задача ожидает в статусе waiting, пока все задачи, от которых она зависит, не будут завершены
+type TaskDependency struct {
+	TaskID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"task_id"`
+	DependsOnID uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"depends_on_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Связи для каскадного удаления зависимостей вместе с задачами
+	Task      *Task `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"-"`
+	DependsOn *Task `gorm:"foreignKey:DependsOnID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName возвращает имя таблицы для модели
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
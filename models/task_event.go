@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskEvent запись журнала изменений задачи: кто и когда перевел задачу из одного статуса в другой.
+// Пишется в той же транзакции, что и сам переход
+type TaskEvent struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt  time.Time  `gorm:"not null;index" json:"created_at"`
+	TaskID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"task_id"`
+	RootTaskID uuid.UUID  `gorm:"type:uuid;not null;index" json:"root_task_id"`
+	Type       string     `gorm:"type:varchar(32);not null;index" json:"type"`
+	FromStatus TaskStatus `gorm:"type:varchar(20)" json:"from_status,omitempty"` // Пусто для события создания
+	ToStatus   TaskStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	Actor      string     `gorm:"not null" json:"actor"` // user_id инициатора или system для планировщиков
+
+	// Снимок задачи после перехода без credentials. По нему поток событий дерева возобновляется из журнала
+	TaskSnapshot json.RawMessage `gorm:"type:jsonb" json:"-"`
+
+	// Связь для каскадного удаления журнала вместе с задачей
+	Task *Task `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName возвращает имя таблицы для модели
+func (TaskEvent) TableName() string {
+	return "task_events"
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookFilterType определяет, по какому признаку подписка отбирает задачи
+type WebhookFilterType string
+
+const (
+	WebhookFilterCreator  WebhookFilterType = "creator"   // Задачи, созданные пользователем
+	WebhookFilterAssignee WebhookFilterType = "assignee"  // Задачи, назначенные на пользователя
+	WebhookFilterRootTask WebhookFilterType = "root_task" // Задачи дерева корневой задачи
+)
+
+// WebhookDeliveryStatus статус доставки события
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// StringList список строк, хранимый в jsonb
+type StringList []string
+
+// Scan реализует интерфейс Scanner для StringList
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case []byte:
+		return json.Unmarshal(v, l)
+	default:
+		return errors.New("cannot scan StringList")
+	}
+}
+
+// Value реализует интерфейс driver.Valuer для StringList
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Contains проверяет наличие строки в списке
+func (l StringList) Contains(value string) bool {
+	for _, item := range l {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription представляет подписку на события жизненного цикла задач
+type WebhookSubscription struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CreatedBy   string            `gorm:"not null;index" json:"created_by"`
+	URL         string            `gorm:"type:text;not null" json:"url"`
+	Secret      string            `gorm:"not null" json:"-"` // Ключ HMAC подписи, отдается только при создании
+	FilterType  WebhookFilterType `gorm:"type:varchar(20);not null;index:idx_webhook_filter" json:"filter_type"`
+	FilterValue string            `gorm:"not null;index:idx_webhook_filter" json:"filter_value"`
+	EventTypes  StringList        `gorm:"type:jsonb" json:"event_types"` // Пустой список - все события
+	Active      bool              `gorm:"not null;default:true" json:"active"`
+}
+
+// BeforeCreate hook для генерации UUID перед созданием записи
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName возвращает имя таблицы для модели
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery представляет запись персистентной очереди доставки события подписчику
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CreatedAt      time.Time             `json:"created_at"`
+	SubscriptionID uuid.UUID             `gorm:"type:uuid;not null;index;constraint:OnDelete:CASCADE" json:"subscription_id"`
+	EventID        int64                 `gorm:"not null" json:"event_id"`
+	EventType      string                `gorm:"type:varchar(32);not null" json:"event_type"`
+	Payload        json.RawMessage       `gorm:"type:jsonb;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_webhook_delivery_due" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null;index:idx_webhook_delivery_due" json:"next_attempt_at"`
+	LastError      string                `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+
+	Subscription *WebhookSubscription `gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook для генерации UUID перед созданием записи
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.Status == "" {
+		d.Status = DeliveryPending
+	}
+	return nil
+}
+
+// TableName возвращает имя таблицы для модели
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"log"
+	"sync"
+
+	"agent-task-manager/database"
+)
+
+// channelTaskSubmitted канал Postgres LISTEN/NOTIFY для оповещения о появлении задач в статусе submitted
+const channelTaskSubmitted = "task_submitted"
+
+// TaskNotifier рассылает ожидающим запросам оповещения о появлении задач для исполнителя
+type TaskNotifier struct {
+	mu          sync.Mutex
+	waiters     map[string]map[chan struct{}]struct{} // Подписчики по исполнителю
+	pgListening bool                                  // Оповещения доставляются через Postgres LISTEN/NOTIFY
+}
+
+// Global instance
+var taskNotifier = &TaskNotifier{
+	waiters: make(map[string]map[chan struct{}]struct{}),
+}
+
+// Subscribe подписывает на оповещения о задачах исполнителя.
+// Возвращает канал оповещений и функцию отписки, которую нужно вызвать по завершении ожидания
+func Subscribe(assignee string) (<-chan struct{}, func()) {
+	// Буфер на одно оповещение, чтобы не потерять сигнал между попытками взять задачу
+	ch := make(chan struct{}, 1)
+
+	taskNotifier.mu.Lock()
+	if taskNotifier.waiters[assignee] == nil {
+		taskNotifier.waiters[assignee] = make(map[chan struct{}]struct{})
+	}
+	taskNotifier.waiters[assignee][ch] = struct{}{}
+	taskNotifier.mu.Unlock()
+
+	unsubscribe := func() {
+		taskNotifier.mu.Lock()
+		defer taskNotifier.mu.Unlock()
+
+		delete(taskNotifier.waiters[assignee], ch)
+		if len(taskNotifier.waiters[assignee]) == 0 {
+			delete(taskNotifier.waiters, assignee)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// TaskSubmitted оповещает о том, что у исполнителя появилась задача в статусе submitted.
+// Вызывается после коммита транзакции. Если включен Postgres LISTEN/NOTIFY, оповещение
+// получат все реплики, иначе только запросы, ожидающие в текущем процессе
+func TaskSubmitted(assignee string) {
+	taskNotifier.mu.Lock()
+	pgListening := taskNotifier.pgListening
+	taskNotifier.mu.Unlock()
+
+	if pgListening {
+		db := database.GetDB()
+		if db != nil {
+			err := db.Exec("SELECT pg_notify(?, ?)", channelTaskSubmitted, assignee).Error
+			if err == nil {
+				// Локальные подписчики получат оповещение через слушателя Postgres
+				return
+			}
+			log.Printf("Warning: failed to send pg_notify, notifying local waiters only: %v", err)
+		}
+	}
+
+	notifyLocal(assignee)
+}
+
+// notifyLocal будит все запросы текущего процесса, ожидающие задачи исполнителя
+func notifyLocal(assignee string) {
+	taskNotifier.mu.Lock()
+	defer taskNotifier.mu.Unlock()
+
+	for ch := range taskNotifier.waiters[assignee] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// Подписчик уже имеет непрочитанное оповещение
+		}
+	}
+}
+
+// setPgListening включает или выключает доставку оповещений через Postgres
+func setPgListening(listening bool) {
+	taskNotifier.mu.Lock()
+	taskNotifier.pgListening = listening
+	taskNotifier.mu.Unlock()
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// reconnectDelay пауза перед повторным подключением слушателя после ошибки
+const reconnectDelay = 5 * time.Second
+
+// PostgresListener слушает канал Postgres LISTEN/NOTIFY и будит локальные ожидающие запросы,
+// чтобы оповещения о задачах доходили до всех реплик сервиса
+type PostgresListener struct {
+	postgresURL string
+	handlers    map[string]channelHandler // Обработчики дополнительных каналов
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// channelHandler обработчик оповещений дополнительного канала
+type channelHandler struct {
+	handle func(payload string)
+	resync func() // Вызывается после каждого подключения: оповещения, отправленные без слушателя, потеряны
+}
+
+// NewPostgresListener создает новый слушатель оповещений Postgres
+func NewPostgresListener(postgresURL string) *PostgresListener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PostgresListener{
+		postgresURL: postgresURL,
+		handlers:    make(map[string]channelHandler),
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+}
+
+// Handle подписывает слушатель на дополнительный канал: handle получает payload каждого оповещения,
+// resync (может быть nil) вызывается после каждого подключения к Postgres. Вызывается до Start
+func (l *PostgresListener) Handle(channel string, handle func(payload string), resync func()) {
+	l.handlers[channel] = channelHandler{handle: handle, resync: resync}
+}
+
+// Start запускает прослушивание с автоматическим переподключением
+func (l *PostgresListener) Start() {
+	defer close(l.done)
+	log.Println("Starting Postgres task notification listener")
+
+	for {
+		if err := l.listen(); err != nil && l.ctx.Err() == nil {
+			log.Printf("Postgres listener error, reconnecting in %v: %v", reconnectDelay, err)
+		}
+
+		// Пока слушатель не подключен, оповещения рассылаются только локально
+		setPgListening(false)
+
+		select {
+		case <-l.ctx.Done():
+			log.Println("Stopping Postgres task notification listener")
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// Stop останавливает слушатель
+func (l *PostgresListener) Stop() {
+	l.cancel()
+	<-l.done
+}
+
+// listen подключается к Postgres и обрабатывает оповещения до ошибки или остановки
+func (l *PostgresListener) listen() error {
+	conn, err := pgx.Connect(l.ctx, l.postgresURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(l.ctx, "LISTEN "+channelTaskSubmitted); err != nil {
+		return err
+	}
+
+	for channel := range l.handlers {
+		if _, err := conn.Exec(l.ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+	}
+
+	setPgListening(true)
+	log.Printf("Listening for task notifications on channel %q", channelTaskSubmitted)
+
+	for _, handler := range l.handlers {
+		if handler.resync != nil {
+			handler.resync()
+		}
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(l.ctx)
+		if err != nil {
+			return err
+		}
+
+		if handler, ok := l.handlers[notification.Channel]; ok {
+			handler.handle(notification.Payload)
+			continue
+		}
+		notifyLocal(notification.Payload)
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"agent-task-manager/service"
+)
+
+// LeaseExpirationScheduler возвращает в очередь задачи, аренда которых истекла
+type LeaseExpirationScheduler struct {
+	interval    time.Duration
+	taskService *service.TaskService
+	stopChan    chan struct{}
+}
+
+// NewLeaseExpirationScheduler создает новый планировщик проверки истекших аренд.
+// Возврат задач выполняется сервисом задач по тем же правилам переходов, что и в API
+func NewLeaseExpirationScheduler(interval time.Duration, taskService *service.TaskService) *LeaseExpirationScheduler {
+	return &LeaseExpirationScheduler{
+		interval:    interval,
+		taskService: taskService,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start запускает периодическую проверку истекших аренд
+func (s *LeaseExpirationScheduler) Start() {
+	log.Println("Starting lease expiration scheduler with interval:", s.interval)
+
+	// Запускаем первую проверку сразу
+	s.requeueExpiredLeases()
+
+	// Создаем тикер для периодического запуска
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.requeueExpiredLeases()
+		case <-s.stopChan:
+			log.Println("Stopping lease expiration scheduler")
+			return
+		}
+	}
+}
+
+// Stop останавливает планировщик
+func (s *LeaseExpirationScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// requeueExpiredLeases переводит задачи в working с истекшей арендой обратно в submitted
+func (s *LeaseExpirationScheduler) requeueExpiredLeases() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	requeued, err := s.taskService.RequeueExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error requeueing tasks with expired lease: %v", err)
+		return
+	}
+
+	if len(requeued) == 0 {
+		return
+	}
+
+	for _, task := range requeued {
+		log.Printf("Task %s requeued after lease expiration (assignee: %s, requeue count: %d)",
+			task.ID, task.Assignee, task.RequeueCount)
+	}
+
+	log.Printf("Requeued %d tasks with expired lease", len(requeued))
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"agent-task-manager/service"
+
+	// Встраиваем базу часовых поясов, так как в минимальных образах её может не быть
+	_ "time/tzdata"
+)
+
+// RecurringTaskScheduler создает корневые задачи по расписанию периодических задач
+type RecurringTaskScheduler struct {
+	interval    time.Duration
+	taskService *service.TaskService
+	stopChan    chan struct{}
+}
+
+// NewRecurringTaskScheduler создает новый планировщик периодических задач.
+// Задачи создаются сервисом задач по тем же правилам, что и через API, включая шифрование credentials
+func NewRecurringTaskScheduler(interval time.Duration, taskService *service.TaskService) *RecurringTaskScheduler {
+	return &RecurringTaskScheduler{
+		interval:    interval,
+		taskService: taskService,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start запускает периодическую проверку расписаний
+func (s *RecurringTaskScheduler) Start() {
+	log.Println("Starting recurring task scheduler with interval:", s.interval)
+
+	// Запускаем первую проверку сразу
+	s.runDueRecurringTasks()
+
+	// Создаем тикер для периодического запуска
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runDueRecurringTasks()
+		case <-s.stopChan:
+			log.Println("Stopping recurring task scheduler")
+			return
+		}
+	}
+}
+
+// Stop останавливает планировщик
+func (s *RecurringTaskScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// runDueRecurringTasks обрабатывает периодические задачи, время запуска которых наступило,
+// и задачи с отложенными политикой queue запусками
+func (s *RecurringTaskScheduler) runDueRecurringTasks() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	due, err := s.taskService.DueRecurringTasks(ctx, now)
+	if err != nil {
+		log.Printf("Error finding due recurring tasks: %v", err)
+		return
+	}
+
+	// Каждое определение обрабатывается в отдельной транзакции сервиса
+	for _, recurringTaskID := range due {
+		run, err := s.taskService.RunRecurringTask(ctx, recurringTaskID, now)
+		if err != nil {
+			log.Printf("Error processing recurring task %s: %v", recurringTaskID, err)
+			continue
+		}
+		if run == nil {
+			continue
+		}
+
+		switch {
+		case run.Queued:
+			log.Printf("Recurring task %s: previous instance is still active, run queued", recurringTaskID)
+		case run.Skipped:
+			log.Printf("Recurring task %s: previous instance is still active, run skipped", recurringTaskID)
+		}
+		if run.DisabledReason != nil {
+			log.Printf("Recurring task %s disabled: %v", recurringTaskID, run.DisabledReason)
+		}
+		if run.Task != nil {
+			log.Printf("Recurring task %s spawned root task %s", recurringTaskID, run.Task.ID)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"agent-task-manager/database"
+	"agent-task-manager/models"
+	"agent-task-manager/webhooks"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// webhookBatchSize - сколько доставок забирается за один проход
+	webhookBatchSize = 50
+	// webhookClaimTTL - на это время доставка откладывается при захвате, чтобы ее не забрала другая реплика.
+	// Если процесс упадет во время отправки, доставка будет повторена после истечения этого времени
+	webhookClaimTTL = 2 * time.Minute
+)
+
+// WebhookDeliveryScheduler отправляет вебхуки из персистентной очереди доставки
+type WebhookDeliveryScheduler struct {
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewWebhookDeliveryScheduler создает новый планировщик доставки вебхуков
+func NewWebhookDeliveryScheduler(interval time.Duration) *WebhookDeliveryScheduler {
+	return &WebhookDeliveryScheduler{
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start запускает периодическую отправку вебхуков
+func (s *WebhookDeliveryScheduler) Start() {
+	log.Println("Starting webhook delivery scheduler with interval:", s.interval)
+
+	// Запускаем первую отправку сразу
+	s.deliverDue()
+
+	// Создаем тикер для периодического запуска
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deliverDue()
+		case <-s.stopChan:
+			log.Println("Stopping webhook delivery scheduler")
+			return
+		}
+	}
+}
+
+// Stop останавливает планировщик
+func (s *WebhookDeliveryScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// deliverDue захватывает доставки, время которых наступило, и отправляет их подписчикам
+func (s *WebhookDeliveryScheduler) deliverDue() {
+	db := database.GetDB()
+	if db == nil {
+		log.Println("Database connection is not available")
+		return
+	}
+
+	for {
+		deliveries, err := s.claimDue(db)
+		if err != nil {
+			log.Printf("Error claiming webhook deliveries: %v", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			s.deliver(db, delivery)
+		}
+
+		// Неполная пачка - очередь на данный момент пуста
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+	}
+}
+
+// claimDue забирает пачку доставок и откладывает их на webhookClaimTTL
+func (s *WebhookDeliveryScheduler) claimDue(db *gorm.DB) ([]models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED позволяет нескольким репликам разбирать очередь параллельно
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(webhookBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(webhookClaimTTL)).Error
+	})
+
+	return deliveries, err
+}
+
+// deliver отправляет одну доставку и сохраняет результат попытки
+func (s *WebhookDeliveryScheduler) deliver(db *gorm.DB, delivery models.WebhookDelivery) {
+	var subscription models.WebhookSubscription
+	if err := db.First(&subscription, "id = ?", delivery.SubscriptionID).Error; err != nil {
+		log.Printf("Error loading webhook subscription %s: %v", delivery.SubscriptionID, err)
+		return
+	}
+
+	// Подписка отключена после постановки в очередь - доставку не отправляем
+	if !subscription.Active {
+		db.Model(&delivery).Updates(map[string]interface{}{
+			"status":     models.DeliveryFailed,
+			"last_error": "subscription is inactive",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookClaimTTL/2)
+	defer cancel()
+
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	if err := webhooks.Deliver(ctx, subscription, delivery); err != nil {
+		updates["last_error"] = webhooks.TruncateError(err)
+		if attempts >= webhooks.MaxDeliveryAttempts {
+			updates["status"] = models.DeliveryFailed
+			log.Printf("Webhook delivery %s to %s failed permanently after %d attempts: %v",
+				delivery.ID, subscription.URL, attempts, err)
+		} else {
+			updates["next_attempt_at"] = time.Now().Add(webhooks.RetryDelay(attempts))
+		}
+	} else {
+		updates["status"] = models.DeliveryDelivered
+		updates["delivered_at"] = time.Now()
+		updates["last_error"] = ""
+	}
+
+	if err := db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		log.Printf("Error saving webhook delivery %s result: %v", delivery.ID, err)
+	}
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvStore хранилище секретов в переменных окружения сервиса. Секрет secretref://github.token
+// читается из переменной <prefix>GITHUB_TOKEN: без префикса ссылка могла бы прочитать SECRET_KEY или POSTGRES_URL
+type EnvStore struct {
+	prefix string
+}
+
+// NewEnvStore создает хранилище секретов в переменных окружения с префиксом prefix
+func NewEnvStore(prefix string) *EnvStore {
+	return &EnvStore{prefix: prefix}
+}
+
+// Get возвращает значение переменной окружения секрета
+func (s *EnvStore) Get(ctx context.Context, name string) (string, error) {
+	variable := s.Variable(name)
+	value, ok := os.LookupEnv(variable)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrNotFound, variable)
+	}
+	return value, nil
+}
+
+// Variable возвращает имя переменной окружения секрета: префикс и имя в верхнем регистре,
+// символы, отличные от букв и цифр, заменяются на _
+func (s *EnvStore) Variable(name string) string {
+	return s.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileDir каталог, из которого разрешаются ссылки file:///path, например смонтированные секреты Kubernetes.
+// Файлы вне каталога не читаются, чтобы создатель задачи не мог получить произвольный файл сервера
+type FileDir struct {
+	dir string
+}
+
+// NewFileDir создает каталог ссылок file:// с абсолютным путем dir
+func NewFileDir(dir string) (*FileDir, error) {
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDir{dir: absolute}, nil
+}
+
+// path возвращает путь файла по ссылке file:///path, проверяя, что он лежит внутри каталога
+func (d *FileDir) path(reference string) (string, error) {
+	parsed, err := url.Parse(reference)
+	if err != nil {
+		return "", fmt.Errorf("invalid file reference: %w", err)
+	}
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		return "", errors.New("file reference must be file:///absolute/path")
+	}
+	if parsed.Path == "" || !filepath.IsAbs(parsed.Path) {
+		return "", errors.New("file reference must contain an absolute path")
+	}
+
+	path := filepath.Clean(parsed.Path)
+	if !withinDir(d.dir, path) {
+		return "", fmt.Errorf("file %s is outside of SECRET_FILE_DIR", path)
+	}
+	return path, nil
+}
+
+// Read читает секрет из файла по ссылке file:///path. Завершающий перевод строки отбрасывается
+func (d *FileDir) Read(reference string) (string, error) {
+	path, err := d.path(reference)
+	if err != nil {
+		return "", err
+	}
+
+	// Символические ссылки раскрываются, чтобы ссылка внутри каталога не вела на файл вне его
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return "", err
+	}
+	dir, err := filepath.EvalSymlinks(d.dir)
+	if err != nil {
+		return "", err
+	}
+	if !withinDir(dir, resolved) {
+		return "", fmt.Errorf("file %s is outside of SECRET_FILE_DIR", path)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// withinDir сообщает, что путь path лежит внутри каталога dir
+func withinDir(dir, path string) bool {
+	relative, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return relative != "." && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
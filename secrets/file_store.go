@@ -0,0 +1,209 @@
+package secrets
+
+import (
+	"agent-task-manager/encryption"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileStore локальное хранилище секретов в JSON файле. Каждый секрет зашифрован так же, как credentials задач:
+// ключом данных, зашифрованным ключом из CREDENTIALS_ENCRYPTION_KEYS. Файл перечитывается при каждом обращении,
+// поэтому секреты, добавленные командой secrets, видны без перезапуска сервиса
+type FileStore struct {
+	path    string
+	keyring *encryption.Keyring
+	mu      sync.Mutex
+}
+
+// fileStoreData содержимое файла хранилища
+type fileStoreData struct {
+	Secrets map[string]fileStoreSecret `json:"secrets"`
+}
+
+// fileStoreSecret зашифрованный секрет
+type fileStoreSecret struct {
+	KeyID        string    `json:"key_id"`
+	EncryptedKey []byte    `json:"encrypted_key"`
+	Ciphertext   []byte    `json:"ciphertext"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// envelope возвращает зашифрованный секрет в виде конверта
+func (s fileStoreSecret) envelope() *encryption.Envelope {
+	return &encryption.Envelope{KeyID: s.KeyID, EncryptedKey: s.EncryptedKey, Ciphertext: s.Ciphertext}
+}
+
+// NewFileStore создает хранилище секретов в файле path, зашифрованное ключами keyring
+func NewFileStore(path string, keyring *encryption.Keyring) *FileStore {
+	return &FileStore{path: path, keyring: keyring}
+}
+
+// Get возвращает расшифрованный секрет
+func (s *FileStore) Get(ctx context.Context, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := data.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	value, err := s.keyring.Open(secret.envelope(), []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+	return string(value), nil
+}
+
+// Put шифрует и сохраняет секрет, заменяя прежнее значение
+func (s *FileStore) Put(name, value string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	envelope, err := s.keyring.Seal([]byte(value), []byte(name))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+	}
+	data.Secrets[name] = fileStoreSecret{
+		KeyID:        envelope.KeyID,
+		EncryptedKey: envelope.EncryptedKey,
+		Ciphertext:   envelope.Ciphertext,
+		UpdatedAt:    time.Now().UTC(),
+	}
+	return s.save(data)
+}
+
+// Delete удаляет секрет. Возвращает false, если секрета не было
+func (s *FileStore) Delete(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := data.Secrets[name]; !ok {
+		return false, nil
+	}
+
+	delete(data.Secrets, name)
+	return true, s.save(data)
+}
+
+// List возвращает имена секретов в алфавитном порядке
+func (s *FileStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(data.Secrets))
+	for name := range data.Secrets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Rewrap перешифровывает ключи данных секретов, зашифрованные прежними ключами, активным ключом.
+// Возвращает количество перешифрованных секретов
+func (s *FileStore) Rewrap() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for name, secret := range data.Secrets {
+		envelope := secret.envelope()
+		changed, err := s.keyring.Rewrap(envelope, []byte(name))
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt secret %s: %w", name, err)
+		}
+		if !changed {
+			continue
+		}
+
+		secret.KeyID = envelope.KeyID
+		secret.EncryptedKey = envelope.EncryptedKey
+		data.Secrets[name] = secret
+		rewrapped++
+	}
+
+	if rewrapped == 0 {
+		return 0, nil
+	}
+	return rewrapped, s.save(data)
+}
+
+// load читает файл хранилища. Отсутствующий файл - пустое хранилище
+func (s *FileStore) load() (*fileStoreData, error) {
+	data := &fileStoreData{Secrets: make(map[string]fileStoreSecret)}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to read secret store: %w", err)
+	}
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("invalid secret store %s: %w", s.path, err)
+	}
+	if data.Secrets == nil {
+		data.Secrets = make(map[string]fileStoreSecret)
+	}
+	return data, nil
+}
+
+// save атомарно записывает файл хранилища с правами 0600: во временный файл рядом, затем переименованием
+func (s *FileStore) save(data *fileStoreData) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Подстановки в правилах политики доступа
+const (
+	// AllUsers пользователь правила, действующего для всех пользователей
+	AllUsers = "*"
+	// userPlaceholder заменяется в префиксе на ID пользователя, например secretref://users/{user}/
+	userPlaceholder = "{user}"
+)
+
+// ErrAccessDenied политика доступа не разрешает пользователю ссылку на секрет
+var ErrAccessDenied = errors.New("secret reference is not allowed")
+
+// AccessPolicy определяет, на какие секреты может ссылаться пользователь. Секрет доступен владельцу
+// credentials, если ссылка начинается с одного из разрешенных ему префиксов. Без правил ссылки запрещены всем:
+// иначе любой пользователь мог бы создать задачу со ссылкой на чужой секрет, назначить её себе и получить значение
+type AccessPolicy struct {
+	rules map[string][]string // Разрешенные префиксы ссылок по ID пользователя, AllUsers - для всех
+}
+
+// ParseAccessPolicy разбирает политику вида "*=secretref://users/{user}/;alice=secretref://team-a/,file:///run/secrets/team-a/":
+// правила разделены ";", в каждом - пользователь (или * для всех) и разрешенные ему префиксы ссылок через ",".
+// Префикс, оканчивающийся на "/", разрешает все секреты в этом пути, иначе - только секрет с этим именем.
+// {user} заменяется на ID пользователя, которому проверяется доступ
+func ParseAccessPolicy(spec string) (*AccessPolicy, error) {
+	policy := &AccessPolicy{rules: make(map[string][]string)}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		userID, list, ok := strings.Cut(rule, "=")
+		userID = strings.TrimSpace(userID)
+		if !ok || userID == "" {
+			return nil, fmt.Errorf("invalid rule %q: expected user=prefix,prefix", rule)
+		}
+
+		for _, prefix := range strings.Split(list, ",") {
+			prefix = strings.TrimSpace(prefix)
+			if prefix == "" {
+				continue
+			}
+			normalized, err := normalizePrefix(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("invalid prefix %q for %s: %w", prefix, userID, err)
+			}
+			policy.rules[userID] = append(policy.rules[userID], normalized)
+		}
+	}
+	return policy, nil
+}
+
+// normalizePrefix проверяет префикс правила и приводит путь file:// к виду, в котором сравниваются ссылки
+func normalizePrefix(prefix string) (string, error) {
+	if strings.HasPrefix(prefix, SecretRefPrefix) {
+		return prefix, nil
+	}
+
+	path, ok := strings.CutPrefix(prefix, FileRefPrefix)
+	if !ok {
+		return "", fmt.Errorf("prefix must start with %s or %s", SecretRefPrefix, FileRefPrefix)
+	}
+	if !filepath.IsAbs(path) {
+		return "", errors.New("file prefix must contain an absolute path")
+	}
+
+	normalized := filepath.Clean(path)
+	if strings.HasSuffix(path, "/") && normalized != "/" {
+		normalized += "/"
+	}
+	return FileRefPrefix + normalized, nil
+}
+
+// IsEmpty сообщает, что в политике нет правил и ссылки на секреты запрещены всем
+func (p *AccessPolicy) IsEmpty() bool {
+	return p == nil || len(p.rules) == 0
+}
+
+// Allows сообщает, что пользователь может ссылаться на секрет. reference должна быть в каноническом виде
+// (см. Resolver.Authorize): путь file:// без "." и ".." сегментов
+func (p *AccessPolicy) Allows(userID, reference string) bool {
+	if p == nil || userID == "" {
+		return false
+	}
+
+	for _, ruleUser := range []string{userID, AllUsers} {
+		for _, prefix := range p.rules[ruleUser] {
+			if strings.Contains(prefix, userPlaceholder) {
+				// Подставляем только ID, который является одним сегментом пути: иначе пользователь "team"
+				// получил бы доступ к секретам пользователя "team/x"
+				if !isPathSegment(userID) {
+					continue
+				}
+				prefix = strings.ReplaceAll(prefix, userPlaceholder, userID)
+			}
+			if matchesPrefix(reference, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPrefix сообщает, что ссылка попадает под префикс: путь с завершающим "/" или точное имя секрета
+// (в том числе с полем #field)
+func matchesPrefix(reference, prefix string) bool {
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(reference, prefix)
+	}
+	return reference == prefix || strings.HasPrefix(reference, prefix+"#")
+}
+
+// isPathSegment сообщает, что значение можно использовать как один сегмент имени секрета
+func isPathSegment(value string) bool {
+	return validateSecretName(value) == nil && !strings.ContainsAny(value, "/#")
+}
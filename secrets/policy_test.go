@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// staticStore хранилище секретов с фиксированными значениями
+type staticStore map[string]string
+
+func (s staticStore) Get(ctx context.Context, name string) (string, error) {
+	value, ok := s[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func TestParseAccessPolicy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: ""},
+		{spec: "*=secretref://users/{user}/"},
+		{spec: " alice = secretref://team-a/ , file:///run/secrets/team-a/ ; bob=secretref://github.token"},
+		{spec: "alice", wantErr: true},
+		{spec: "=secretref://team-a/", wantErr: true},
+		{spec: "alice=vault://team-a/", wantErr: true},
+		{spec: "alice=file://relative/path", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseAccessPolicy(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAccessPolicy(%q): error %v, want error %v", tt.spec, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolverAuthorize(t *testing.T) {
+	dir := t.TempDir()
+	files, err := NewFileDir(dir)
+	if err != nil {
+		t.Fatalf("NewFileDir: %v", err)
+	}
+
+	policy, err := ParseAccessPolicy("*=secretref://users/{user}/;" +
+		"alice=secretref://team-a/,secretref://github.token,file://" + dir + "/team-a/")
+	if err != nil {
+		t.Fatalf("ParseAccessPolicy: %v", err)
+	}
+	resolver := NewResolver(staticStore{}, files, policy)
+
+	tests := []struct {
+		name      string
+		owners    []string
+		reference string
+		allowed   bool
+	}{
+		{name: "own namespace", owners: []string{"bob"}, reference: "secretref://users/bob/token", allowed: true},
+		{name: "other user's namespace", owners: []string{"bob"}, reference: "secretref://users/alice/token"},
+		{name: "team prefix", owners: []string{"alice"}, reference: "secretref://team-a/db#password", allowed: true},
+		{name: "team prefix of another user", owners: []string{"bob"}, reference: "secretref://team-a/db"},
+		{name: "prefix is not a path prefix", owners: []string{"alice"}, reference: "secretref://team-ab/db"},
+		{name: "exact secret", owners: []string{"alice"}, reference: "secretref://github.token", allowed: true},
+		{name: "exact secret with field", owners: []string{"alice"}, reference: "secretref://github.token#value", allowed: true},
+		{name: "exact secret is not a prefix", owners: []string{"alice"}, reference: "secretref://github.token2"},
+		{name: "dot segments", owners: []string{"alice"}, reference: "secretref://team-a/../team-b/db"},
+		{name: "empty segment", owners: []string{"alice"}, reference: "secretref://team-a//db"},
+		{name: "any of the owners", owners: []string{"bob", "alice"}, reference: "secretref://team-a/db", allowed: true},
+		{name: "no owners", reference: "secretref://team-a/db"},
+		{name: "user id is not a segment", owners: []string{"bob/x"}, reference: "secretref://users/bob/x/token"},
+		{name: "file in allowed directory", owners: []string{"alice"}, reference: "file://" + dir + "/team-a/key", allowed: true},
+		{name: "file escaping allowed directory", owners: []string{"alice"}, reference: "file://" + dir + "/team-a/../team-b/key"},
+		{name: "file of another user", owners: []string{"bob"}, reference: "file://" + dir + "/team-a/key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolver.Authorize(tt.reference, tt.owners...)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected %s to be allowed for %v, got %v", tt.reference, tt.owners, err)
+			}
+			if !tt.allowed && err == nil {
+				t.Fatalf("expected %s to be rejected for %v", tt.reference, tt.owners)
+			}
+		})
+	}
+}
+
+func TestResolverWithoutPolicyDeniesReferences(t *testing.T) {
+	resolver := NewResolver(staticStore{"team-a/db": "secret"}, nil, nil)
+
+	_, err := resolver.Resolve(context.Background(), "secretref://team-a/db", "alice")
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+
+	// Значения, не являющиеся ссылками, возвращаются как есть
+	value, err := resolver.Resolve(context.Background(), "raw-value", "alice")
+	if err != nil || value != "raw-value" {
+		t.Fatalf("expected raw value, got %q, %v", value, err)
+	}
+}
+
+func TestResolverResolve(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "team-a"), 0o700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "team-a", "key"), []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	files, err := NewFileDir(dir)
+	if err != nil {
+		t.Fatalf("NewFileDir: %v", err)
+	}
+	policy, err := ParseAccessPolicy("alice=secretref://team-a/,file://" + dir + "/team-a/")
+	if err != nil {
+		t.Fatalf("ParseAccessPolicy: %v", err)
+	}
+	resolver := NewResolver(staticStore{"team-a/db": "store-secret"}, files, policy)
+
+	for reference, want := range map[string]string{
+		"secretref://team-a/db":                  "store-secret",
+		"file://" + dir + "/team-a/key":          "file-secret",
+		"file://" + dir + "/team-a/./key":        "file-secret",
+		"file://localhost" + dir + "/team-a/key": "file-secret",
+	} {
+		value, err := resolver.Resolve(context.Background(), reference, "alice")
+		if err != nil || value != want {
+			t.Errorf("Resolve(%s): expected %q, got %q, %v", reference, want, value, err)
+		}
+	}
+}
+
+func TestVaultStoreRejectsDotSegments(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	store := NewVaultStore(server.URL, "token", "secret")
+	for _, name := range []string{"team-a/../team-b/db", "team-a/./db", "team-a//db", "/team-a/db", "team-a/db/", "..#value"} {
+		if _, err := store.Get(context.Background(), name); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): expected path error, got %v", name, err)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("expected no requests to vault, got %d", requests)
+	}
+}
@@ -0,0 +1,143 @@
+// Package secrets разрешает ссылки на секреты в credentials задач. Вместо самого секрета задача хранит ссылку
+// secretref://<name> (секрет из настроенного хранилища) или file:///path (файл из разрешенного каталога),
+// а значение подставляется только при выдаче задачи исполнителю
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Префиксы ссылок на секреты
+const (
+	SecretRefPrefix = "secretref://"
+	FileRefPrefix   = "file://"
+)
+
+// ErrNotFound секрет не найден в хранилище
+var ErrNotFound = errors.New("secret not found")
+
+// ErrUnavailable хранилище секретов временно недоступно: запрос можно повторить позже
+var ErrUnavailable = errors.New("secret store is unavailable")
+
+// Store хранилище именованных секретов, на которые ссылается secretref://<name>
+type Store interface {
+	// Get возвращает значение секрета. Если секрета нет, возвращает ошибку, оборачивающую ErrNotFound
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Resolver разрешает ссылки на секреты. Без хранилища ссылки secretref:// не принимаются,
+// без каталога файлов не принимаются ссылки file://, а без политики доступа ссылки запрещены всем пользователям
+type Resolver struct {
+	store  Store
+	files  *FileDir
+	policy *AccessPolicy
+}
+
+// NewResolver создает resolver поверх хранилища секретов, каталога файлов и политики доступа, любой из них может быть nil
+func NewResolver(store Store, files *FileDir, policy *AccessPolicy) *Resolver {
+	return &Resolver{store: store, files: files, policy: policy}
+}
+
+// IsReference сообщает, что значение является ссылкой на секрет, а не самим секретом
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix) || strings.HasPrefix(value, FileRefPrefix)
+}
+
+// Validate проверяет синтаксис ссылки и что её вид поддерживается конфигурацией, не обращаясь к самому секрету.
+// nil Resolver не поддерживает ни один вид ссылок
+func (r *Resolver) Validate(reference string) error {
+	if r == nil {
+		r = &Resolver{}
+	}
+
+	switch {
+	case strings.HasPrefix(reference, SecretRefPrefix):
+		if r.store == nil {
+			return errors.New("secretref:// references are not supported: SECRET_STORE is not configured")
+		}
+		return validateSecretName(strings.TrimPrefix(reference, SecretRefPrefix))
+	case strings.HasPrefix(reference, FileRefPrefix):
+		if r.files == nil {
+			return errors.New("file:// references are not supported: SECRET_FILE_DIR is not configured")
+		}
+		_, err := r.files.path(reference)
+		return err
+	default:
+		return fmt.Errorf("%q is not a secret reference", reference)
+	}
+}
+
+// Authorize проверяет ссылку и что политика доступа разрешает её хотя бы одному из владельцев credentials owners
+func (r *Resolver) Authorize(reference string, owners ...string) error {
+	if err := r.Validate(reference); err != nil {
+		return err
+	}
+
+	canonical := reference
+	if strings.HasPrefix(reference, FileRefPrefix) {
+		// Сравниваем путь без "." и "..", чтобы ссылка не вышла из разрешенного каталога
+		path, err := r.files.path(reference)
+		if err != nil {
+			return err
+		}
+		canonical = FileRefPrefix + path
+	}
+
+	for _, owner := range owners {
+		if r.policy.Allows(owner, canonical) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not permitted for %s by SECRET_ACCESS_POLICY", ErrAccessDenied, reference, strings.Join(owners, ", "))
+}
+
+// Resolve возвращает значение секрета, на который ссылается value, если политика доступа разрешает ссылку
+// одному из владельцев credentials owners. Значение, не являющееся ссылкой, возвращается как есть
+func (r *Resolver) Resolve(ctx context.Context, value string, owners ...string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	if err := r.Authorize(value, owners...); err != nil {
+		return "", err
+	}
+
+	if name, ok := strings.CutPrefix(value, SecretRefPrefix); ok {
+		return r.store.Get(ctx, name)
+	}
+	return r.files.Read(value)
+}
+
+// validateSecretName проверяет имя секрета: латинские буквы, цифры и символы _ - . / #.
+// Путь до #field не может содержать пустые сегменты и сегменты "." и "..", иначе ссылка обошла бы префикс политики доступа
+func validateSecretName(name string) error {
+	if name == "" {
+		return errors.New("secret name cannot be empty")
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("_-./#", r):
+		default:
+			return fmt.Errorf("secret name %q contains invalid character %q", name, r)
+		}
+	}
+
+	path, _, _ := strings.Cut(name, "#")
+	return validatePathSegments(path)
+}
+
+// validatePathSegments проверяет, что путь секрета не содержит пустых сегментов и сегментов "." и ".."
+func validatePathSegments(path string) error {
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "":
+			return fmt.Errorf("secret path %q contains an empty segment", path)
+		case ".", "..":
+			return fmt.Errorf("secret path %q contains a %q segment", path, segment)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// vaultRequestTimeout ограничивает запрос к Vault: секреты разрешаются в транзакции выдачи задачи
+const vaultRequestTimeout = 5 * time.Second
+
+// defaultVaultField поле секрета Vault, если в ссылке не указано #field
+const defaultVaultField = "value"
+
+// VaultStore хранилище секретов в движке KV v2 HashiCorp Vault или совместимом HTTP API.
+// Ссылка secretref://path/to/secret#field читает поле field секрета path/to/secret (по умолчанию поле value)
+type VaultStore struct {
+	addr   string
+	token  string
+	mount  string
+	client *http.Client
+}
+
+// NewVaultStore создает хранилище секретов Vault с адресом addr, токеном token и точкой монтирования KV v2 mount
+func NewVaultStore(addr, token, mount string) *VaultStore {
+	return &VaultStore{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		client: &http.Client{Timeout: vaultRequestTimeout},
+	}
+}
+
+// vaultResponse ответ Vault на чтение секрета KV v2
+type vaultResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// Get читает поле секрета через GET /v1/<mount>/data/<path>
+func (s *VaultStore) Get(ctx context.Context, name string) (string, error) {
+	path, field, _ := strings.Cut(name, "#")
+	if field == "" {
+		field = defaultVaultField
+	}
+	if path == "" {
+		return "", fmt.Errorf("vault secret path cannot be empty in %q", name)
+	}
+	// "." и ".." сегменты нормализуются HTTP клиентом и Vault и вывели бы ссылку за пределы её пути
+	if err := validatePathSegments(path); err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint := s.addr + "/v1/" + s.mount + "/data/" + strings.Join(segments, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: vault request failed: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault secret %s", ErrNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		// Перегрузка и ошибки сервера временные, остальные ответы (например, нет прав) требуют исправления конфигурации
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return "", err
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: invalid vault response: %w", ErrUnavailable, err)
+	}
+
+	value, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: field %s of vault secret %s", ErrNotFound, field, path)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %s of vault secret %s is not a string", field, path)
+	}
+	return str, nil
+}
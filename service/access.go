@@ -0,0 +1,52 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// GetAccessibleTask возвращает задачу, если пользователь является её создателем, исполнителем
+// или создателем корневой задачи дерева
+func (s *TaskService) GetAccessibleTask(ctx context.Context, userID string, taskID uuid.UUID) (*models.Task, error) {
+	task, err := findTask(ctx, s.repo, taskID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canAccessTask(ctx, s.repo, userID, task) {
+		return nil, newError(KindForbidden, "access denied: you are not the creator or assignee of this task")
+	}
+
+	return task, nil
+}
+
+// canAccessTask проверяет права на задачу: создатель, исполнитель или создатель корневой задачи
+func canAccessTask(ctx context.Context, repo TaskRepository, userID string, task *models.Task) bool {
+	if task.CreatedBy == userID || task.Assignee == userID {
+		return true
+	}
+	if task.RootTaskID != nil && *task.RootTaskID != task.ID {
+		if rootTask, err := repo.GetTask(ctx, *task.RootTaskID); err == nil {
+			return rootTask.CreatedBy == userID
+		}
+	}
+	return false
+}
+
+// History возвращает доступную пользователю задачу и журнал её переходов в порядке записи
+func (s *TaskService) History(ctx context.Context, userID string, taskID uuid.UUID) (*models.Task, []models.TaskEvent, error) {
+	task, err := s.GetAccessibleTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskEvents, err := s.repo.ListTaskEvents(ctx, task.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+
+	return task, taskEvents, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Cancel отменяет задачу и все её активные подзадачи от имени исполнителя или создателя
+func (s *TaskService) Cancel(ctx context.Context, userID string, taskID uuid.UUID) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+	var submitted []models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи или создателем
+		if task.Assignee != userID && task.CreatedBy != userID {
+			return newError(KindForbidden, "only assignee or creator can cancel the task")
+		}
+
+		// Проверяем текущий статус задачи - нельзя отменить уже завершенную или отмененную задачу
+		if task.Status == models.StatusCompleted || task.Status == models.StatusCanceled ||
+			task.Status == models.StatusFailed || task.Status == models.StatusDeadLettered ||
+			task.Status == models.StatusRejected {
+			return newError(KindInvalid, "cannot cancel task with status: "+string(task.Status)).
+				withField("current_status", task.Status)
+		}
+
+		// Обновляем задачу
+		previousStatus := task.Status
+		task.Status = models.StatusCanceled
+		task.LeaseExpiresAt = nil
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		canceledEvent := events.NewEvent(events.EventCanceled, *task, previousStatus, userID)
+		taskEvents, submitted, err = finishWithSubtasks(ctx, repo, task, canceledEvent, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	finishTransition(submitted, taskEvents)
+
+	return task, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// maxChildrenDepth максимальная глубина дерева подзадач в ответе на взятие задачи
+const maxChildrenDepth = 10
+
+// validateClaimOptions проверяет параметры ответа при взятии задачи
+func validateClaimOptions(opts ClaimOptions) error {
+	if opts.ChildrenDepth < 0 || opts.ChildrenDepth > maxChildrenDepth {
+		return newError(KindInvalid, fmt.Sprintf("children_depth must be between 0 and %d", maxChildrenDepth))
+	}
+	return nil
+}
+
+// loadChildren загружает подзадачи задачи всех статусов на depth уровней вниз.
+// Дерево читается одним запросом по root_task_id, подзадачи упорядочены по времени создания
+func loadChildren(ctx context.Context, repo TaskRepository, task *models.Task, depth int) ([]TaskNode, error) {
+	rootTaskID := task.ID
+	if task.RootTaskID != nil {
+		rootTaskID = *task.RootTaskID
+	}
+
+	treeTasks, err := repo.ListTreeTasks(ctx, rootTaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]models.Task)
+	for _, treeTask := range treeTasks {
+		if treeTask.ParentTaskID != nil {
+			children[*treeTask.ParentTaskID] = append(children[*treeTask.ParentTaskID], treeTask)
+		}
+	}
+	return buildTaskNodes(children, task.ID, depth), nil
+}
+
+// buildTaskNodes строит узлы подзадач parentID на depth уровней вниз.
+// Credentials подзадач не возвращаются (models.Task.Redacted): их создавали исполнители других уровней дерева
+func buildTaskNodes(children map[uuid.UUID][]models.Task, parentID uuid.UUID, depth int) []TaskNode {
+	subtasks := children[parentID]
+	if depth <= 0 || len(subtasks) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(subtasks, func(a, b models.Task) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	nodes := make([]TaskNode, len(subtasks))
+	for i, subtask := range subtasks {
+		nodes[i] = TaskNode{
+			Task:     subtask.Redacted(),
+			Children: buildTaskNodes(children, subtask.ID, depth-1),
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"agent-task-manager/notify"
+	"agent-task-manager/secrets"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// longPollRecheckInterval интервал повторной проверки при ожидании задачи.
+// Нужен для отложенных задач (run_at, not_before), о наступлении времени которых никто не оповещает
+const longPollRecheckInterval = 5 * time.Second
+
+// errCredentialsUnresolvable credentials задачи нельзя получить без вмешательства создателя: секрет не найден
+// или запрещен политикой доступа, ссылка невалидна, ключ шифрования удален
+var errCredentialsUnresolvable = errors.New("task credentials cannot be resolved")
+
+// errNoTasksAvailable ошибка, возвращаемая, когда у исполнителя нет доступных задач
+var errNoTasksAvailable = newError(KindNotFound, "no tasks available for assignment")
+
+// Claim атомарно берет в работу следующую доступную задачу исполнителя.
+// Если доступных задач нет, возвращает ошибку вида KindNotFound
+func (s *TaskService) Claim(ctx context.Context, userID string, opts ClaimOptions) (*TaskWithSubtasks, error) {
+	if err := validateClaimOptions(opts); err != nil {
+		return nil, err
+	}
+
+	response, err := s.claim(ctx, userID, opts)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errNoTasksAvailable
+	}
+	return response, err
+}
+
+// claim берет в работу задачу исполнителя. Если доступных задач нет, возвращает ErrNotFound
+func (s *TaskService) claim(ctx context.Context, userID string, opts ClaimOptions) (*TaskWithSubtasks, error) {
+	var response *TaskWithSubtasks
+	var taskEvents []events.Event
+	var submitted []models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Ищем задачу исполнителя в статусе submitted, время которой наступило.
+		// Строка блокируется, чтобы параллельные запросы одного исполнителя не получили одну и ту же задачу
+		now := time.Now()
+		var task *models.Task
+		var credentials json.RawMessage
+		var references []string
+		for task == nil {
+			candidate, err := repo.ClaimCandidate(ctx, userID, now, s.cfg.PriorityAgingInterval)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) && len(taskEvents) > 0 {
+					// Задач не осталось, но неудачи задач с неразрешимыми credentials нужно сохранить
+					return repo.RecordEvents(ctx, taskEvents)
+				}
+				if errors.Is(err, ErrNotFound) {
+					return err
+				}
+				return fmt.Errorf("failed to find task: %w", err)
+			}
+
+			// Credentials расшифровываются, а ссылки на секреты разрешаются только для ответа исполнителю
+			// и обратно в хранилище не попадают
+			credentials, references, err = s.claimCredentials(ctx, repo, candidate)
+			if err == nil {
+				task = candidate
+				break
+			}
+			if !errors.Is(err, errCredentialsUnresolvable) {
+				// Временная ошибка (хранилище секретов или база данных недоступны) - задача не выдается
+				return err
+			}
+
+			// Credentials не получить без вмешательства создателя: задача уходит в dead-letter,
+			// иначе она оставалась бы первой в очереди исполнителя при каждом запросе
+			failureEvents, resubmitted, err := deadLetterOnCredentials(ctx, repo, candidate, err)
+			if err != nil {
+				return err
+			}
+			taskEvents = append(taskEvents, failureEvents...)
+			submitted = append(submitted, resubmitted...)
+		}
+
+		// Меняем статус на working и выдаем аренду на LeaseTTL
+		previousStatus := task.Status
+		leaseExpiresAt := now.Add(s.cfg.LeaseTTL)
+		task.Status = models.StatusWorking
+		task.LeaseExpiresAt = &leaseExpiresAt
+		task.NotBefore = nil
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+
+		// Выдача credentials записывается в журнал в той же транзакции: без записи задача не выдается
+		if access := newCredentialAccess(task, credentials, references, opts, now); access != nil {
+			if err := repo.RecordCredentialAccess(ctx, access); err != nil {
+				return fmt.Errorf("failed to record credential access: %w", err)
+			}
+		}
+
+		// Загружаем завершенные подзадачи первого уровня
+		completedSubtasks, err := repo.ListSubtasks(ctx, task.ID, models.StatusCompleted)
+		if err != nil {
+			return fmt.Errorf("failed to load completed subtasks: %w", err)
+		}
+
+		// Загружаем отклоненные подзадачи первого уровня, чтобы исполнитель узнал причину отказа
+		rejectedSubtasks, err := repo.ListSubtasks(ctx, task.ID, models.StatusRejected)
+		if err != nil {
+			return fmt.Errorf("failed to load rejected subtasks: %w", err)
+		}
+
+		// Загружаем окончательно неудавшиеся подзадачи первого уровня с причиной неудачи в result
+		failedSubtasks, err := repo.ListSubtasks(ctx, task.ID, failedSubtaskStatuses...)
+		if err != nil {
+			return fmt.Errorf("failed to load failed subtasks: %w", err)
+		}
+
+		// Загружаем подзадачи всех статусов на запрошенную глубину
+		var children []TaskNode
+		if opts.ChildrenDepth > 0 {
+			children, err = loadChildren(ctx, repo, task, opts.ChildrenDepth)
+			if err != nil {
+				return fmt.Errorf("failed to load subtask tree: %w", err)
+			}
+		}
+
+		// Записываем журнал событий в той же транзакции
+		taskEvents = append(taskEvents, events.NewEvent(events.EventClaimed, *task, previousStatus, userID))
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+
+		response = &TaskWithSubtasks{
+			Task:              task.Redacted(),
+			Credentials:       credentials,
+			CompletedSubtasks: completedSubtasks,
+			RejectedSubtasks:  rejectedSubtasks,
+			FailedSubtasks:    failedSubtasks,
+			Children:          children,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, submittedTask := range submitted {
+		taskSubmitted(&submittedTask)
+	}
+	events.Publish(taskEvents...)
+
+	if response == nil {
+		return nil, ErrNotFound
+	}
+	return response, nil
+}
+
+// claimCredentials расшифровывает credentials задачи и разрешает ссылки на секреты.
+// Ошибки, которые не исчезнут без вмешательства создателя задачи, оборачивают errCredentialsUnresolvable
+func (s *TaskService) claimCredentials(ctx context.Context, repo TaskRepository, task *models.Task) (json.RawMessage, []string, error) {
+	credentials, err := openCredentials(s.cfg.CredentialsKeyring, task)
+	if err != nil {
+		// Ключ удален из CREDENTIALS_ENCRYPTION_KEYS или шифротекст поврежден
+		return nil, nil, fmt.Errorf("%w: failed to decrypt task credentials: %w", errCredentialsUnresolvable, err)
+	}
+
+	resolved, references, err := s.resolveCredentials(ctx, repo, task, credentials)
+	if err != nil {
+		if errors.Is(err, secrets.ErrUnavailable) || errors.Is(err, errCredentialOwners) || ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("failed to resolve task credentials: %w", err)
+		}
+		return nil, nil, fmt.Errorf("%w: failed to resolve task credentials: %w", errCredentialsUnresolvable, err)
+	}
+	return resolved, references, nil
+}
+
+// deadLetterOnCredentials переводит задачу, credentials которой нельзя получить, в dead-letter с причиной в result.
+// Создатель может исправить секрет или политику доступа и перезапустить задачу через redrive.
+// Как и при окончательной неудаче, к родителю применяется его политика on_child_failure, а зависимые задачи завершаются.
+// Возвращает события переходов и задачи, вернувшиеся в очередь
+func deadLetterOnCredentials(ctx context.Context, repo TaskRepository, task *models.Task, cause error) ([]events.Event, []models.Task, error) {
+	reason := cause.Error()
+	previousStatus := task.Status
+	task.Status = models.StatusDeadLettered
+	task.Result = "FAILURE REASON: " + reason
+	task.NotBefore = nil
+	task.LeaseExpiresAt = nil
+	if err := repo.SaveTask(ctx, task); err != nil {
+		return nil, nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	taskEvents := []events.Event{events.NewEvent(events.EventFailed, *task, previousStatus, events.ActorSystem)}
+
+	parentEvents, submitted, err := propagateChildFailure(ctx, repo, task, reason, events.ActorSystem)
+	if err != nil {
+		return nil, nil, err
+	}
+	taskEvents = append(taskEvents, parentEvents...)
+
+	settled, unblocked, err := settleDependents(ctx, repo, taskEvents, events.ActorSystem)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(taskEvents, settled...), append(submitted, unblocked...), nil
+}
+
+// WaitForTask берет в работу следующую задачу исполнителя. Если задач нет, ждет до wait (не больше LongPollMaxWait),
+// пока задача не появится. Без задач возвращает ошибку вида KindNotFound, при отмене ctx - ошибку контекста
+func (s *TaskService) WaitForTask(ctx context.Context, userID string, wait time.Duration, opts ClaimOptions) (*TaskWithSubtasks, error) {
+	if err := validateClaimOptions(opts); err != nil {
+		return nil, err
+	}
+	if wait > s.cfg.LongPollMaxWait {
+		wait = s.cfg.LongPollMaxWait
+	}
+
+	// Подписываемся до первой попытки, чтобы не пропустить оповещение между попыткой и ожиданием
+	var notifications <-chan struct{}
+	if wait > 0 {
+		ch, unsubscribe := notify.Subscribe(userID)
+		defer unsubscribe()
+		notifications = ch
+	}
+
+	deadline := time.After(wait)
+	recheck := time.NewTicker(longPollRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		response, err := s.claim(ctx, userID, opts)
+		if err == nil {
+			return response, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		if wait == 0 {
+			return nil, errNoTasksAvailable
+		}
+
+		// Ждем оповещения о новой задаче, периодической перепроверки, таймаута или отмены
+		select {
+		case <-notifications:
+		case <-recheck.C:
+		case <-deadline:
+			return nil, errNoTasksAvailable
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
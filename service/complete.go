@@ -0,0 +1,69 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Complete завершает задачу в работе от имени исполнителя, отменяет её активные подзадачи
+// и возвращает родителя в очередь, если все его подзадачи завершены
+func (s *TaskService) Complete(ctx context.Context, userID string, taskID uuid.UUID, req CompleteTaskRequest) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+	var submitted []models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи
+		if task.Assignee != userID {
+			return newError(KindForbidden, "only assignee can complete the task")
+		}
+
+		// Проверяем текущий статус задачи
+		if task.Status != models.StatusWorking {
+			return newError(KindInvalid, "task must be in working status to complete").
+				withField("current_status", task.Status)
+		}
+
+		// Структурированный результат должен соответствовать схеме задачи
+		if err := checkPayload(task.OutputSchema, req.Output, "output"); err != nil {
+			return err
+		}
+
+		// Обновляем задачу
+		previousStatus := task.Status
+		task.Status = models.StatusCompleted
+		task.Result = req.Description
+		task.Output = nullIfEmpty(req.Output)
+		task.LeaseExpiresAt = nil
+		if req.DeleteAt != nil {
+			task.DeleteAt = req.DeleteAt
+		}
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		completedEvent := events.NewEvent(events.EventCompleted, *task, previousStatus, userID)
+		// Задачи, ожидавшие завершения этой задачи, возвращаются в очередь, если больше ничего не ждут
+		taskEvents, submitted, err = finishWithSubtasks(ctx, repo, task, completedEvent, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	finishTransition(submitted, taskEvents)
+
+	return task, nil
+}
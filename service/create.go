@@ -0,0 +1,274 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Create создает задачу от имени пользователя: проверяет запрос, переводит родителя в waiting,
+// записывает журнал событий и оповещает исполнителя
+func (s *TaskService) Create(ctx context.Context, userID string, req CreateTaskRequest) (*models.Task, error) {
+	// Валидация Credentials
+	credentials, err := s.validateCredentialsRequest(userID, req.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	// Валидация наследуемых credentials: наследовать можно только у родителя
+	inheritServices, err := normalizeInheritedServices(req.InheritCredentials)
+	if err != nil {
+		return nil, newError(KindInvalid, "invalid inherit_credentials: "+err.Error())
+	}
+	if len(inheritServices) > 0 && req.ParentTaskID == nil {
+		return nil, newError(KindInvalid, "inherit_credentials requires parent_task_id")
+	}
+
+	// Валидация политики повторных попыток
+	retryPolicy, err := validateRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		return nil, newError(KindInvalid, "invalid retry policy: "+err.Error())
+	}
+
+	// Валидация приоритета
+	if err := ValidatePriority(req.Priority); err != nil {
+		return nil, newError(KindInvalid, "invalid priority: "+err.Error())
+	}
+
+	// Валидация входных данных по схеме и схемы результата
+	if err := checkPayload(req.InputSchema, req.Input, "input"); err != nil {
+		return nil, err
+	}
+	if hasPayload(req.OutputSchema) {
+		if _, err := compileSchema(req.OutputSchema); err != nil {
+			return nil, newError(KindInvalid, "invalid output_schema: "+errorLine(err))
+		}
+	}
+
+	// Валидация реакции на неудачу подзадачи
+	onChildFailure, err := validateChildFailurePolicy(req.OnChildFailure)
+	if err != nil {
+		return nil, newError(KindInvalid, "invalid on_child_failure: "+err.Error())
+	}
+
+	// Валидация зависимостей
+	dependsOn, err := normalizeDependencies(req.DependsOn)
+	if err != nil {
+		return nil, newError(KindInvalid, "invalid depends_on: "+err.Error())
+	}
+
+	// Устанавливаем DeleteAt по умолчанию на +3 месяца, если не указано
+	deleteAt := req.DeleteAt
+	if deleteAt == nil {
+		threeMonthsLater := time.Now().AddDate(0, 3, 0)
+		deleteAt = &threeMonthsLater
+	}
+
+	// Создаем задачу. ID генерируется заранее, чтобы корневая задача ссылалась на себя уже при вставке
+	task := &models.Task{
+		ID:           uuid.New(),
+		CreatedBy:    userID,
+		Description:  req.Description,
+		Assignee:     req.Assignee,
+		ParentTaskID: req.ParentTaskID,
+		DeleteAt:     deleteAt,
+		RunAt:        req.RunAt,
+		Status:       models.StatusSubmitted,
+
+		OnChildFailure: onChildFailure,
+
+		Input:        nullIfEmpty(req.Input),
+		InputSchema:  nullIfEmpty(req.InputSchema),
+		OutputSchema: nullIfEmpty(req.OutputSchema),
+
+		MaxAttempts:        retryPolicy.MaxAttempts,
+		BackoffBaseSeconds: retryPolicy.BackoffBaseSeconds,
+		MaxDelaySeconds:    retryPolicy.MaxDelaySeconds,
+	}
+
+	// ID задачи, заданный вызывающей стороной
+	if req.TaskID != nil {
+		task.ID = *req.TaskID
+	}
+
+	// Явно указанный приоритет
+	if req.Priority != nil {
+		task.Priority = *req.Priority
+	}
+
+	var taskEvents []events.Event
+
+	// Задача, обновление родителя и журнал событий пишутся атомарно
+	err = s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Родительская задача, если подзадача создается для существующей задачи
+		var parentTask *models.Task
+		var err error
+
+		// Если есть ParentTaskID, нужно получить RootTaskID из родительской задачи.
+		// Родитель блокируется до конца транзакции: параллельное завершение или отмена родителя
+		// не должны вернуть его в waiting
+		if req.ParentTaskID != nil {
+			parentTask, err = repo.LockTask(ctx, *req.ParentTaskID)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					return newError(KindInvalid, "parent task not found")
+				}
+				return fmt.Errorf("failed to get parent task: %w", err)
+			}
+
+			// Проверяем, что parent задача находится в разрешенном статусе
+			if !isParentStatusAllowed(parentTask.Status) {
+				return newError(KindInvalid, "parent task must be in waiting, working or submitted status").
+					withField("parent_status", parentTask.Status)
+			}
+
+			// Если приоритет не указан явно, подзадача наследует приоритет родителя
+			if req.Priority == nil {
+				task.Priority = parentTask.Priority
+			}
+
+			// Устанавливаем RootTaskID из родительской задачи
+			task.RootTaskID = parentTask.RootTaskID
+			// Если у родительской задачи нет RootTaskID, используем ID родительской задачи
+			if task.RootTaskID == nil {
+				task.RootTaskID = &parentTask.ID
+			}
+
+			// Подзадача получает выбранные сервисы из credentials родителя
+			if len(inheritServices) > 0 {
+				credentials, err = s.inheritCredentials(userID, parentTask, credentials, inheritServices)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			// Корневая задача является корнем своего дерева
+			task.RootTaskID = &task.ID
+		}
+
+		// Credentials шифруются после того, как ID задачи окончательно определен: шифротекст привязан к нему
+		if err := SealCredentials(s.cfg.CredentialsKeyring, task, credentials); err != nil {
+			return fmt.Errorf("failed to encrypt credentials: %w", err)
+		}
+
+		// Пока зависимости не завершены, задача ожидает в статусе waiting
+		if len(dependsOn) > 0 {
+			pending, err := checkDependencies(ctx, repo, userID, task, dependsOn)
+			if err != nil {
+				return err
+			}
+			if pending {
+				task.Status = models.StatusWaiting
+			}
+		}
+
+		// Создаем задачу в хранилище
+		if err := repo.CreateTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		if err := repo.AddDependencies(ctx, task.ID, dependsOn); err != nil {
+			return fmt.Errorf("failed to save task dependencies: %w", err)
+		}
+		task.DependsOn = dependsOn
+
+		taskEvents = []events.Event{events.NewEvent(events.EventCreated, *task, "", userID)}
+
+		// Если есть parent, переводим его в статус waiting и снимаем аренду:
+		// пока подзадачи не завершены, исполнитель родителя не обязан слать heartbeat.
+		// Создание еще одной подзадачи для уже ожидающего родителя не является переходом
+		if parentTask != nil && parentTask.Status != models.StatusWaiting {
+			if err := repo.UpdateStatus(ctx, []uuid.UUID{parentTask.ID}, models.StatusWaiting); err != nil {
+				return fmt.Errorf("failed to update parent task status: %w", err)
+			}
+
+			previousStatus := parentTask.Status
+			parentTask.Status = models.StatusWaiting
+			parentTask.LeaseExpiresAt = nil
+			taskEvents = append(taskEvents, events.NewEvent(events.EventWaiting, *parentTask, previousStatus, userID))
+		}
+
+		// Записываем журнал событий в той же транзакции
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskCreated(task)
+	events.Publish(taskEvents...)
+
+	return task, nil
+}
+
+// validateCredentialsRequest проверяет credentials новой задачи создателя owner и возвращает их, а без credentials - пустой объект
+func (s *TaskService) validateCredentialsRequest(owner string, credentials json.RawMessage) (json.RawMessage, error) {
+	if len(credentials) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+
+	// Проверяем структуру credentials
+	// Ожидаемая структура: { "service": { "ENV_VAR": "value" } }
+	if err := ValidateCredentialsPayload(credentials); err != nil {
+		return nil, newError(KindInvalid, err.Error())
+	}
+
+	// Ссылки на секреты только проверяются: секреты подставляются при выдаче задачи исполнителю
+	if err := CheckCredentialReferences(s.cfg, owner, credentials); err != nil {
+		return nil, newError(KindInvalid, "invalid credentials: "+err.Error())
+	}
+
+	return credentials, nil
+}
+
+// createSystemTask создает корневую задачу от имени системы (например, запуск периодической задачи) в транзакции repo
+// по тем же правилам, что и Create: проверяет credentials и приоритет, делает задачу корнем своего дерева,
+// шифрует credentials и записывает событие создания. Действия после коммита выполняет вызывающая сторона
+func (s *TaskService) createSystemTask(ctx context.Context, repo TaskRepository, task *models.Task, credentials json.RawMessage) (events.Event, error) {
+	credentials, err := s.validateCredentialsRequest(task.CreatedBy, credentials)
+	if err != nil {
+		return events.Event{}, err
+	}
+	if err := ValidatePriority(&task.Priority); err != nil {
+		return events.Event{}, newError(KindInvalid, "invalid priority: "+err.Error())
+	}
+
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+	task.ParentTaskID = nil
+	task.RootTaskID = &task.ID
+	task.Status = models.StatusSubmitted
+	if task.DeleteAt == nil {
+		deleteAt := time.Now().AddDate(0, 3, 0)
+		task.DeleteAt = &deleteAt
+	}
+
+	if err := SealCredentials(s.cfg.CredentialsKeyring, task, credentials); err != nil {
+		return events.Event{}, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	if err := repo.CreateTask(ctx, task); err != nil {
+		return events.Event{}, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	taskEvents := []events.Event{events.NewEvent(events.EventCreated, *task, "", events.ActorSystem)}
+	if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+		return events.Event{}, fmt.Errorf("failed to record task events: %w", err)
+	}
+	return taskEvents[0], nil
+}
+
+// taskCreated добавляет исполнителя новой задачи в кэш после коммита, если задача в статусе submitted
+func taskCreated(task *models.Task) {
+	if task.Status == models.StatusSubmitted {
+		taskSubmitted(task)
+	}
+}
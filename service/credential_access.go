@@ -0,0 +1,95 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения размера выборки журнала выдачи credentials
+const (
+	defaultCredentialAccessLimit = 100
+	maxCredentialAccessLimit     = 1000
+)
+
+// newCredentialAccess создает запись журнала о выдаче credentials задачи исполнителю.
+// Возвращает nil, если у задачи нет credentials
+func newCredentialAccess(task *models.Task, credentials json.RawMessage, references []string, opts ClaimOptions, now time.Time) *models.CredentialAccess {
+	credsMap, err := validateCredentials(credentials)
+	if err != nil || len(credsMap) == 0 {
+		return nil
+	}
+
+	rootTaskID := task.ID
+	if task.RootTaskID != nil {
+		rootTaskID = *task.RootTaskID
+	}
+
+	return &models.CredentialAccess{
+		AccessedAt:    now,
+		TaskID:        task.ID,
+		RootTaskID:    rootTaskID,
+		TaskCreatedBy: task.CreatedBy,
+		Assignee:      task.Assignee,
+		Services:      slices.Sorted(maps.Keys(credsMap)),
+		References:    references,
+		ClientIP:      opts.ClientIP,
+		Protocol:      opts.Protocol,
+	}
+}
+
+// validateCredentialAccessFilter проверяет и дополняет фильтр журнала: лимит по умолчанию и его максимум
+func validateCredentialAccessFilter(filter *CredentialAccessFilter) error {
+	if filter.Limit < 0 || filter.Limit > maxCredentialAccessLimit {
+		return newError(KindInvalid, fmt.Sprintf("limit must be between 1 and %d", maxCredentialAccessLimit))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultCredentialAccessLimit
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return newError(KindInvalid, "until must be after since")
+	}
+	return nil
+}
+
+// CredentialAccessLog возвращает записи журнала выдачи credentials по фильтру, начиная с последних.
+// Администраторы (ADMIN_USERS) видят записи всех задач, остальные пользователи - только задач, которые они создали
+func (s *TaskService) CredentialAccessLog(ctx context.Context, userID string, filter CredentialAccessFilter) ([]models.CredentialAccess, error) {
+	if err := validateCredentialAccessFilter(&filter); err != nil {
+		return nil, err
+	}
+	if !s.cfg.IsAdmin(userID) {
+		filter.TaskCreatedBy = userID
+	}
+
+	accesses, err := s.repo.ListCredentialAccess(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential access log: %w", err)
+	}
+	return accesses, nil
+}
+
+// TaskCredentialAccess возвращает журнал выдачи credentials задачи, начиная с последних записей.
+// Доступен создателю задачи и администраторам, в том числе после удаления задачи
+func (s *TaskService) TaskCredentialAccess(ctx context.Context, userID string, taskID uuid.UUID, limit int) ([]models.CredentialAccess, error) {
+	accesses, err := s.CredentialAccessLog(ctx, userID, CredentialAccessFilter{TaskID: &taskID, Limit: limit})
+	if err != nil || len(accesses) > 0 || s.cfg.IsAdmin(userID) {
+		return accesses, err
+	}
+
+	// Записей создателя нет: различаем чужую задачу, задачу без выдач и несуществующую задачу
+	task, err := findTask(ctx, s.repo, taskID, false)
+	if err != nil {
+		return nil, err
+	}
+	if task.CreatedBy != userID {
+		return nil, newError(KindForbidden, "access denied: only the task creator and admins can view its credential access log")
+	}
+	return accesses, nil
+}
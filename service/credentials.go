@@ -0,0 +1,391 @@
+package service
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/encryption"
+	"agent-task-manager/models"
+	"agent-task-manager/secrets"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// defaultReencryptBatchSize количество задач, перешифровываемых в одной транзакции
+const defaultReencryptBatchSize = 100
+
+// hasCredentials сообщает, что в credentials есть хотя бы один сервис
+func hasCredentials(credentials json.RawMessage) bool {
+	credsMap, err := validateCredentials(credentials)
+	return err != nil || len(credsMap) > 0
+}
+
+// SealCredentials записывает credentials в задачу. Если ключи шифрования заданы, credentials шифруются
+// активным ключом и в открытом поле не остаются; пустые credentials и credentials без ключей хранятся как есть.
+// ID задачи должен быть уже задан: шифротекст привязан к нему и не расшифруется в другой записи
+func SealCredentials(keyring *encryption.Keyring, task *models.Task, credentials json.RawMessage) error {
+	if keyring == nil || !hasCredentials(credentials) {
+		task.Credentials = credentials
+		return nil
+	}
+
+	envelope, err := keyring.Seal(credentials, task.ID[:])
+	if err != nil {
+		return err
+	}
+
+	task.Credentials = nil
+	task.CredentialsKeyID = envelope.KeyID
+	task.CredentialsEncryptedKey = envelope.EncryptedKey
+	task.CredentialsCiphertext = envelope.Ciphertext
+	return nil
+}
+
+// credentialsEnvelope возвращает зашифрованные credentials задачи, nil - credentials хранятся в открытом виде
+func credentialsEnvelope(task *models.Task) *encryption.Envelope {
+	if len(task.CredentialsCiphertext) == 0 {
+		return nil
+	}
+	return &encryption.Envelope{
+		KeyID:        task.CredentialsKeyID,
+		EncryptedKey: task.CredentialsEncryptedKey,
+		Ciphertext:   task.CredentialsCiphertext,
+	}
+}
+
+// openCredentials возвращает расшифрованные credentials задачи.
+// Задачи, созданные до включения шифрования, хранят credentials в открытом виде
+func openCredentials(keyring *encryption.Keyring, task *models.Task) (json.RawMessage, error) {
+	envelope := credentialsEnvelope(task)
+	if envelope == nil {
+		return task.Credentials, nil
+	}
+	if keyring == nil {
+		return nil, errors.New("credentials are encrypted but CREDENTIALS_ENCRYPTION_KEYS is not set")
+	}
+
+	credentials, err := keyring.Open(envelope, task.ID[:])
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(credentials), nil
+}
+
+// recurringCredentialsAD возвращает связанные данные шифротекста credentials периодической задачи.
+// Префикс отличает их от ID задачи, чтобы шифротекст нельзя было перенести между таблицами
+func recurringCredentialsAD(id uuid.UUID) []byte {
+	return append([]byte("recurring_task:"), id[:]...)
+}
+
+// SealRecurringCredentials записывает credentials в периодическую задачу по тем же правилам, что и SealCredentials,
+// заменяя прежние открытые и зашифрованные credentials. ID периодической задачи должен быть уже задан
+func SealRecurringCredentials(keyring *encryption.Keyring, recurringTask *models.RecurringTask, credentials json.RawMessage) error {
+	recurringTask.Credentials = credentials
+	recurringTask.CredentialsKeyID = ""
+	recurringTask.CredentialsEncryptedKey = nil
+	recurringTask.CredentialsCiphertext = nil
+	if keyring == nil || !hasCredentials(credentials) {
+		return nil
+	}
+
+	envelope, err := keyring.Seal(credentials, recurringCredentialsAD(recurringTask.ID))
+	if err != nil {
+		return err
+	}
+
+	recurringTask.Credentials = nil
+	recurringTask.CredentialsKeyID = envelope.KeyID
+	recurringTask.CredentialsEncryptedKey = envelope.EncryptedKey
+	recurringTask.CredentialsCiphertext = envelope.Ciphertext
+	return nil
+}
+
+// recurringCredentialsEnvelope возвращает зашифрованные credentials периодической задачи, nil - credentials открытые
+func recurringCredentialsEnvelope(recurringTask *models.RecurringTask) *encryption.Envelope {
+	if len(recurringTask.CredentialsCiphertext) == 0 {
+		return nil
+	}
+	return &encryption.Envelope{
+		KeyID:        recurringTask.CredentialsKeyID,
+		EncryptedKey: recurringTask.CredentialsEncryptedKey,
+		Ciphertext:   recurringTask.CredentialsCiphertext,
+	}
+}
+
+// openRecurringCredentials возвращает расшифрованные credentials периодической задачи
+func openRecurringCredentials(keyring *encryption.Keyring, recurringTask *models.RecurringTask) (json.RawMessage, error) {
+	envelope := recurringCredentialsEnvelope(recurringTask)
+	if envelope == nil {
+		return recurringTask.Credentials, nil
+	}
+	if keyring == nil {
+		return nil, errors.New("credentials are encrypted but CREDENTIALS_ENCRYPTION_KEYS is not set")
+	}
+
+	credentials, err := keyring.Open(envelope, recurringCredentialsAD(recurringTask.ID))
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(credentials), nil
+}
+
+// CheckCredentialReferences проверяет ссылки на секреты (secretref://, file://) в credentials, не разрешая их:
+// синтаксис ссылки и что политика доступа разрешает её владельцу credentials owner (создателю задачи).
+// Если включен RequireCredentialReferences, каждое значение должно быть ссылкой
+func CheckCredentialReferences(cfg *config.Config, owner string, credentials json.RawMessage) error {
+	credsMap, err := validateCredentials(credentials)
+	if err != nil {
+		return err
+	}
+
+	for _, serviceName := range slices.Sorted(maps.Keys(credsMap)) {
+		serviceVars := credsMap[serviceName]
+		for _, envVar := range slices.Sorted(maps.Keys(serviceVars)) {
+			value := serviceVars[envVar]
+			if !secrets.IsReference(value) {
+				if cfg.RequireCredentialReferences {
+					return fmt.Errorf("%s.%s: raw secret values are not allowed, use a secretref:// or file:// reference", serviceName, envVar)
+				}
+				continue
+			}
+			if err := cfg.SecretResolver.Authorize(value, owner); err != nil {
+				return fmt.Errorf("%s.%s: %w", serviceName, envVar, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveCredentials подставляет значения секретов вместо ссылок в credentials задачи и возвращает разрешенные ссылки
+// в алфавитном порядке. Политика доступа проверяется повторно: каждая ссылка должна быть разрешена
+// одному из владельцев credentials (см. credentialOwners). Credentials без ссылок возвращаются как есть
+func (s *TaskService) resolveCredentials(ctx context.Context, repo TaskRepository, task *models.Task, credentials json.RawMessage) (json.RawMessage, []string, error) {
+	credsMap, err := validateCredentials(credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var owners []string
+	var references []string
+	for serviceName, serviceVars := range credsMap {
+		for envVar, value := range serviceVars {
+			if !secrets.IsReference(value) {
+				continue
+			}
+			if owners == nil {
+				owners, err = credentialOwners(ctx, repo, task)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			secret, err := s.cfg.SecretResolver.Resolve(ctx, value, owners...)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to resolve %s.%s: %w", serviceName, envVar, err)
+			}
+			serviceVars[envVar] = secret
+			references = append(references, value)
+		}
+	}
+
+	if len(references) == 0 {
+		return credentials, nil, nil
+	}
+	slices.Sort(references)
+	resolved, err := json.Marshal(credsMap)
+	return resolved, slices.Compact(references), err
+}
+
+// errCredentialOwners не удалось загрузить предков задачи для проверки политики доступа к секретам
+var errCredentialOwners = errors.New("failed to get parent task")
+
+// credentialOwners возвращает владельцев credentials задачи: её создателя и создателей её предков.
+// Ссылки, унаследованные через inherit_credentials, были разрешены создателю одного из предков
+func credentialOwners(ctx context.Context, repo TaskRepository, task *models.Task) ([]string, error) {
+	owners := []string{task.CreatedBy}
+	for parentID := task.ParentTaskID; parentID != nil; {
+		parent, err := repo.GetTask(ctx, *parentID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errCredentialOwners, err)
+		}
+		if !slices.Contains(owners, parent.CreatedBy) {
+			owners = append(owners, parent.CreatedBy)
+		}
+		parentID = parent.ParentTaskID
+	}
+	return owners, nil
+}
+
+// normalizeInheritedServices сортирует список наследуемых сервисов и удаляет повторы
+func normalizeInheritedServices(services []string) ([]string, error) {
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	normalized := slices.Clone(services)
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	if slices.Contains(normalized, "") {
+		return nil, errors.New("service name cannot be empty")
+	}
+	return normalized, nil
+}
+
+// inheritCredentials добавляет к credentials подзадачи сервисы services из credentials родителя.
+// Наследует только исполнитель или создатель родителя: это они распоряжаются его credentials.
+// Ссылки на секреты копируются как есть и разрешаются при выдаче подзадачи
+func (s *TaskService) inheritCredentials(userID string, parent *models.Task, credentials json.RawMessage, services []string) (json.RawMessage, error) {
+	if userID != parent.Assignee && userID != parent.CreatedBy {
+		return nil, newError(KindForbidden, "only the parent task's assignee or creator can grant its credentials")
+	}
+
+	parentCredentials, err := openCredentials(s.cfg.CredentialsKeyring, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt parent task credentials: %w", err)
+	}
+	parentMap, err := validateCredentials(parentCredentials)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent task credentials: %w", err)
+	}
+	credsMap, err := validateCredentials(credentials)
+	if err != nil {
+		return nil, newError(KindInvalid, "invalid credentials format: "+err.Error())
+	}
+	if credsMap == nil {
+		credsMap = make(map[string]map[string]string, len(services))
+	}
+
+	for _, serviceName := range services {
+		serviceVars, ok := parentMap[serviceName]
+		if !ok {
+			return nil, newError(KindInvalid, "parent task has no credentials for inherited service").
+				withField("service", serviceName)
+		}
+		if _, ok := credsMap[serviceName]; ok {
+			return nil, newError(KindInvalid, "service is given both in credentials and inherit_credentials").
+				withField("service", serviceName)
+		}
+		credsMap[serviceName] = serviceVars
+	}
+	return json.Marshal(credsMap)
+}
+
+// ReencryptResult итог перешифрования credentials
+type ReencryptResult struct {
+	Rewrapped          int `json:"rewrapped"`           // Задачи, ключ данных которых перешифрован активным ключом
+	Encrypted          int `json:"encrypted"`           // Задачи с открытыми credentials, которые были зашифрованы
+	RecurringRewrapped int `json:"recurring_rewrapped"` // Периодические задачи, ключ данных которых перешифрован
+	RecurringEncrypted int `json:"recurring_encrypted"` // Периодические задачи с открытыми credentials, которые были зашифрованы
+}
+
+// add добавляет к итогу счетчики партии
+func (r *ReencryptResult) add(batch ReencryptResult) {
+	r.Rewrapped += batch.Rewrapped
+	r.Encrypted += batch.Encrypted
+	r.RecurringRewrapped += batch.RecurringRewrapped
+	r.RecurringEncrypted += batch.RecurringEncrypted
+}
+
+// ReencryptCredentials переводит credentials всех задач и периодических задач на активный ключ партиями по batchSize записей:
+// ключи данных, зашифрованные прежними ключами, перешифровываются (сами credentials не меняются),
+// а открытые credentials записей, созданных до включения шифрования, шифруются.
+// Прежние ключи должны оставаться в CREDENTIALS_ENCRYPTION_KEYS до завершения перешифрования
+func (s *TaskService) ReencryptCredentials(ctx context.Context, batchSize int) (ReencryptResult, error) {
+	var result ReencryptResult
+
+	keyring := s.cfg.CredentialsKeyring
+	if keyring == nil {
+		return result, errors.New("CREDENTIALS_ENCRYPTION_KEYS is not set")
+	}
+	if batchSize <= 0 {
+		batchSize = defaultReencryptBatchSize
+	}
+
+	err := s.reencryptInBatches(ctx, batchSize, &result, func(repo TaskRepository, batch *ReencryptResult) (int, error) {
+		tasks, err := repo.ListCredentialsToReencrypt(ctx, keyring.ActiveKeyID(), batchSize)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list tasks to re-encrypt: %w", err)
+		}
+
+		for i := range tasks {
+			task := &tasks[i]
+			if envelope := credentialsEnvelope(task); envelope != nil {
+				if _, err := keyring.Rewrap(envelope, task.ID[:]); err != nil {
+					return 0, fmt.Errorf("failed to re-encrypt credentials of task %s: %w", task.ID, err)
+				}
+				task.CredentialsKeyID = envelope.KeyID
+				task.CredentialsEncryptedKey = envelope.EncryptedKey
+				batch.Rewrapped++
+			} else {
+				if err := SealCredentials(keyring, task, task.Credentials); err != nil {
+					return 0, fmt.Errorf("failed to encrypt credentials of task %s: %w", task.ID, err)
+				}
+				batch.Encrypted++
+			}
+
+			if err := repo.SaveCredentials(ctx, task); err != nil {
+				return 0, fmt.Errorf("failed to save credentials of task %s: %w", task.ID, err)
+			}
+		}
+		return len(tasks), nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = s.reencryptInBatches(ctx, batchSize, &result, func(repo TaskRepository, batch *ReencryptResult) (int, error) {
+		recurringTasks, err := repo.ListRecurringCredentialsToReencrypt(ctx, keyring.ActiveKeyID(), batchSize)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list recurring tasks to re-encrypt: %w", err)
+		}
+
+		for i := range recurringTasks {
+			recurringTask := &recurringTasks[i]
+			if envelope := recurringCredentialsEnvelope(recurringTask); envelope != nil {
+				if _, err := keyring.Rewrap(envelope, recurringCredentialsAD(recurringTask.ID)); err != nil {
+					return 0, fmt.Errorf("failed to re-encrypt credentials of recurring task %s: %w", recurringTask.ID, err)
+				}
+				recurringTask.CredentialsKeyID = envelope.KeyID
+				recurringTask.CredentialsEncryptedKey = envelope.EncryptedKey
+				batch.RecurringRewrapped++
+			} else {
+				if err := SealRecurringCredentials(keyring, recurringTask, recurringTask.Credentials); err != nil {
+					return 0, fmt.Errorf("failed to encrypt credentials of recurring task %s: %w", recurringTask.ID, err)
+				}
+				batch.RecurringEncrypted++
+			}
+
+			if err := repo.SaveRecurringCredentials(ctx, recurringTask); err != nil {
+				return 0, fmt.Errorf("failed to save credentials of recurring task %s: %w", recurringTask.ID, err)
+			}
+		}
+		return len(recurringTasks), nil
+	})
+	return result, err
+}
+
+// reencryptInBatches выполняет reencrypt в отдельных транзакциях, пока очередная партия не окажется неполной.
+// Счетчики партии попадают в result только после коммита её транзакции
+func (s *TaskService) reencryptInBatches(ctx context.Context, batchSize int, result *ReencryptResult, reencrypt func(repo TaskRepository, batch *ReencryptResult) (int, error)) error {
+	for {
+		var batch ReencryptResult
+		var processed int
+		err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+			var err error
+			processed, err = reencrypt(repo, &batch)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		result.add(batch)
+		if processed < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// maxDependencies максимальное количество зависимостей одной задачи
+const maxDependencies = 100
+
+// failedDependencyStatuses финальные статусы зависимости, после которых зависящая задача уже не может продолжить работу
+var failedDependencyStatuses = []models.TaskStatus{
+	models.StatusFailed,
+	models.StatusDeadLettered,
+	models.StatusCanceled,
+	models.StatusRejected,
+}
+
+// normalizeDependencies убирает повторы из списка зависимостей и упорядочивает его,
+// чтобы параллельные транзакции блокировали задачи в одном порядке
+func normalizeDependencies(dependsOn []uuid.UUID) ([]uuid.UUID, error) {
+	if len(dependsOn) == 0 {
+		return nil, nil
+	}
+
+	normalized := slices.Clone(dependsOn)
+	slices.SortFunc(normalized, func(a, b uuid.UUID) int {
+		return slices.Compare(a[:], b[:])
+	})
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) > maxDependencies {
+		return nil, fmt.Errorf("a task can depend on at most %d tasks", maxDependencies)
+	}
+	if slices.Contains(normalized, uuid.Nil) {
+		return nil, errors.New("dependency task id cannot be empty")
+	}
+	return normalized, nil
+}
+
+// checkDependencies проверяет зависимости новой задачи: задачи существуют, доступны пользователю,
+// не завершились неудачей и не образуют цикл ожидания с задачей и её родителем. Задачи зависимостей блокируются до конца транзакции,
+// чтобы их параллельное завершение не пропустило новую задачу.
+// Возвращает true, если среди зависимостей есть незавершенные и задача должна ждать
+func checkDependencies(ctx context.Context, repo TaskRepository, userID string, task *models.Task, dependsOn []uuid.UUID) (bool, error) {
+	pending := false
+	for _, dependsOnID := range dependsOn {
+		if dependsOnID == task.ID {
+			return false, newError(KindInvalid, "task cannot depend on itself")
+		}
+
+		dependency, err := repo.LockTask(ctx, dependsOnID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return false, newError(KindInvalid, "dependency task not found").
+					withField("depends_on", dependsOnID)
+			}
+			return false, fmt.Errorf("failed to get dependency task: %w", err)
+		}
+
+		if !canAccessTask(ctx, repo, userID, dependency) {
+			return false, newError(KindForbidden, "access denied: you are not the creator or assignee of the dependency task").
+				withField("depends_on", dependsOnID)
+		}
+
+		// Задача, зависящая от неудавшейся зависимости, никогда бы не дождалась её завершения
+		if slices.Contains(failedDependencyStatuses, dependency.Status) {
+			return false, newError(KindInvalid, "dependency task has already ended without completing: "+string(dependency.Status)).
+				withField("depends_on", dependsOnID).
+				withField("dependency_status", dependency.Status)
+		}
+
+		if dependency.Status != models.StatusCompleted {
+			pending = true
+		}
+	}
+
+	// Родитель ждет свои подзадачи, поэтому новая задача не может зависеть от задачи,
+	// которая сама (через зависимости или подзадачи) ждет родителя или новую задачу
+	blocked := []uuid.UUID{task.ID}
+	if task.ParentTaskID != nil {
+		blocked = append(blocked, *task.ParentTaskID)
+	}
+	cycle, err := waitsForAny(ctx, repo, dependsOn, blocked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check dependency cycle: %w", err)
+	}
+	if cycle {
+		return false, newError(KindInvalid, "dependency cycle detected: a dependency waits for this task or its parent")
+	}
+
+	return pending, nil
+}
+
+// waitsForAny проверяет, ждет ли какая-либо из задач start (непосредственно или транзитивно) одну из задач targets.
+// Незавершенная задача ждет свои незавершенные зависимости и активные подзадачи, завершенная не ждет ничего
+func waitsForAny(ctx context.Context, repo TaskRepository, start, targets []uuid.UUID) (bool, error) {
+	visited := make(map[uuid.UUID]bool)
+	queue := slices.Clone(start)
+
+	for len(queue) > 0 {
+		taskID := queue[0]
+		queue = queue[1:]
+
+		if slices.Contains(targets, taskID) {
+			return true, nil
+		}
+		if visited[taskID] {
+			continue
+		}
+		visited[taskID] = true
+
+		task, err := repo.GetTask(ctx, taskID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return false, err
+		}
+		if !slices.Contains(cancelableSubtaskStatuses, task.Status) {
+			continue
+		}
+
+		dependencies, err := repo.ListDependencies(ctx, []uuid.UUID{taskID})
+		if err != nil {
+			return false, err
+		}
+		for _, dependency := range dependencies {
+			queue = append(queue, dependency.DependsOnID)
+		}
+
+		subtasks, err := repo.ListSubtasks(ctx, taskID, cancelableSubtaskStatuses...)
+		if err != nil {
+			return false, err
+		}
+		for _, subtask := range subtasks {
+			queue = append(queue, subtask.ID)
+		}
+	}
+
+	return false, nil
+}
+
+// unblockDependents возвращает в submitted ожидающие задачи, зависящие от завершенной задачи,
+// если все их зависимости завершены и подзадач в работе нет. Задачи блокируются перед проверкой,
+// чтобы параллельное завершение двух зависимостей не оставило задачу в ожидании.
+// Возвращает события перехода задач
+func unblockDependents(ctx context.Context, repo TaskRepository, completedID uuid.UUID, actor string) ([]events.Event, error) {
+	dependents, err := repo.ListDependents(ctx, completedID, models.StatusWaiting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependent tasks: %w", err)
+	}
+
+	var unblocked []events.Event
+	for _, dependent := range dependents {
+		task, err := repo.LockTask(ctx, dependent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock dependent task: %w", err)
+		}
+		if task.Status != models.StatusWaiting {
+			continue
+		}
+
+		ready, err := readyToResume(ctx, repo, task)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			continue
+		}
+
+		if err := repo.UpdateStatus(ctx, []uuid.UUID{task.ID}, models.StatusSubmitted); err != nil {
+			return nil, fmt.Errorf("failed to update dependent task status: %w", err)
+		}
+
+		previousStatus := task.Status
+		task.Status = models.StatusSubmitted
+		task.LeaseExpiresAt = nil
+		unblocked = append(unblocked, events.NewEvent(events.EventUnblocked, *task, previousStatus, actor))
+	}
+
+	return unblocked, nil
+}
+
+// settleDependents обрабатывает задачи, зависящие от задач переходов taskEvents: после завершения зависимости
+// ожидающие задачи возвращаются в очередь, а после её неудачи, отмены или отклонения - завершаются неудачей.
+// Неудачи зависящих задач обрабатываются так же, поэтому неудача проходит по всей цепочке зависимостей.
+// Возвращает события новых переходов и задачи, вернувшиеся в очередь
+func settleDependents(ctx context.Context, repo TaskRepository, taskEvents []events.Event, actor string) ([]events.Event, []models.Task, error) {
+	var settled []events.Event
+	var submitted []models.Task
+
+	queue := slices.Clone(taskEvents)
+	for len(queue) > 0 {
+		event := queue[0]
+		queue = queue[1:]
+
+		switch {
+		case event.Task.Status == models.StatusCompleted:
+			unblocked, err := unblockDependents(ctx, repo, event.Task.ID, actor)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, unblockedEvent := range unblocked {
+				submitted = append(submitted, unblockedEvent.Task)
+			}
+			settled = append(settled, unblocked...)
+
+		case slices.Contains(failedDependencyStatuses, event.Task.Status):
+			failed, resubmitted, err := failDependents(ctx, repo, &event.Task, actor)
+			if err != nil {
+				return nil, nil, err
+			}
+			settled = append(settled, failed...)
+			submitted = append(submitted, resubmitted...)
+			queue = append(queue, failed...)
+		}
+	}
+
+	return settled, submitted, nil
+}
+
+// failDependents завершает неудачей ожидающие задачи, зависящие от неудавшейся, отмененной или отклоненной задачи.
+// Причина неудачи указывает зависимость и её статус. Активные подзадачи таких задач отменяются,
+// а к их родителям применяется политика on_child_failure.
+// Возвращает события переходов задач дерева и задачи, вернувшиеся в очередь
+func failDependents(ctx context.Context, repo TaskRepository, dependency *models.Task, actor string) ([]events.Event, []models.Task, error) {
+	dependents, err := repo.ListDependents(ctx, dependency.ID, models.StatusWaiting)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list dependent tasks: %w", err)
+	}
+
+	var taskEvents []events.Event
+	var submitted []models.Task
+	for _, dependent := range dependents {
+		task, err := repo.LockTask(ctx, dependent.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to lock dependent task: %w", err)
+		}
+		if task.Status != models.StatusWaiting {
+			continue
+		}
+
+		previousStatus := task.Status
+		reason := fmt.Sprintf("dependency %s ended with status %s", dependency.ID, dependency.Status)
+		task.Status = models.StatusFailed
+		task.Result = "FAILURE REASON: " + reason
+		task.LeaseExpiresAt = nil
+		task.NotBefore = nil
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return nil, nil, fmt.Errorf("failed to update dependent task: %w", err)
+		}
+		taskEvents = append(taskEvents, events.NewEvent(events.EventFailed, *task, previousStatus, actor))
+
+		// Подзадачи неудавшейся задачи больше не нужны
+		canceled, err := cancelSubtasksRecursive(ctx, repo, task.ID, actor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to cancel subtasks: %w", err)
+		}
+		taskEvents = append(taskEvents, canceled...)
+		removeUserIfNoActiveTasks(ctx, repo, task.Assignee)
+
+		parentEvents, resubmitted, err := propagateChildFailure(ctx, repo, task, reason, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+		taskEvents = append(taskEvents, parentEvents...)
+		submitted = append(submitted, resubmitted...)
+	}
+
+	return taskEvents, submitted, nil
+}
+
+// readyToResume проверяет, что ожидающая задача больше ничего не ждет: все её зависимости завершены,
+// а подзадачи завершены, отменены, отклонены или, если политика on_child_failure не wait, окончательно неудачны
+func readyToResume(ctx context.Context, repo TaskRepository, task *models.Task) (bool, error) {
+	pendingDependencies, err := repo.CountPendingDependencies(ctx, task.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count pending dependencies: %w", err)
+	}
+	if pendingDependencies > 0 {
+		return false, nil
+	}
+
+	totalCount, err := repo.CountSubtasks(ctx, task.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count subtasks: %w", err)
+	}
+	finishedCount, err := repo.CountSubtasks(ctx, task.ID, subtaskFinishedStatuses(task.OnChildFailure)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to count finished subtasks: %w", err)
+	}
+	return totalCount == finishedCount, nil
+}
+
+// attachDependencies заполняет DependsOn задач из task_dependencies
+func attachDependencies(ctx context.Context, repo TaskRepository, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+	dependencies, err := repo.ListDependencies(ctx, taskIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+
+	dependsOn := make(map[uuid.UUID][]uuid.UUID)
+	for _, dependency := range dependencies {
+		dependsOn[dependency.TaskID] = append(dependsOn[dependency.TaskID], dependency.DependsOnID)
+	}
+	for i := range tasks {
+		tasks[i].DependsOn = dependsOn[tasks[i].ID]
+	}
+	return nil
+}
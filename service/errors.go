@@ -0,0 +1,52 @@
+package service
+
+import "errors"
+
+// ErrNotFound возвращается репозиторием, если запись не найдена
+var ErrNotFound = errors.New("record not found")
+
+// ErrorKind вид ошибки операции, по которому транспорт выбирает код ответа
+type ErrorKind int
+
+const (
+	// KindInvalid неверные параметры запроса или недопустимый для операции статус задачи
+	KindInvalid ErrorKind = iota + 1
+	// KindForbidden у пользователя нет прав на операцию
+	KindForbidden
+	// KindNotFound задача не найдена
+	KindNotFound
+	// KindConflict операция конфликтует с текущим состоянием задачи (например, аренда уже истекла)
+	KindConflict
+)
+
+// Error ошибка операции над задачей с дополнительными полями ответа, например current_status.
+// Остальные ошибки сервиса считаются внутренними
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Error реализует интерфейс error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError создает ошибку операции над задачей
+func newError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// withField добавляет поле в ответ с ошибкой
+func (e *Error) withField(key string, value interface{}) *Error {
+	if e.Fields == nil {
+		e.Fields = map[string]interface{}{}
+	}
+	e.Fields[key] = value
+	return e
+}
+
+// errTaskNotFound ошибка отсутствующей задачи
+func errTaskNotFound() *Error {
+	return newError(KindNotFound, "task not found")
+}
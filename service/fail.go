@@ -0,0 +1,112 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fail помечает задачу в работе как неудачную от имени исполнителя. Если попытки политики повторов
+// не исчерпаны, задача возвращается в очередь с задержкой, иначе попадает в dead-letter или failed,
+// и к родителю применяется его политика on_child_failure
+func (s *TaskService) Fail(ctx context.Context, userID string, taskID uuid.UUID, req FailTaskRequest) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+	var submitted []models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи
+		if task.Assignee != userID {
+			return newError(KindForbidden, "only assignee can fail the task")
+		}
+
+		// Проверяем текущий статус задачи
+		if task.Status != models.StatusWorking {
+			return newError(KindInvalid, "task must be in working status to fail").
+				withField("current_status", task.Status)
+		}
+
+		// Обновляем задачу
+		previousStatus := task.Status
+		applyFailure(task, req.Reason)
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		taskEvents = []events.Event{events.NewEvent(events.EventFailed, *task, previousStatus, userID)}
+
+		// Если задача вернулась в очередь, исполнитель снова имеет активную задачу
+		if task.Status == models.StatusSubmitted {
+			submitted = append(submitted, *task)
+		}
+
+		// При окончательной неудаче родитель реагирует по своей политике on_child_failure
+		// (по умолчанию wait - родитель остается в статусе waiting)
+		if slices.Contains(failedSubtaskStatuses, task.Status) {
+			parentEvents, resubmitted, err := propagateChildFailure(ctx, repo, task, req.Reason, userID)
+			if err != nil {
+				return err
+			}
+			taskEvents = append(taskEvents, parentEvents...)
+			submitted = append(submitted, resubmitted...)
+
+			// Задачи, ожидавшие неудавшуюся задачу, завершаются неудачей вместо бесконечного ожидания
+			settled, unblocked, err := settleDependents(ctx, repo, taskEvents, userID)
+			if err != nil {
+				return err
+			}
+			taskEvents = append(taskEvents, settled...)
+			submitted = append(submitted, unblocked...)
+		}
+
+		// Записываем журнал событий в той же транзакции
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, submittedTask := range submitted {
+		taskSubmitted(&submittedTask)
+	}
+	events.Publish(taskEvents...)
+
+	return task, nil
+}
+
+// applyFailure засчитывает неудачную попытку задачи: если попытки не исчерпаны, возвращает её в очередь с задержкой,
+// иначе переводит в dead-lettered (есть политика повторов) или failed
+func applyFailure(task *models.Task, reason string) {
+	task.Attempts++
+	task.Result = "FAILURE REASON: " + reason
+	task.LeaseExpiresAt = nil
+
+	switch {
+	case task.Attempts < task.MaxAttempts:
+		// Попытки не исчерпаны - возвращаем задачу в очередь с задержкой
+		notBefore := time.Now().Add(task.RetryDelay())
+		task.Status = models.StatusSubmitted
+		task.NotBefore = &notBefore
+	case task.HasRetryPolicy():
+		// Все попытки исчерпаны - задача попадает в dead-letter и может быть перезапущена через redrive
+		task.Status = models.StatusDeadLettered
+		task.NotBefore = nil
+	default:
+		task.Status = models.StatusFailed
+	}
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormRepository хранилище задач в PostgreSQL через GORM
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRepository создает хранилище задач поверх подключения GORM
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+// notFound переводит ошибку GORM об отсутствии записи в ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// claimOrder возвращает сортировку задач для выдачи: по эффективному приоритету, затем по времени создания (FIFO).
+// При включенном старении приоритет растет на 1 за каждый agingInterval ожидания с момента создания
+func claimOrder(agingInterval time.Duration, now time.Time) clause.OrderBy {
+	if agingInterval <= 0 {
+		return clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "priority"}, Desc: true},
+			{Column: clause.Column{Name: "created_at"}},
+		}}
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                "priority + FLOOR(EXTRACT(EPOCH FROM (?::timestamptz - created_at)) / ?) DESC, created_at ASC",
+		Vars:               []interface{}{now, agingInterval.Seconds()},
+		WithoutParentheses: true,
+	}}
+}
+
+// Transaction выполняет fn в транзакции БД
+func (r *GormRepository) Transaction(ctx context.Context, fn func(repo TaskRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormRepository{db: tx})
+	})
+}
+
+// GetTask возвращает задачу по ID
+func (r *GormRepository) GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.WithContext(ctx).First(&task, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &task, nil
+}
+
+// LockTask возвращает задачу по ID с блокировкой строки FOR UPDATE
+func (r *GormRepository) LockTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&task, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &task, nil
+}
+
+// ClaimCandidate возвращает следующую задачу исполнителя с блокировкой FOR UPDATE SKIP LOCKED,
+// чтобы параллельные запросы одного исполнителя не получили одну и ту же задачу
+func (r *GormRepository) ClaimCandidate(ctx context.Context, assignee string, now time.Time, agingInterval time.Duration) (*models.Task, error) {
+	// Пропускаем отложенные задачи (run_at в будущем) и задачи, чья следующая попытка отложена (not_before в будущем)
+	var task models.Task
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("assignee = ? AND status = ?", assignee, models.StatusSubmitted).
+		Where("run_at IS NULL OR run_at <= ?", now).
+		Where("not_before IS NULL OR not_before <= ?", now).
+		Order(claimOrder(agingInterval, now)).
+		Take(&task).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &task, nil
+}
+
+// CreateTask сохраняет новую задачу
+func (r *GormRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Create(task).Error
+}
+
+// SaveTask сохраняет все поля задачи
+func (r *GormRepository) SaveTask(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Save(task).Error
+}
+
+// UpdateStatus переводит задачи в статус и снимает с них аренду, не затрагивая остальные поля
+func (r *GormRepository) UpdateStatus(ctx context.Context, ids []uuid.UUID, status models.TaskStatus) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&models.Task{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"lease_expires_at": nil,
+		}).Error
+}
+
+// ListExpiredLeases возвращает задачи с истекшей арендой с блокировкой FOR UPDATE SKIP LOCKED:
+// задачи, которые сейчас продлевает или завершает исполнитель, пропускаются, а несколько реплик работают параллельно
+func (r *GormRepository) ListExpiredLeases(ctx context.Context, now time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND lease_expires_at IS NOT NULL AND lease_expires_at < ?", models.StatusWorking, now).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListSubtasks возвращает подзадачи первого уровня в указанных статусах
+func (r *GormRepository) ListSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error) {
+	var subtasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Where("parent_task_id = ? AND status IN ?", parentID, statuses).
+		Find(&subtasks).Error; err != nil {
+		return nil, err
+	}
+	return subtasks, nil
+}
+
+// CountSubtasks считает подзадачи первого уровня в указанных статусах
+func (r *GormRepository) CountSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Task{}).Where("parent_task_id = ?", parentID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// CountAssigneeTasks считает задачи исполнителя в указанных статусах
+func (r *GormRepository) CountAssigneeTasks(ctx context.Context, assignee string, statuses ...models.TaskStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Task{}).
+		Where("assignee = ? AND status IN ?", assignee, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+// AddDependencies сохраняет зависимости задачи в task_dependencies
+func (r *GormRepository) AddDependencies(ctx context.Context, taskID uuid.UUID, dependsOn []uuid.UUID) error {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+
+	rows := make([]models.TaskDependency, len(dependsOn))
+	for i, dependsOnID := range dependsOn {
+		rows[i] = models.TaskDependency{TaskID: taskID, DependsOnID: dependsOnID}
+	}
+	return r.db.WithContext(ctx).Create(&rows).Error
+}
+
+// ListDependencies возвращает зависимости указанных задач
+func (r *GormRepository) ListDependencies(ctx context.Context, taskIDs []uuid.UUID) ([]models.TaskDependency, error) {
+	var dependencies []models.TaskDependency
+	if len(taskIDs) == 0 {
+		return dependencies, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("task_id IN ?", taskIDs).
+		Order("created_at").
+		Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// ListDependents возвращает задачи в указанных статусах, зависящие от задачи
+func (r *GormRepository) ListDependents(ctx context.Context, dependsOnID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN task_dependencies ON task_dependencies.task_id = tasks.id").
+		Where("task_dependencies.depends_on_id = ? AND tasks.status IN ?", dependsOnID, statuses).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// CountPendingDependencies считает зависимости задачи, которые еще не завершены
+func (r *GormRepository) CountPendingDependencies(ctx context.Context, taskID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.TaskDependency{}).
+		Joins("JOIN tasks ON tasks.id = task_dependencies.depends_on_id").
+		Where("task_dependencies.task_id = ? AND tasks.status <> ?", taskID, models.StatusCompleted).
+		Count(&count).Error
+	return count, err
+}
+
+// ListRootTasks возвращает корневые задачи пользователя: корневая задача ссылается на себя в root_task_id
+func (r *GormRepository) ListRootTasks(ctx context.Context, createdBy string) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Where("created_by = ? AND id = root_task_id", createdBy).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListDeadLettered возвращает задачи в dead-letter, созданные пользователем или назначенные на него, в порядке создания
+func (r *GormRepository) ListDeadLettered(ctx context.Context, userID string) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND (created_by = ? OR assignee = ?)", models.StatusDeadLettered, userID, userID).
+		Order("created_at").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListTreeTasks возвращает все задачи с данным root_task_id
+func (r *GormRepository) ListTreeTasks(ctx context.Context, rootTaskID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Where("root_task_id = ?", rootTaskID).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListDueRecurringTasks возвращает ID включенных периодических задач, которые пора запустить
+func (r *GormRepository) ListDueRecurringTasks(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&models.RecurringTask{}).
+		Where("enabled = ? AND (next_run_at <= ? OR pending_runs > 0)", true, now).
+		Order("next_run_at").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// LockRecurringTask возвращает периодическую задачу с блокировкой FOR UPDATE SKIP LOCKED,
+// чтобы несколько реплик не создали один и тот же запуск
+func (r *GormRepository) LockRecurringTask(ctx context.Context, id uuid.UUID) (*models.RecurringTask, error) {
+	var recurringTask models.RecurringTask
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		First(&recurringTask, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &recurringTask, nil
+}
+
+// SaveRecurringTask сохраняет все поля периодической задачи
+func (r *GormRepository) SaveRecurringTask(ctx context.Context, recurringTask *models.RecurringTask) error {
+	return r.db.WithContext(ctx).Save(recurringTask).Error
+}
+
+// CountRecurringInstances считает задачи, созданные по периодической задаче, в указанных статусах
+func (r *GormRepository) CountRecurringInstances(ctx context.Context, recurringTaskID uuid.UUID, statuses ...models.TaskStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Task{}).
+		Where("recurring_task_id = ? AND status IN ?", recurringTaskID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+// ListCredentialsToReencrypt возвращает задачи с credentials, зашифрованными другим ключом или открытыми,
+// с блокировкой FOR UPDATE. Пустые credentials ({} или null) не шифруются и не выбираются
+func (r *GormRepository) ListCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("(credentials_ciphertext IS NOT NULL AND credentials_key_id <> ?) OR "+
+			"(credentials_ciphertext IS NULL AND credentials IS NOT NULL AND credentials NOT IN ('{}'::jsonb, 'null'::jsonb))", activeKeyID).
+		Order("id").
+		Limit(limit).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// SaveCredentials сохраняет поля credentials задачи
+func (r *GormRepository) SaveCredentials(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Model(&models.Task{}).
+		Where("id = ?", task.ID).
+		Updates(map[string]interface{}{
+			"credentials":               nullIfEmpty(task.Credentials),
+			"credentials_key_id":        task.CredentialsKeyID,
+			"credentials_encrypted_key": task.CredentialsEncryptedKey,
+			"credentials_ciphertext":    task.CredentialsCiphertext,
+		}).Error
+}
+
+// ListRecurringCredentialsToReencrypt возвращает периодические задачи с credentials, зашифрованными другим ключом
+// или открытыми, с блокировкой FOR UPDATE. Пустые credentials ({} или null) не шифруются и не выбираются
+func (r *GormRepository) ListRecurringCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.RecurringTask, error) {
+	var recurringTasks []models.RecurringTask
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("(credentials_ciphertext IS NOT NULL AND credentials_key_id <> ?) OR "+
+			"(credentials_ciphertext IS NULL AND credentials IS NOT NULL AND credentials NOT IN ('{}'::jsonb, 'null'::jsonb))", activeKeyID).
+		Order("id").
+		Limit(limit).
+		Find(&recurringTasks).Error; err != nil {
+		return nil, err
+	}
+	return recurringTasks, nil
+}
+
+// SaveRecurringCredentials сохраняет поля credentials периодической задачи
+func (r *GormRepository) SaveRecurringCredentials(ctx context.Context, recurringTask *models.RecurringTask) error {
+	return r.db.WithContext(ctx).Model(&models.RecurringTask{}).
+		Where("id = ?", recurringTask.ID).
+		Updates(map[string]interface{}{
+			"credentials":               nullIfEmpty(recurringTask.Credentials),
+			"credentials_key_id":        recurringTask.CredentialsKeyID,
+			"credentials_encrypted_key": recurringTask.CredentialsEncryptedKey,
+			"credentials_ciphertext":    recurringTask.CredentialsCiphertext,
+		}).Error
+}
+
+// RecordEvents записывает события в журнал task_events и присваивает им ID строк журнала
+func (r *GormRepository) RecordEvents(ctx context.Context, evts []events.Event) error {
+	return events.Record(r.db.WithContext(ctx), evts)
+}
+
+// RecordCredentialAccess добавляет запись в журнал выдачи credentials
+func (r *GormRepository) RecordCredentialAccess(ctx context.Context, access *models.CredentialAccess) error {
+	return r.db.WithContext(ctx).Create(access).Error
+}
+
+// ListCredentialAccess возвращает записи журнала выдачи credentials по фильтру, начиная с последних
+func (r *GormRepository) ListCredentialAccess(ctx context.Context, filter CredentialAccessFilter) ([]models.CredentialAccess, error) {
+	query := r.db.WithContext(ctx).Model(&models.CredentialAccess{})
+	if filter.TaskID != nil {
+		query = query.Where("task_id = ?", *filter.TaskID)
+	}
+	if filter.TaskCreatedBy != "" {
+		query = query.Where("task_created_by = ?", filter.TaskCreatedBy)
+	}
+	if filter.Assignee != "" {
+		query = query.Where("assignee = ?", filter.Assignee)
+	}
+	if filter.Service != "" {
+		query = query.Where("services @> ?", models.StringList{filter.Service})
+	}
+	if filter.Reference != "" {
+		query = query.Where("secret_references @> ?", models.StringList{filter.Reference})
+	}
+	if filter.Since != nil {
+		query = query.Where("accessed_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("accessed_at < ?", *filter.Until)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var accesses []models.CredentialAccess
+	if err := query.Order("id DESC").Find(&accesses).Error; err != nil {
+		return nil, err
+	}
+	return accesses, nil
+}
+
+// ListTaskEvents возвращает журнал переходов задачи
+func (r *GormRepository) ListTaskEvents(ctx context.Context, taskID uuid.UUID) ([]models.TaskEvent, error) {
+	var taskEvents []models.TaskEvent
+	if err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("id").
+		Find(&taskEvents).Error; err != nil {
+		return nil, err
+	}
+	return taskEvents, nil
+}
+
+// ListRootTaskEvents возвращает до limit событий дерева корневой задачи с ID больше afterID в порядке ID
+func (r *GormRepository) ListRootTaskEvents(ctx context.Context, rootTaskID uuid.UUID, afterID int64, limit int) ([]models.TaskEvent, error) {
+	var taskEvents []models.TaskEvent
+	if err := r.db.WithContext(ctx).
+		Where("root_task_id = ? AND id > ?", rootTaskID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&taskEvents).Error; err != nil {
+		return nil, err
+	}
+	return taskEvents, nil
+}
+
+// ListTaskEventsByID возвращает события журнала по ID в порядке ID
+func (r *GormRepository) ListTaskEventsByID(ctx context.Context, ids []int64) ([]models.TaskEvent, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var taskEvents []models.TaskEvent
+	if err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&taskEvents).Error; err != nil {
+		return nil, err
+	}
+	return taskEvents, nil
+}
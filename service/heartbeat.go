@@ -0,0 +1,49 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Heartbeat продлевает аренду задачи в работе на LeaseTTL от текущего момента от имени исполнителя
+func (s *TaskService) Heartbeat(ctx context.Context, userID string, taskID uuid.UUID) (*models.Task, error) {
+	var task *models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу с блокировкой, чтобы не конкурировать с планировщиком аренд
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи
+		if task.Assignee != userID {
+			return newError(KindForbidden, "only assignee can send heartbeat for the task")
+		}
+
+		// Продлевать можно только аренду задачи в работе
+		// Если аренда уже истекла и задача вернулась в submitted, исполнитель должен взять её заново
+		if task.Status != models.StatusWorking {
+			return newError(KindConflict, "task must be in working status to extend lease").
+				withField("current_status", task.Status)
+		}
+
+		leaseExpiresAt := time.Now().Add(s.cfg.LeaseTTL)
+		task.LeaseExpiresAt = &leaseExpiresAt
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to extend task lease: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// RequestInput приостанавливает задачу в работе с вопросом исполнителя к создателю.
+// Задача переходит в input-required и не выдается исполнителю, пока создатель не ответит
+func (s *TaskService) RequestInput(ctx context.Context, userID string, taskID uuid.UUID, question string) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи
+		if task.Assignee != userID {
+			return newError(KindForbidden, "only assignee can request input for the task")
+		}
+
+		// Проверяем текущий статус задачи
+		if task.Status != models.StatusWorking {
+			return newError(KindInvalid, "task must be in working status to request input").
+				withField("current_status", task.Status)
+		}
+
+		// Приостанавливаем задачу: аренда снимается, пока ждем ответа создателя
+		previousStatus := task.Status
+		task.Status = models.StatusInputRequired
+		task.InputQuestion = question
+		task.InputAnswer = ""
+		task.LeaseExpiresAt = nil
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		// Если активных задач больше нет, удаляем пользователя из кэша
+		removeUserIfNoActiveTasks(ctx, repo, task.Assignee)
+
+		// Записываем журнал событий в той же транзакции
+		taskEvents = []events.Event{events.NewEvent(events.EventInputRequired, *task, previousStatus, userID)}
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.Publish(taskEvents...)
+
+	return task, nil
+}
+
+// ProvideInput сохраняет ответ создателя и возвращает задачу из input-required в очередь
+func (s *TaskService) ProvideInput(ctx context.Context, userID string, taskID uuid.UUID, answer string) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является создателем задачи
+		if task.CreatedBy != userID {
+			return newError(KindForbidden, "only creator can provide input for the task")
+		}
+
+		// Проверяем текущий статус задачи
+		if task.Status != models.StatusInputRequired {
+			return newError(KindInvalid, "task must be in input-required status to provide input").
+				withField("current_status", task.Status)
+		}
+
+		// Возвращаем задачу в очередь с ответом создателя
+		previousStatus := task.Status
+		task.Status = models.StatusSubmitted
+		task.InputAnswer = answer
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		// Записываем журнал событий в той же транзакции
+		taskEvents = []events.Event{events.NewEvent(events.EventInputProvided, *task, previousStatus, userID)}
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Добавляем исполнителя в кэш и будим его, если он ожидает задачу
+	taskSubmitted(task)
+	events.Publish(taskEvents...)
+
+	return task, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RequeueExpired возвращает в очередь задачи в работе, аренда которых истекла к now, и увеличивает их счетчик возвратов.
+// Используется планировщиком аренд, возвращает задачи после перехода
+func (s *TaskService) RequeueExpired(ctx context.Context, now time.Time) ([]models.Task, error) {
+	var requeued []models.Task
+	var taskEvents []events.Event
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Задачи блокируются до конца транзакции, поэтому heartbeat и завершение задачи
+		// либо ждут возврата, либо уже продлили аренду и задача не выбирается
+		var err error
+		requeued, err = repo.ListExpiredLeases(ctx, now)
+		if err != nil {
+			return fmt.Errorf("failed to find tasks with expired lease: %w", err)
+		}
+
+		taskEvents = make([]events.Event, 0, len(requeued))
+		for i := range requeued {
+			task := &requeued[i]
+			previousStatus := task.Status
+			task.Status = models.StatusSubmitted
+			task.LeaseExpiresAt = nil
+			task.RequeueCount++
+
+			if err := repo.SaveTask(ctx, task); err != nil {
+				return fmt.Errorf("failed to requeue task: %w", err)
+			}
+			taskEvents = append(taskEvents, events.NewEvent(events.EventRequeued, *task, previousStatus, events.ActorSystem))
+		}
+
+		// Записываем возврат задач в журнал в той же транзакции
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Исполнители вернувшихся задач снова имеют задачи в статусе submitted
+	for _, task := range requeued {
+		taskSubmitted(&task)
+	}
+	events.Publish(taskEvents...)
+
+	return requeued, nil
+}
@@ -0,0 +1,565 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository хранилище задач в памяти процесса для тестов и локального запуска без PostgreSQL.
+// Транзакции выполняются последовательно под общей блокировкой и откатываются восстановлением снимка
+type MemoryRepository struct {
+	store *memoryStore
+	inTx  bool // Репозиторий передан в fn транзакции, блокировка уже захвачена
+}
+
+// memoryStore данные хранилища в памяти
+type memoryStore struct {
+	mu             sync.Mutex
+	tasks          map[uuid.UUID]models.Task
+	recurringTasks map[uuid.UUID]models.RecurringTask
+	dependencies   []models.TaskDependency
+	events         []models.TaskEvent
+	lastEventID    int64
+	accesses       []models.CredentialAccess
+	lastAccessID   int64
+}
+
+// NewMemoryRepository создает пустое хранилище задач в памяти
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		store: &memoryStore{
+			tasks:          make(map[uuid.UUID]models.Task),
+			recurringTasks: make(map[uuid.UUID]models.RecurringTask),
+		},
+	}
+}
+
+// lock захватывает блокировку хранилища вне транзакции и возвращает функцию её освобождения
+func (r *MemoryRepository) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.store.mu.Lock()
+	return r.store.mu.Unlock
+}
+
+// Transaction выполняет fn под блокировкой хранилища и восстанавливает снимок данных, если fn вернула ошибку
+func (r *MemoryRepository) Transaction(ctx context.Context, fn func(repo TaskRepository) error) (err error) {
+	if r.inTx {
+		return fn(r)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tasks := maps.Clone(r.store.tasks)
+	recurringTasks := maps.Clone(r.store.recurringTasks)
+	dependencyCount := len(r.store.dependencies)
+	eventCount := len(r.store.events)
+	lastEventID := r.store.lastEventID
+	accessCount := len(r.store.accesses)
+	lastAccessID := r.store.lastAccessID
+
+	committed := false
+	defer func() {
+		if !committed {
+			r.store.tasks = tasks
+			r.store.recurringTasks = recurringTasks
+			r.store.dependencies = r.store.dependencies[:dependencyCount]
+			r.store.events = r.store.events[:eventCount]
+			r.store.lastEventID = lastEventID
+			r.store.accesses = r.store.accesses[:accessCount]
+			r.store.lastAccessID = lastAccessID
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := fn(&MemoryRepository{store: r.store, inTx: true}); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// GetTask возвращает копию задачи по ID
+func (r *MemoryRepository) GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	defer r.lock()()
+
+	task, ok := r.store.tasks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &task, nil
+}
+
+// LockTask возвращает копию задачи по ID. Транзакции и так выполняются последовательно
+func (r *MemoryRepository) LockTask(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	return r.GetTask(ctx, id)
+}
+
+// effectivePriority вычисляет приоритет задачи с учетом старения, как claimOrder в PostgreSQL
+func effectivePriority(task models.Task, now time.Time, agingInterval time.Duration) float64 {
+	priority := float64(task.Priority)
+	if agingInterval > 0 {
+		priority += math.Floor(now.Sub(task.CreatedAt).Seconds() / agingInterval.Seconds())
+	}
+	return priority
+}
+
+// ClaimCandidate возвращает следующую задачу исполнителя в порядке выдачи
+func (r *MemoryRepository) ClaimCandidate(ctx context.Context, assignee string, now time.Time, agingInterval time.Duration) (*models.Task, error) {
+	defer r.lock()()
+
+	var candidates []models.Task
+	for _, task := range r.store.tasks {
+		if task.Assignee != assignee || task.Status != models.StatusSubmitted {
+			continue
+		}
+		if task.RunAt != nil && task.RunAt.After(now) {
+			continue
+		}
+		if task.NotBefore != nil && task.NotBefore.After(now) {
+			continue
+		}
+		candidates = append(candidates, task)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNotFound
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		pi := effectivePriority(candidates[i], now, agingInterval)
+		pj := effectivePriority(candidates[j], now, agingInterval)
+		if pi != pj {
+			return pi > pj
+		}
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+
+	return &candidates[0], nil
+}
+
+// CreateTask сохраняет новую задачу, заполняя значения по умолчанию так же, как хук BeforeCreate и БД
+func (r *MemoryRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	defer r.lock()()
+
+	if err := task.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	if _, exists := r.store.tasks[task.ID]; exists {
+		return fmt.Errorf("task %s already exists", task.ID)
+	}
+
+	r.store.tasks[task.ID] = *task
+	return nil
+}
+
+// SaveTask сохраняет все поля задачи
+func (r *MemoryRepository) SaveTask(ctx context.Context, task *models.Task) error {
+	defer r.lock()()
+
+	r.store.tasks[task.ID] = *task
+	return nil
+}
+
+// UpdateStatus переводит задачи в статус и снимает с них аренду
+func (r *MemoryRepository) UpdateStatus(ctx context.Context, ids []uuid.UUID, status models.TaskStatus) error {
+	defer r.lock()()
+
+	for _, id := range ids {
+		task, ok := r.store.tasks[id]
+		if !ok {
+			continue
+		}
+		task.Status = status
+		task.LeaseExpiresAt = nil
+		r.store.tasks[id] = task
+	}
+	return nil
+}
+
+// ListExpiredLeases возвращает задачи в работе с истекшей арендой в порядке создания
+func (r *MemoryRepository) ListExpiredLeases(ctx context.Context, now time.Time) ([]models.Task, error) {
+	return r.filterTasks(func(task models.Task) bool {
+		return task.Status == models.StatusWorking && task.LeaseExpiresAt != nil && task.LeaseExpiresAt.Before(now)
+	}), nil
+}
+
+// ListSubtasks возвращает подзадачи первого уровня в указанных статусах
+func (r *MemoryRepository) ListSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error) {
+	defer r.lock()()
+
+	var subtasks []models.Task
+	for _, task := range r.store.tasks {
+		if task.ParentTaskID != nil && *task.ParentTaskID == parentID && slices.Contains(statuses, task.Status) {
+			subtasks = append(subtasks, task)
+		}
+	}
+
+	// Порядок map случаен, сортируем для предсказуемого результата
+	sort.Slice(subtasks, func(i, j int) bool {
+		return subtasks[i].CreatedAt.Before(subtasks[j].CreatedAt)
+	})
+	return subtasks, nil
+}
+
+// CountSubtasks считает подзадачи первого уровня в указанных статусах, без статусов - все подзадачи
+func (r *MemoryRepository) CountSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) (int64, error) {
+	defer r.lock()()
+
+	var count int64
+	for _, task := range r.store.tasks {
+		if task.ParentTaskID == nil || *task.ParentTaskID != parentID {
+			continue
+		}
+		if len(statuses) == 0 || slices.Contains(statuses, task.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountAssigneeTasks считает задачи исполнителя в указанных статусах
+func (r *MemoryRepository) CountAssigneeTasks(ctx context.Context, assignee string, statuses ...models.TaskStatus) (int64, error) {
+	defer r.lock()()
+
+	var count int64
+	for _, task := range r.store.tasks {
+		if task.Assignee == assignee && slices.Contains(statuses, task.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// AddDependencies сохраняет зависимости задачи
+func (r *MemoryRepository) AddDependencies(ctx context.Context, taskID uuid.UUID, dependsOn []uuid.UUID) error {
+	defer r.lock()()
+
+	now := time.Now()
+	for _, dependsOnID := range dependsOn {
+		r.store.dependencies = append(r.store.dependencies, models.TaskDependency{
+			TaskID:      taskID,
+			DependsOnID: dependsOnID,
+			CreatedAt:   now,
+		})
+	}
+	return nil
+}
+
+// ListDependencies возвращает зависимости указанных задач в порядке добавления
+func (r *MemoryRepository) ListDependencies(ctx context.Context, taskIDs []uuid.UUID) ([]models.TaskDependency, error) {
+	defer r.lock()()
+
+	var dependencies []models.TaskDependency
+	for _, dependency := range r.store.dependencies {
+		if slices.Contains(taskIDs, dependency.TaskID) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies, nil
+}
+
+// ListDependents возвращает задачи в указанных статусах, зависящие от задачи
+func (r *MemoryRepository) ListDependents(ctx context.Context, dependsOnID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error) {
+	defer r.lock()()
+
+	var tasks []models.Task
+	for _, dependency := range r.store.dependencies {
+		if dependency.DependsOnID != dependsOnID {
+			continue
+		}
+		if task, ok := r.store.tasks[dependency.TaskID]; ok && slices.Contains(statuses, task.Status) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// CountPendingDependencies считает зависимости задачи, которые еще не завершены
+func (r *MemoryRepository) CountPendingDependencies(ctx context.Context, taskID uuid.UUID) (int64, error) {
+	defer r.lock()()
+
+	var count int64
+	for _, dependency := range r.store.dependencies {
+		if dependency.TaskID != taskID {
+			continue
+		}
+		if task, ok := r.store.tasks[dependency.DependsOnID]; ok && task.Status != models.StatusCompleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ListRootTasks возвращает корневые задачи, созданные пользователем
+func (r *MemoryRepository) ListRootTasks(ctx context.Context, createdBy string) ([]models.Task, error) {
+	return r.filterTasks(func(task models.Task) bool {
+		return task.CreatedBy == createdBy && task.RootTaskID != nil && *task.RootTaskID == task.ID
+	}), nil
+}
+
+// ListTreeTasks возвращает все задачи дерева корневой задачи
+func (r *MemoryRepository) ListTreeTasks(ctx context.Context, rootTaskID uuid.UUID) ([]models.Task, error) {
+	return r.filterTasks(func(task models.Task) bool {
+		return task.RootTaskID != nil && *task.RootTaskID == rootTaskID
+	}), nil
+}
+
+// ListDeadLettered возвращает задачи в dead-letter, созданные пользователем или назначенные на него, в порядке создания
+func (r *MemoryRepository) ListDeadLettered(ctx context.Context, userID string) ([]models.Task, error) {
+	return r.filterTasks(func(task models.Task) bool {
+		return task.Status == models.StatusDeadLettered && (task.CreatedBy == userID || task.Assignee == userID)
+	}), nil
+}
+
+// filterTasks возвращает задачи, подходящие под условие, в порядке создания
+func (r *MemoryRepository) filterTasks(match func(task models.Task) bool) []models.Task {
+	defer r.lock()()
+
+	var tasks []models.Task
+	for _, task := range r.store.tasks {
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	// Порядок map случаен, сортируем для предсказуемого результата
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// ListDueRecurringTasks возвращает ID включенных периодических задач, которые пора запустить
+func (r *MemoryRepository) ListDueRecurringTasks(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	defer r.lock()()
+
+	var ids []uuid.UUID
+	for id, recurringTask := range r.store.recurringTasks {
+		if !recurringTask.Enabled {
+			continue
+		}
+		if (recurringTask.NextRunAt != nil && !recurringTask.NextRunAt.After(now)) || recurringTask.PendingRuns > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// LockRecurringTask возвращает копию периодической задачи по ID. Транзакции и так выполняются последовательно
+func (r *MemoryRepository) LockRecurringTask(ctx context.Context, id uuid.UUID) (*models.RecurringTask, error) {
+	defer r.lock()()
+
+	recurringTask, ok := r.store.recurringTasks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &recurringTask, nil
+}
+
+// SaveRecurringTask сохраняет все поля периодической задачи, заполняя значения по умолчанию для новой записи
+func (r *MemoryRepository) SaveRecurringTask(ctx context.Context, recurringTask *models.RecurringTask) error {
+	defer r.lock()()
+
+	if err := recurringTask.BeforeCreate(nil); err != nil {
+		return err
+	}
+	r.store.recurringTasks[recurringTask.ID] = *recurringTask
+	return nil
+}
+
+// CountRecurringInstances считает задачи, созданные по периодической задаче, в указанных статусах
+func (r *MemoryRepository) CountRecurringInstances(ctx context.Context, recurringTaskID uuid.UUID, statuses ...models.TaskStatus) (int64, error) {
+	defer r.lock()()
+
+	var count int64
+	for _, task := range r.store.tasks {
+		if task.RecurringTaskID != nil && *task.RecurringTaskID == recurringTaskID && slices.Contains(statuses, task.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ListCredentialsToReencrypt возвращает задачи с credentials, зашифрованными другим ключом или открытыми
+func (r *MemoryRepository) ListCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.Task, error) {
+	tasks := r.filterTasks(func(task models.Task) bool {
+		if len(task.CredentialsCiphertext) > 0 {
+			return task.CredentialsKeyID != activeKeyID
+		}
+		return hasCredentials(task.Credentials)
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+// SaveCredentials сохраняет поля credentials задачи
+func (r *MemoryRepository) SaveCredentials(ctx context.Context, task *models.Task) error {
+	defer r.lock()()
+
+	stored, ok := r.store.tasks[task.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Credentials = task.Credentials
+	stored.CredentialsKeyID = task.CredentialsKeyID
+	stored.CredentialsEncryptedKey = task.CredentialsEncryptedKey
+	stored.CredentialsCiphertext = task.CredentialsCiphertext
+	r.store.tasks[task.ID] = stored
+	return nil
+}
+
+// ListRecurringCredentialsToReencrypt возвращает периодические задачи с credentials, зашифрованными другим ключом или открытыми
+func (r *MemoryRepository) ListRecurringCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.RecurringTask, error) {
+	defer r.lock()()
+
+	var recurringTasks []models.RecurringTask
+	for _, recurringTask := range r.store.recurringTasks {
+		if len(recurringTask.CredentialsCiphertext) > 0 {
+			if recurringTask.CredentialsKeyID == activeKeyID {
+				continue
+			}
+		} else if !hasCredentials(recurringTask.Credentials) {
+			continue
+		}
+		recurringTasks = append(recurringTasks, recurringTask)
+	}
+
+	slices.SortFunc(recurringTasks, func(a, b models.RecurringTask) int {
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+	if len(recurringTasks) > limit {
+		recurringTasks = recurringTasks[:limit]
+	}
+	return recurringTasks, nil
+}
+
+// SaveRecurringCredentials сохраняет поля credentials периодической задачи
+func (r *MemoryRepository) SaveRecurringCredentials(ctx context.Context, recurringTask *models.RecurringTask) error {
+	defer r.lock()()
+
+	stored, ok := r.store.recurringTasks[recurringTask.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Credentials = recurringTask.Credentials
+	stored.CredentialsKeyID = recurringTask.CredentialsKeyID
+	stored.CredentialsEncryptedKey = recurringTask.CredentialsEncryptedKey
+	stored.CredentialsCiphertext = recurringTask.CredentialsCiphertext
+	r.store.recurringTasks[recurringTask.ID] = stored
+	return nil
+}
+
+// RecordEvents записывает события в журнал и присваивает им ID строк журнала
+func (r *MemoryRepository) RecordEvents(ctx context.Context, evts []events.Event) error {
+	defer r.lock()()
+
+	for i, event := range evts {
+		row, err := event.TaskEvent()
+		if err != nil {
+			return err
+		}
+		r.store.lastEventID++
+		row.ID = r.store.lastEventID
+		r.store.events = append(r.store.events, row)
+		evts[i].ID = row.ID
+	}
+	return nil
+}
+
+// ListTaskEvents возвращает журнал переходов задачи в порядке записи
+func (r *MemoryRepository) ListTaskEvents(ctx context.Context, taskID uuid.UUID) ([]models.TaskEvent, error) {
+	defer r.lock()()
+
+	var taskEvents []models.TaskEvent
+	for _, row := range r.store.events {
+		if row.TaskID == taskID {
+			taskEvents = append(taskEvents, row)
+		}
+	}
+	return taskEvents, nil
+}
+
+// ListRootTaskEvents возвращает до limit событий дерева корневой задачи с ID больше afterID в порядке ID
+func (r *MemoryRepository) ListRootTaskEvents(ctx context.Context, rootTaskID uuid.UUID, afterID int64, limit int) ([]models.TaskEvent, error) {
+	defer r.lock()()
+
+	var taskEvents []models.TaskEvent
+	for _, row := range r.store.events {
+		if len(taskEvents) >= limit {
+			break
+		}
+		if row.RootTaskID == rootTaskID && row.ID > afterID {
+			taskEvents = append(taskEvents, row)
+		}
+	}
+	return taskEvents, nil
+}
+
+// ListTaskEventsByID возвращает события журнала по ID в порядке ID
+func (r *MemoryRepository) ListTaskEventsByID(ctx context.Context, ids []int64) ([]models.TaskEvent, error) {
+	defer r.lock()()
+
+	var taskEvents []models.TaskEvent
+	for _, row := range r.store.events {
+		if slices.Contains(ids, row.ID) {
+			taskEvents = append(taskEvents, row)
+		}
+	}
+	return taskEvents, nil
+}
+
+// RecordCredentialAccess добавляет запись в журнал выдачи credentials
+func (r *MemoryRepository) RecordCredentialAccess(ctx context.Context, access *models.CredentialAccess) error {
+	defer r.lock()()
+
+	r.store.lastAccessID++
+	access.ID = r.store.lastAccessID
+	r.store.accesses = append(r.store.accesses, *access)
+	return nil
+}
+
+// ListCredentialAccess возвращает записи журнала выдачи credentials по фильтру, начиная с последних
+func (r *MemoryRepository) ListCredentialAccess(ctx context.Context, filter CredentialAccessFilter) ([]models.CredentialAccess, error) {
+	defer r.lock()()
+
+	var accesses []models.CredentialAccess
+	for _, access := range slices.Backward(r.store.accesses) {
+		switch {
+		case filter.TaskID != nil && access.TaskID != *filter.TaskID,
+			filter.TaskCreatedBy != "" && access.TaskCreatedBy != filter.TaskCreatedBy,
+			filter.Assignee != "" && access.Assignee != filter.Assignee,
+			filter.Service != "" && !slices.Contains(access.Services, filter.Service),
+			filter.Reference != "" && !slices.Contains(access.References, filter.Reference),
+			filter.Since != nil && access.AccessedAt.Before(*filter.Since),
+			filter.Until != nil && !access.AccessedAt.Before(*filter.Until):
+			continue
+		}
+
+		accesses = append(accesses, access)
+		if filter.Limit > 0 && len(accesses) == filter.Limit {
+			break
+		}
+	}
+	return accesses, nil
+}
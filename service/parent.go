@@ -0,0 +1,260 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// finishedSubtaskStatuses статусы подзадач, после которых родительская задача может продолжить работу
+var finishedSubtaskStatuses = []models.TaskStatus{
+	models.StatusCompleted,
+	models.StatusCanceled,
+	models.StatusRejected,
+}
+
+// failedSubtaskStatuses статусы окончательной неудачи подзадачи, к которым применяется политика on_child_failure
+var failedSubtaskStatuses = []models.TaskStatus{
+	models.StatusFailed,
+	models.StatusDeadLettered,
+}
+
+// subtaskFinishedStatuses статусы подзадач, которые родитель с политикой policy больше не ждет.
+// При политике wait неудавшаяся подзадача не считается завершенной
+func subtaskFinishedStatuses(policy models.ChildFailurePolicy) []models.TaskStatus {
+	if policy == models.ChildFailureWait {
+		return finishedSubtaskStatuses
+	}
+	return append(slices.Clone(finishedSubtaskStatuses), failedSubtaskStatuses...)
+}
+
+// cancelableSubtaskStatuses статусы подзадач, которые отменяются вместе с родителем
+var cancelableSubtaskStatuses = []models.TaskStatus{
+	models.StatusSubmitted,
+	models.StatusWorking,
+	models.StatusWaiting,
+	models.StatusInputRequired,
+}
+
+// resubmitParentIfSubtasksFinished возвращает ожидающую родительскую задачу в submitted,
+// если все её подзадачи завершены, отменены или отклонены (а при политике on_child_failure, отличной от wait,
+// еще и окончательно неудачны). Возвращает событие перехода родителя или nil, если родитель продолжает ожидание
+func resubmitParentIfSubtasksFinished(ctx context.Context, repo TaskRepository, parentID *uuid.UUID, actor string) (*events.Event, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+
+	// Блокируем родителя: подзадачи, завершающиеся одновременно, проверяют готовность родителя по очереди,
+	// и последняя из них видит остальные завершенными
+	parentTask, err := repo.LockTask(ctx, *parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent task: %w", err)
+	}
+
+	// Возвращаем в очередь только ожидающего родителя, завершенные задачи не воскрешаем
+	if parentTask.Status != models.StatusWaiting {
+		return nil, nil
+	}
+
+	// Подсчитываем задачи с таким же parent
+	totalCount, err := repo.CountSubtasks(ctx, *parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subtasks: %w", err)
+	}
+	finishedCount, err := repo.CountSubtasks(ctx, *parentID, subtaskFinishedStatuses(parentTask.OnChildFailure)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count finished subtasks: %w", err)
+	}
+
+	if totalCount == 0 || totalCount != finishedCount {
+		return nil, nil
+	}
+
+	// Родитель с незавершенными зависимостями продолжает ожидание, его вернет в очередь завершение зависимостей
+	pendingDependencies, err := repo.CountPendingDependencies(ctx, *parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending dependencies: %w", err)
+	}
+	if pendingDependencies > 0 {
+		return nil, nil
+	}
+
+	return resubmitParent(ctx, repo, parentTask, actor)
+}
+
+// resubmitParent возвращает ожидающую родительскую задачу в submitted и возвращает событие перехода
+func resubmitParent(ctx context.Context, repo TaskRepository, parentTask *models.Task, actor string) (*events.Event, error) {
+	if err := repo.UpdateStatus(ctx, []uuid.UUID{parentTask.ID}, models.StatusSubmitted); err != nil {
+		return nil, fmt.Errorf("failed to update parent task status: %w", err)
+	}
+
+	previousStatus := parentTask.Status
+	parentTask.Status = models.StatusSubmitted
+	parentTask.LeaseExpiresAt = nil
+	event := events.NewEvent(events.EventResubmitted, *parentTask, previousStatus, actor)
+	return &event, nil
+}
+
+// cancelSubtasksRecursive рекурсивно отменяет все активные подзадачи.
+// Возвращает события отмены подзадач всех уровней для журнала и подписчиков
+func cancelSubtasksRecursive(ctx context.Context, repo TaskRepository, parentID uuid.UUID, actor string) ([]events.Event, error) {
+	// Получаем все подзадачи, которые нужно отменить
+	subtasks, err := repo.ListSubtasks(ctx, parentID, cancelableSubtaskStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	canceled := make([]events.Event, 0, len(subtasks))
+	if len(subtasks) == 0 {
+		return canceled, nil
+	}
+
+	// Отменяем найденные подзадачи
+	subtaskIDs := make([]uuid.UUID, len(subtasks))
+	for i, subtask := range subtasks {
+		subtaskIDs[i] = subtask.ID
+	}
+	if err := repo.UpdateStatus(ctx, subtaskIDs, models.StatusCanceled); err != nil {
+		return nil, err
+	}
+
+	// Рекурсивно отменяем подзадачи каждой подзадачи
+	for _, subtask := range subtasks {
+		previousStatus := subtask.Status
+		subtask.Status = models.StatusCanceled
+		subtask.LeaseExpiresAt = nil
+		canceled = append(canceled, events.NewEvent(events.EventCanceled, subtask, previousStatus, actor))
+
+		nested, err := cancelSubtasksRecursive(ctx, repo, subtask.ID, actor)
+		if err != nil {
+			return nil, err
+		}
+		canceled = append(canceled, nested...)
+	}
+
+	return canceled, nil
+}
+
+// finishWithSubtasks завершает переход задачи в финальный статус: отменяет её активные подзадачи,
+// возвращает родителя в очередь, если все его подзадачи завершены, обрабатывает зависящие задачи
+// и записывает журнал событий. Возвращает все события перехода и задачи, вернувшиеся в очередь
+func finishWithSubtasks(ctx context.Context, repo TaskRepository, task *models.Task, event events.Event, actor string) ([]events.Event, []models.Task, error) {
+	// Рекурсивно отменяем все активные подзадачи этой задачи
+	canceledSubtasks, err := cancelSubtasksRecursive(ctx, repo, task.ID, actor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to cancel subtasks: %w", err)
+	}
+
+	// События дерева задач для журнала и подписчиков
+	taskEvents := append([]events.Event{event}, canceledSubtasks...)
+	var submitted []models.Task
+
+	// Если все подзадачи родителя завершены, возвращаем его в очередь
+	resubmittedParent, err := resubmitParentIfSubtasksFinished(ctx, repo, task.ParentTaskID, actor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resubmit parent task: %w", err)
+	}
+	if resubmittedParent != nil {
+		taskEvents = append(taskEvents, *resubmittedParent)
+		submitted = append(submitted, resubmittedParent.Task)
+	}
+
+	// Задачи, ожидавшие завершенные или отмененные задачи, продолжают работу или завершаются неудачей
+	settled, unblocked, err := settleDependents(ctx, repo, taskEvents, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	taskEvents = append(taskEvents, settled...)
+	submitted = append(submitted, unblocked...)
+
+	// Если активных задач больше нет, удаляем пользователя из кэша
+	removeUserIfNoActiveTasks(ctx, repo, task.Assignee)
+
+	// Записываем журнал событий в той же транзакции
+	if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+		return nil, nil, fmt.Errorf("failed to record task events: %w", err)
+	}
+
+	return taskEvents, submitted, nil
+}
+
+// propagateChildFailure применяет политику on_child_failure ожидающего родителя к окончательно неудавшейся подзадаче.
+// При fail_parent неудача родителя поднимается дальше по дереву по политике его собственного родителя.
+// Возвращает события переходов задач дерева и задачи, вернувшиеся в очередь
+func propagateChildFailure(ctx context.Context, repo TaskRepository, failedTask *models.Task, reason, actor string) ([]events.Event, []models.Task, error) {
+	if failedTask.ParentTaskID == nil {
+		return nil, nil, nil
+	}
+
+	// Родитель блокируется, чтобы параллельные неудачи подзадач применили политику последовательно
+	parentTask, err := repo.LockTask(ctx, *failedTask.ParentTaskID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get parent task: %w", err)
+	}
+	if parentTask.Status != models.StatusWaiting {
+		return nil, nil, nil
+	}
+
+	switch parentTask.OnChildFailure {
+	case models.ChildFailureResubmitParent:
+		// Родитель с незавершенными зависимостями продолжает ожидание, неудачу он увидит после их завершения
+		return resubmitParentOnFailure(ctx, repo, parentTask, nil, actor)
+
+	case models.ChildFailureCancelSiblings:
+		canceled, err := cancelSubtasksRecursive(ctx, repo, parentTask.ID, actor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to cancel sibling subtasks: %w", err)
+		}
+		return resubmitParentOnFailure(ctx, repo, parentTask, canceled, actor)
+
+	case models.ChildFailureFailParent:
+		canceled, err := cancelSubtasksRecursive(ctx, repo, parentTask.ID, actor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to cancel sibling subtasks: %w", err)
+		}
+
+		previousStatus := parentTask.Status
+		parentReason := fmt.Sprintf("subtask %s failed: %s", failedTask.ID, reason)
+		applyFailure(parentTask, parentReason)
+		if err := repo.SaveTask(ctx, parentTask); err != nil {
+			return nil, nil, fmt.Errorf("failed to update parent task: %w", err)
+		}
+		taskEvents := append(canceled, events.NewEvent(events.EventFailed, *parentTask, previousStatus, actor))
+
+		// Родитель с неисчерпанными попытками возвращается в очередь, иначе неудача поднимается выше
+		if parentTask.Status == models.StatusSubmitted {
+			return taskEvents, []models.Task{*parentTask}, nil
+		}
+		removeUserIfNoActiveTasks(ctx, repo, parentTask.Assignee)
+		ancestorEvents, submitted, err := propagateChildFailure(ctx, repo, parentTask, parentReason, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+		return append(taskEvents, ancestorEvents...), submitted, nil
+
+	default:
+		return nil, nil, nil
+	}
+}
+
+// resubmitParentOnFailure возвращает родителя в очередь после неудачи подзадачи, если у него нет незавершенных зависимостей.
+// taskEvents - уже произошедшие переходы дерева, к которым добавляется событие родителя
+func resubmitParentOnFailure(ctx context.Context, repo TaskRepository, parentTask *models.Task, taskEvents []events.Event, actor string) ([]events.Event, []models.Task, error) {
+	pendingDependencies, err := repo.CountPendingDependencies(ctx, parentTask.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count pending dependencies: %w", err)
+	}
+	if pendingDependencies > 0 {
+		return taskEvents, nil, nil
+	}
+
+	resubmitted, err := resubmitParent(ctx, repo, parentTask, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(taskEvents, *resubmitted), []models.Task{*parentTask}, nil
+}
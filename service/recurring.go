@@ -0,0 +1,167 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recurringActiveStatuses статусы, в которых экземпляр периодической задачи считается активным
+var recurringActiveStatuses = []models.TaskStatus{
+	models.StatusSubmitted,
+	models.StatusWorking,
+	models.StatusWaiting,
+	models.StatusInputRequired,
+}
+
+// RecurringRun результат обработки периодической задачи планировщиком
+type RecurringRun struct {
+	RecurringTaskID uuid.UUID
+	Task            *models.Task // Созданный экземпляр, nil - запуск не состоялся
+	Queued          bool         // Предыдущий экземпляр активен, запуск отложен политикой queue
+	Skipped         bool         // Предыдущий экземпляр активен, запуск пропущен политикой skip
+	DisabledReason  error        // Расписание стало невалидным, и определение отключено
+}
+
+// DueRecurringTasks возвращает ID периодических задач, время запуска которых наступило к now
+// или у которых есть отложенные политикой queue запуски
+func (s *TaskService) DueRecurringTasks(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ids, err := s.repo.ListDueRecurringTasks(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due recurring tasks: %w", err)
+	}
+	return ids, nil
+}
+
+// RunRecurringTask обрабатывает периодическую задачу: создает новую корневую задачу, если время запуска наступило
+// и предыдущий экземпляр завершен, и обновляет состояние планировщика в той же транзакции.
+// Возвращает nil, если определение обрабатывает другая реплика или оно удалено
+func (s *TaskService) RunRecurringTask(ctx context.Context, recurringTaskID uuid.UUID, now time.Time) (*RecurringRun, error) {
+	var run *RecurringRun
+	var createdEvent events.Event
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Блокировка строки не дает нескольким репликам создать один и тот же запуск
+		recurringTask, err := repo.LockRecurringTask(ctx, recurringTaskID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to lock recurring task: %w", err)
+		}
+
+		run = &RecurringRun{RecurringTaskID: recurringTaskID}
+		if !recurringTask.Enabled {
+			return nil
+		}
+
+		// Проверяем, есть ли активный экземпляр, созданный по этому определению
+		activeCount, err := repo.CountRecurringInstances(ctx, recurringTask.ID, recurringActiveStatuses...)
+		if err != nil {
+			return fmt.Errorf("failed to count recurring task instances: %w", err)
+		}
+
+		isDue := recurringTask.NextRunAt != nil && !recurringTask.NextRunAt.After(now)
+		var scheduledAt *time.Time
+
+		if isDue {
+			dueAt := *recurringTask.NextRunAt
+
+			switch {
+			case activeCount == 0:
+				scheduledAt = &dueAt
+			case recurringTask.OverlapPolicy == models.OverlapQueue:
+				recurringTask.PendingRuns++
+				run.Queued = true
+			default:
+				run.Skipped = true
+			}
+
+			// Вычисляем следующий запуск от текущего момента, пропущенные за время простоя запуски не догоняем
+			nextRunAt, err := recurringTask.NextRun(now)
+			if err != nil {
+				// Расписание стало невалидным - отключаем определение, чтобы не обрабатывать его на каждом тике
+				run.DisabledReason = err
+				recurringTask.Enabled = false
+				recurringTask.NextRunAt = nil
+			} else {
+				recurringTask.NextRunAt = &nextRunAt
+			}
+		} else if recurringTask.PendingRuns > 0 && activeCount == 0 {
+			// Предыдущий экземпляр завершился - запускаем отложенный запуск
+			recurringTask.PendingRuns--
+			scheduledAt = &now
+		}
+
+		if scheduledAt != nil {
+			run.Task, createdEvent, err = s.spawnRecurringTask(ctx, repo, recurringTask, *scheduledAt, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := repo.SaveRecurringTask(ctx, recurringTask); err != nil {
+			return fmt.Errorf("failed to save recurring task: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if run != nil && run.Task != nil {
+		taskCreated(run.Task)
+		events.Publish(createdEvent)
+	}
+
+	return run, nil
+}
+
+// spawnRecurringTask создает экземпляр периодической задачи как системную корневую задачу
+// и отмечает запуск в состоянии определения
+func (s *TaskService) spawnRecurringTask(ctx context.Context, repo TaskRepository, recurringTask *models.RecurringTask, scheduledAt, now time.Time) (*models.Task, events.Event, error) {
+	loc, err := recurringTask.Location()
+	if err != nil {
+		return nil, events.Event{}, err
+	}
+
+	description, err := recurringTask.RenderDescription(models.RecurringTaskRun{
+		Name:        recurringTask.Name,
+		ScheduledAt: scheduledAt.In(loc),
+		RunNumber:   recurringTask.RunCount + 1,
+	})
+	if err != nil {
+		return nil, events.Event{}, err
+	}
+
+	task := &models.Task{
+		CreatedBy:       recurringTask.CreatedBy,
+		Assignee:        recurringTask.Assignee,
+		Description:     description,
+		Priority:        recurringTask.Priority,
+		MaxAttempts:     1,
+		RecurringTaskID: &recurringTask.ID,
+	}
+
+	// Credentials определения расшифровываются и шифруются заново с ID экземпляра
+	credentials, err := openRecurringCredentials(s.cfg.CredentialsKeyring, recurringTask)
+	if err != nil {
+		return nil, events.Event{}, fmt.Errorf("failed to decrypt recurring task credentials: %w", err)
+	}
+
+	createdEvent, err := s.createSystemTask(ctx, repo, task, credentials)
+	if err != nil {
+		return nil, events.Event{}, err
+	}
+
+	recurringTask.RunCount++
+	recurringTask.LastRunAt = &now
+	recurringTask.LastTaskID = &task.ID
+
+	return task, createdEvent, nil
+}
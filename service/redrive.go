@@ -0,0 +1,99 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ListDeadLettered возвращает задачи в dead-letter, созданные пользователем или назначенные на него
+func (s *TaskService) ListDeadLettered(ctx context.Context, userID string) ([]models.Task, error) {
+	tasks, err := s.repo.ListDeadLettered(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-lettered tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// Redrive возвращает задачу из dead-letter в очередь от имени создателя со сброшенным счетчиком попыток
+func (s *TaskService) Redrive(ctx context.Context, userID string, taskID uuid.UUID) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу с блокировкой и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Перезапустить задачу может только её создатель
+		if task.CreatedBy != userID {
+			return newError(KindForbidden, "only creator can redrive the task")
+		}
+
+		if task.Status != models.StatusDeadLettered {
+			return newError(KindInvalid, "task must be in dead-lettered status to redrive").
+				withField("current_status", task.Status)
+		}
+
+		// Родительская задача должна всё ещё ожидать результата подзадачи.
+		// Родитель блокируется, чтобы его статус не изменился до коммита
+		var parentTask *models.Task
+		if task.ParentTaskID != nil {
+			parentTask, err = repo.LockTask(ctx, *task.ParentTaskID)
+			if err != nil {
+				return fmt.Errorf("failed to get parent task: %w", err)
+			}
+
+			if !isParentStatusAllowed(parentTask.Status) {
+				return newError(KindInvalid, "parent task must be in waiting, working or submitted status").
+					withField("parent_status", parentTask.Status)
+			}
+		}
+
+		// Сбрасываем счетчик попыток и возвращаем задачу в очередь
+		previousStatus := task.Status
+		task.Status = models.StatusSubmitted
+		task.Attempts = 0
+		task.NotBefore = nil
+		task.LeaseExpiresAt = nil
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		taskEvents = []events.Event{events.NewEvent(events.EventRedriven, *task, previousStatus, userID)}
+
+		// Родитель снова ожидает подзадачу, как при её создании: иначе он мог бы завершиться
+		// и каскадно отменить перезапущенную подзадачу
+		if parentTask != nil && parentTask.Status != models.StatusWaiting {
+			if err := repo.UpdateStatus(ctx, []uuid.UUID{parentTask.ID}, models.StatusWaiting); err != nil {
+				return fmt.Errorf("failed to update parent task status: %w", err)
+			}
+
+			parentPreviousStatus := parentTask.Status
+			parentTask.Status = models.StatusWaiting
+			parentTask.LeaseExpiresAt = nil
+			taskEvents = append(taskEvents, events.NewEvent(events.EventWaiting, *parentTask, parentPreviousStatus, userID))
+		}
+
+		// Записываем журнал событий в той же транзакции
+		if err := repo.RecordEvents(ctx, taskEvents); err != nil {
+			return fmt.Errorf("failed to record task events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Добавляем исполнителя в кэш и будим его, если он ожидает задачу
+	taskSubmitted(task)
+	events.Publish(taskEvents...)
+
+	return task, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Reject отклоняет задачу от имени исполнителя до начала работы и отменяет её подзадачи.
+// Если все подзадачи родителя завершены, родитель возвращается в очередь и получает отклоненную подзадачу при выдаче
+func (s *TaskService) Reject(ctx context.Context, userID string, taskID uuid.UUID, reason string) (*models.Task, error) {
+	var task *models.Task
+	var taskEvents []events.Event
+	var submitted []models.Task
+
+	err := s.repo.Transaction(ctx, func(repo TaskRepository) error {
+		// Получаем задачу и проверяем права
+		var err error
+		task, err = findTask(ctx, repo, taskID, true)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем, что пользователь является исполнителем задачи
+		if task.Assignee != userID {
+			return newError(KindForbidden, "only assignee can reject the task")
+		}
+
+		// Отклонить можно только задачу, которая еще не взята в работу
+		if task.Status != models.StatusSubmitted {
+			return newError(KindInvalid, "task must be in submitted status to reject").
+				withField("current_status", task.Status)
+		}
+
+		// Обновляем задачу
+		previousStatus := task.Status
+		task.Status = models.StatusRejected
+		task.Result = "REJECTION REASON: " + reason
+		task.NotBefore = nil
+
+		if err := repo.SaveTask(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		// Подзадачи отклоненной задачи больше не нужны
+		rejectedEvent := events.NewEvent(events.EventRejected, *task, previousStatus, userID)
+		taskEvents, submitted, err = finishWithSubtasks(ctx, repo, task, rejectedEvent, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	finishTransition(submitted, taskEvents)
+
+	return task, nil
+}
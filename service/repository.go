@@ -0,0 +1,97 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskRepository хранилище задач и журнала событий, на котором работает TaskService.
+// Методы, не находящие запись, возвращают ErrNotFound
+type TaskRepository interface {
+	// Transaction выполняет fn атомарно: изменения, сделанные через переданный репозиторий,
+	// применяются вместе, если fn вернула nil, и отменяются иначе
+	Transaction(ctx context.Context, fn func(repo TaskRepository) error) error
+
+	// GetTask возвращает задачу по ID
+	GetTask(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	// LockTask возвращает задачу по ID, блокируя её до конца транзакции
+	LockTask(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	// ClaimCandidate возвращает следующую задачу исполнителя для выдачи в работу, блокируя её до конца транзакции.
+	// Задачи в статусе submitted, время которых наступило, упорядочиваются по эффективному приоритету,
+	// затем по времени создания. Задачи, заблокированные другими транзакциями, пропускаются
+	ClaimCandidate(ctx context.Context, assignee string, now time.Time, agingInterval time.Duration) (*models.Task, error)
+
+	// CreateTask сохраняет новую задачу
+	CreateTask(ctx context.Context, task *models.Task) error
+	// SaveTask сохраняет все поля задачи
+	SaveTask(ctx context.Context, task *models.Task) error
+	// UpdateStatus переводит задачи в статус и снимает с них аренду
+	UpdateStatus(ctx context.Context, ids []uuid.UUID, status models.TaskStatus) error
+
+	// ListExpiredLeases возвращает задачи в работе, аренда которых истекла к now, блокируя их до конца транзакции.
+	// Задачи, заблокированные другими транзакциями, пропускаются
+	ListExpiredLeases(ctx context.Context, now time.Time) ([]models.Task, error)
+
+	// ListSubtasks возвращает подзадачи первого уровня в указанных статусах
+	ListSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error)
+	// CountSubtasks считает подзадачи первого уровня в указанных статусах, без статусов - все подзадачи
+	CountSubtasks(ctx context.Context, parentID uuid.UUID, statuses ...models.TaskStatus) (int64, error)
+	// CountAssigneeTasks считает задачи исполнителя в указанных статусах
+	CountAssigneeTasks(ctx context.Context, assignee string, statuses ...models.TaskStatus) (int64, error)
+
+	// AddDependencies сохраняет зависимости задачи от задач dependsOn
+	AddDependencies(ctx context.Context, taskID uuid.UUID, dependsOn []uuid.UUID) error
+	// ListDependencies возвращает зависимости указанных задач
+	ListDependencies(ctx context.Context, taskIDs []uuid.UUID) ([]models.TaskDependency, error)
+	// ListDependents возвращает задачи в указанных статусах, зависящие от задачи dependsOnID
+	ListDependents(ctx context.Context, dependsOnID uuid.UUID, statuses ...models.TaskStatus) ([]models.Task, error)
+	// CountPendingDependencies считает незавершенные (не completed) задачи, от которых зависит задача
+	CountPendingDependencies(ctx context.Context, taskID uuid.UUID) (int64, error)
+
+	// ListRootTasks возвращает корневые задачи, созданные пользователем
+	ListRootTasks(ctx context.Context, createdBy string) ([]models.Task, error)
+	// ListTreeTasks возвращает все задачи дерева корневой задачи
+	ListTreeTasks(ctx context.Context, rootTaskID uuid.UUID) ([]models.Task, error)
+	// ListDeadLettered возвращает задачи в dead-letter, созданные пользователем или назначенные на него, в порядке создания
+	ListDeadLettered(ctx context.Context, userID string) ([]models.Task, error)
+
+	// ListDueRecurringTasks возвращает ID включенных периодических задач, время запуска которых наступило к now
+	// или у которых есть запуски, отложенные политикой queue
+	ListDueRecurringTasks(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+	// LockRecurringTask возвращает периодическую задачу по ID, блокируя её до конца транзакции.
+	// Если строку уже заблокировала другая транзакция, возвращает ErrNotFound
+	LockRecurringTask(ctx context.Context, id uuid.UUID) (*models.RecurringTask, error)
+	// SaveRecurringTask сохраняет все поля периодической задачи
+	SaveRecurringTask(ctx context.Context, recurringTask *models.RecurringTask) error
+	// CountRecurringInstances считает задачи, созданные по периодической задаче, в указанных статусах
+	CountRecurringInstances(ctx context.Context, recurringTaskID uuid.UUID, statuses ...models.TaskStatus) (int64, error)
+
+	// ListCredentialsToReencrypt возвращает задачи, credentials которых зашифрованы не ключом activeKeyID
+	// или хранятся в открытом виде, не больше limit задач, блокируя их до конца транзакции
+	ListCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.Task, error)
+	// SaveCredentials сохраняет открытые и зашифрованные credentials задачи, не затрагивая остальные поля
+	SaveCredentials(ctx context.Context, task *models.Task) error
+	// ListRecurringCredentialsToReencrypt возвращает периодические задачи, credentials которых зашифрованы не ключом activeKeyID
+	// или хранятся открытыми, блокируя их до конца транзакции
+	ListRecurringCredentialsToReencrypt(ctx context.Context, activeKeyID string, limit int) ([]models.RecurringTask, error)
+	// SaveRecurringCredentials сохраняет открытые и зашифрованные credentials периодической задачи, не затрагивая остальные поля
+	SaveRecurringCredentials(ctx context.Context, recurringTask *models.RecurringTask) error
+
+	// RecordEvents записывает события в журнал task_events и присваивает им ID строк журнала
+	RecordEvents(ctx context.Context, evts []events.Event) error
+	// ListTaskEvents возвращает журнал переходов задачи в порядке записи
+	ListTaskEvents(ctx context.Context, taskID uuid.UUID) ([]models.TaskEvent, error)
+	// ListRootTaskEvents возвращает до limit событий дерева корневой задачи с ID больше afterID в порядке ID
+	ListRootTaskEvents(ctx context.Context, rootTaskID uuid.UUID, afterID int64, limit int) ([]models.TaskEvent, error)
+	// ListTaskEventsByID возвращает события журнала по ID в порядке ID
+	ListTaskEventsByID(ctx context.Context, ids []int64) ([]models.TaskEvent, error)
+
+	// RecordCredentialAccess добавляет запись в журнал выдачи credentials. Изменять и удалять записи журнала нельзя
+	RecordCredentialAccess(ctx context.Context, access *models.CredentialAccess) error
+	// ListCredentialAccess возвращает записи журнала выдачи credentials по фильтру, начиная с последних
+	ListCredentialAccess(ctx context.Context, filter CredentialAccessFilter) ([]models.CredentialAccess, error)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaURL адрес, под которым схема задачи добавляется в компилятор
+const schemaURL = "urn:agent-task-manager:schema"
+
+// hasPayload сообщает, что JSON-значение передано и не равно null
+func hasPayload(payload json.RawMessage) bool {
+	return len(payload) > 0 && !bytes.Equal(bytes.TrimSpace(payload), []byte("null"))
+}
+
+// nullIfEmpty возвращает nil для отсутствующего значения или null, чтобы не хранить null в jsonb
+func nullIfEmpty(payload json.RawMessage) json.RawMessage {
+	if !hasPayload(payload) {
+		return nil
+	}
+	return payload
+}
+
+// compileSchema компилирует JSON Schema (по умолчанию draft 2020-12).
+// Внешние $ref не загружаются ни из сети, ни из файловой системы: схема должна быть самодостаточной
+func compileSchema(raw json.RawMessage) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(schemaURL)
+}
+
+// validatePayload проверяет JSON-значение по схеме
+func validatePayload(schema *jsonschema.Schema, payload json.RawMessage) error {
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	err = schema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		// Первая строка содержит только адрес схемы, нарушения перечислены в следующих строках
+		_, violations, found := strings.Cut(validationErr.Error(), "\n")
+		if found {
+			return errors.New(violations)
+		}
+	}
+	return err
+}
+
+// errorLine переводит многострочную ошибку компиляции или валидации схемы в одну строку
+func errorLine(err error) string {
+	lines := strings.Split(err.Error(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimLeft(lines[i], " -")
+	}
+	return strings.Join(lines, "; ")
+}
+
+// checkPayload проверяет значение по необязательной схеме задачи. name - имя поля значения в ошибке
+func checkPayload(schemaRaw, payload json.RawMessage, name string) error {
+	if hasPayload(payload) && !json.Valid(payload) {
+		return newError(KindInvalid, name+" must be valid JSON")
+	}
+	if !hasPayload(schemaRaw) {
+		return nil
+	}
+
+	schema, err := compileSchema(schemaRaw)
+	if err != nil {
+		return newError(KindInvalid, fmt.Sprintf("invalid %s_schema: %s", name, errorLine(err)))
+	}
+	if !hasPayload(payload) {
+		return newError(KindInvalid, fmt.Sprintf("%s is required by the task's %s_schema", name, name))
+	}
+	if err := validatePayload(schema, payload); err != nil {
+		return newError(KindInvalid, fmt.Sprintf("%s does not match %s_schema: %s", name, name, errorLine(err)))
+	}
+	return nil
+}
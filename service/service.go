@@ -0,0 +1,85 @@
+package service
+
+import (
+	"agent-task-manager/cache"
+	"agent-task-manager/config"
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"agent-task-manager/notify"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskService бизнес-правила переходов задач, общие для HTTP, A2A, MCP и других транспортов.
+// Каждый переход выполняется в транзакции репозитория вместе с записью журнала событий,
+// а кэш, оповещения и рассылка событий обновляются после её успешного завершения
+type TaskService struct {
+	repo TaskRepository
+	cfg  *config.Config
+}
+
+// NewTaskService создает сервис задач поверх репозитория
+func NewTaskService(repo TaskRepository, cfg *config.Config) *TaskService {
+	return &TaskService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// activeAssigneeStatuses статусы, в которых задача числится за исполнителем в кэше
+var activeAssigneeStatuses = []models.TaskStatus{
+	models.StatusSubmitted,
+	models.StatusWorking,
+	models.StatusWaiting,
+}
+
+// findTask загружает задачу, переводя отсутствие записи в ошибку операции
+func findTask(ctx context.Context, repo TaskRepository, taskID uuid.UUID, lock bool) (*models.Task, error) {
+	var task *models.Task
+	var err error
+	if lock {
+		task, err = repo.LockTask(ctx, taskID)
+	} else {
+		task, err = repo.GetTask(ctx, taskID)
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, errTaskNotFound()
+		}
+		return nil, fmt.Errorf("failed to find task: %w", err)
+	}
+	return task, nil
+}
+
+// removeUserIfNoActiveTasks удаляет исполнителя из кэша, если у него не осталось активных задач
+func removeUserIfNoActiveTasks(ctx context.Context, repo TaskRepository, assignee string) {
+	activeTaskCount, err := repo.CountAssigneeTasks(ctx, assignee, activeAssigneeStatuses...)
+	if err == nil && activeTaskCount == 0 {
+		cache.RemoveUserWithTask(assignee)
+	}
+}
+
+// taskSubmitted добавляет исполнителя задачи в статусе submitted в кэш и будит его, если он ожидает задачу
+// в GET /task?wait=... Отложенные задачи и повторные попытки учитываются отдельно, пока не наступит их время
+func taskSubmitted(task *models.Task) {
+	if task.IsScheduled(time.Now()) {
+		cache.AddUserWithScheduledTask(task.Assignee, *task.AvailableAt())
+		return
+	}
+	cache.AddUserWithTask(task.Assignee)
+	notify.TaskSubmitted(task.Assignee)
+}
+
+// finishTransition выполняет действия после коммита перехода: будит исполнителей задач,
+// вернувшихся в очередь, и публикует события
+func finishTransition(submitted []models.Task, taskEvents []events.Event) {
+	for _, task := range submitted {
+		taskSubmitted(&task)
+	}
+	events.Publish(taskEvents...)
+}
@@ -0,0 +1,832 @@
+package service
+
+import (
+	"agent-task-manager/config"
+	"agent-task-manager/encryption"
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"agent-task-manager/secrets"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestService создает сервис задач поверх хранилища в памяти
+func newTestService(t *testing.T) (*TaskService, *MemoryRepository) {
+	t.Helper()
+	repo := NewMemoryRepository()
+	return NewTaskService(repo, &config.Config{LeaseTTL: time.Minute}), repo
+}
+
+// mustCreate создает задачу и завершает тест при ошибке
+func mustCreate(t *testing.T, svc *TaskService, userID string, req CreateTaskRequest) *models.Task {
+	t.Helper()
+	task, err := svc.Create(context.Background(), userID, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return task
+}
+
+// mustClaim берет задачу исполнителя в работу и завершает тест при ошибке
+func mustClaim(t *testing.T, svc *TaskService, userID string) *TaskWithSubtasks {
+	t.Helper()
+	claimed, err := svc.Claim(context.Background(), userID, ClaimOptions{})
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	return claimed
+}
+
+// mustGet возвращает задачу из хранилища
+func mustGet(t *testing.T, repo *MemoryRepository, taskID uuid.UUID) *models.Task {
+	t.Helper()
+	task, err := repo.GetTask(context.Background(), taskID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	return task
+}
+
+// assertKind проверяет вид ошибки операции
+func assertKind(t *testing.T, err error, kind ErrorKind) {
+	t.Helper()
+	var serviceErr *Error
+	if !errors.As(err, &serviceErr) || serviceErr.Kind != kind {
+		t.Fatalf("expected error of kind %d, got %v", kind, err)
+	}
+}
+
+// assertEventTypes проверяет журнал переходов задачи
+func assertEventTypes(t *testing.T, repo *MemoryRepository, taskID uuid.UUID, want ...string) {
+	t.Helper()
+	taskEvents, err := repo.ListTaskEvents(context.Background(), taskID)
+	if err != nil {
+		t.Fatalf("ListTaskEvents: %v", err)
+	}
+	if len(taskEvents) != len(want) {
+		t.Fatalf("expected events %v, got %d events", want, len(taskEvents))
+	}
+	for i, event := range taskEvents {
+		if event.Type != want[i] {
+			t.Fatalf("event %d: expected %s, got %s", i, want[i], event.Type)
+		}
+	}
+}
+
+func TestCreateClaimComplete(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	task := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "build", Assignee: "agent"})
+	if task.Status != models.StatusSubmitted || task.RootTaskID == nil || *task.RootTaskID != task.ID {
+		t.Fatalf("unexpected created task: status %s, root %v", task.Status, task.RootTaskID)
+	}
+
+	claimed := mustClaim(t, svc, "agent")
+	if claimed.ID != task.ID || claimed.Status != models.StatusWorking || claimed.LeaseExpiresAt == nil {
+		t.Fatalf("unexpected claimed task: %+v", claimed.Task)
+	}
+
+	_, err := svc.Complete(ctx, "stranger", task.ID, CompleteTaskRequest{Description: "done"})
+	assertKind(t, err, KindForbidden)
+
+	completed, err := svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "done"})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if completed.Status != models.StatusCompleted || completed.LeaseExpiresAt != nil {
+		t.Fatalf("unexpected completed task: %+v", completed)
+	}
+
+	_, err = svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "again"})
+	assertKind(t, err, KindInvalid)
+
+	assertEventTypes(t, repo, task.ID, "created", "claimed", "completed")
+}
+
+func TestSubtaskResubmitsParent(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	parent := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "parent", Assignee: "lead"})
+	mustClaim(t, svc, "lead")
+
+	subtask := mustCreate(t, svc, "lead", CreateTaskRequest{Description: "child", Assignee: "agent", ParentTaskID: &parent.ID})
+	if *subtask.RootTaskID != parent.ID {
+		t.Fatalf("subtask root: expected %s, got %s", parent.ID, *subtask.RootTaskID)
+	}
+	if status := mustGet(t, repo, parent.ID).Status; status != models.StatusWaiting {
+		t.Fatalf("parent status: expected waiting, got %s", status)
+	}
+
+	mustClaim(t, svc, "agent")
+	if _, err := svc.Complete(ctx, "agent", subtask.ID, CompleteTaskRequest{Description: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if status := mustGet(t, repo, parent.ID).Status; status != models.StatusSubmitted {
+		t.Fatalf("parent status: expected submitted, got %s", status)
+	}
+	assertEventTypes(t, repo, parent.ID, "created", "claimed", "waiting", "resubmitted")
+
+	claimed := mustClaim(t, svc, "lead")
+	if len(claimed.CompletedSubtasks) != 1 || claimed.CompletedSubtasks[0].ID != subtask.ID {
+		t.Fatalf("expected completed subtask in claim response, got %+v", claimed.CompletedSubtasks)
+	}
+}
+
+func TestFailRetriesThenDeadLetters(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	task := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description: "flaky",
+		Assignee:    "agent",
+		RetryPolicy: &RetryPolicy{MaxAttempts: 2, BackoffBaseSeconds: 1},
+	})
+
+	mustClaim(t, svc, "agent")
+	retried, err := svc.Fail(ctx, "agent", task.ID, FailTaskRequest{Reason: "timeout"})
+	if err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if retried.Status != models.StatusSubmitted || retried.NotBefore == nil {
+		t.Fatalf("expected delayed retry, got status %s", retried.Status)
+	}
+	if !retried.IsScheduled(time.Now()) {
+		t.Fatal("expected delayed retry to be scheduled until not_before")
+	}
+
+	// До истечения задержки задача не выдается
+	_, err = svc.Claim(ctx, "agent", ClaimOptions{})
+	assertKind(t, err, KindNotFound)
+}
+
+func TestRequeueExpired(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	task := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "slow", Assignee: "agent"})
+	mustClaim(t, svc, "agent")
+
+	// Аренда еще действует - возвращать нечего
+	requeued, err := svc.RequeueExpired(ctx, time.Now())
+	if err != nil || len(requeued) != 0 {
+		t.Fatalf("RequeueExpired before expiry: %v, %d tasks", err, len(requeued))
+	}
+
+	requeued, err = svc.RequeueExpired(ctx, time.Now().Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	if len(requeued) != 1 || requeued[0].ID != task.ID {
+		t.Fatalf("expected task %s to be requeued, got %d tasks", task.ID, len(requeued))
+	}
+
+	stored := mustGet(t, repo, task.ID)
+	if stored.Status != models.StatusSubmitted || stored.LeaseExpiresAt != nil || stored.RequeueCount != 1 {
+		t.Fatalf("unexpected requeued task: status %s, requeue count %d", stored.Status, stored.RequeueCount)
+	}
+	assertEventTypes(t, repo, task.ID, "created", "claimed", "requeued")
+
+	// Прежний исполнитель не может завершить задачу, вернувшуюся в очередь
+	_, err = svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "late"})
+	assertKind(t, err, KindInvalid)
+}
+
+func TestRunRecurringTask(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	dueAt := now.Add(-time.Minute)
+	recurringTask := &models.RecurringTask{
+		CreatedBy:           "manager",
+		Name:                "report",
+		CronExpression:      "*/5 * * * *",
+		Assignee:            "agent",
+		DescriptionTemplate: "{{.Name}} #{{.RunNumber}}",
+		OverlapPolicy:       models.OverlapQueue,
+		Enabled:             true,
+		NextRunAt:           &dueAt,
+	}
+	if err := repo.SaveRecurringTask(ctx, recurringTask); err != nil {
+		t.Fatalf("SaveRecurringTask: %v", err)
+	}
+
+	due, err := svc.DueRecurringTasks(ctx, now)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("DueRecurringTasks: %v, %d due", err, len(due))
+	}
+
+	run, err := svc.RunRecurringTask(ctx, recurringTask.ID, now)
+	if err != nil {
+		t.Fatalf("RunRecurringTask: %v", err)
+	}
+	if run.Task == nil {
+		t.Fatal("expected a task to be spawned")
+	}
+
+	spawned := mustGet(t, repo, run.Task.ID)
+	if spawned.Description != "report #1" || spawned.CreatedBy != "manager" ||
+		spawned.RootTaskID == nil || *spawned.RootTaskID != spawned.ID ||
+		spawned.RecurringTaskID == nil || *spawned.RecurringTaskID != recurringTask.ID {
+		t.Fatalf("unexpected spawned task: %+v", spawned)
+	}
+	assertEventTypes(t, repo, spawned.ID, "created")
+
+	stored, err := repo.LockRecurringTask(ctx, recurringTask.ID)
+	if err != nil {
+		t.Fatalf("LockRecurringTask: %v", err)
+	}
+	if stored.RunCount != 1 || stored.NextRunAt == nil || !stored.NextRunAt.After(now) {
+		t.Fatalf("unexpected scheduler state: run count %d, next run %v", stored.RunCount, stored.NextRunAt)
+	}
+
+	// Следующий запуск при активном экземпляре откладывается политикой queue
+	nextRun := stored.NextRunAt.Add(time.Second)
+	run, err = svc.RunRecurringTask(ctx, recurringTask.ID, nextRun)
+	if err != nil || !run.Queued || run.Task != nil {
+		t.Fatalf("expected queued run, got %+v, %v", run, err)
+	}
+
+	// После завершения экземпляра отложенный запуск создает новую задачу
+	mustClaim(t, svc, "agent")
+	if _, err := svc.Complete(ctx, "agent", spawned.ID, CompleteTaskRequest{Description: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	run, err = svc.RunRecurringTask(ctx, recurringTask.ID, nextRun)
+	if err != nil || run.Task == nil || run.Task.Description != "report #2" {
+		t.Fatalf("expected queued run to spawn, got %+v, %v", run, err)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	// Зависимость от несуществующей задачи отклоняется, и задача не создается
+	missing := uuid.New()
+	taskID := uuid.New()
+	_, err := svc.Create(ctx, "manager", CreateTaskRequest{
+		Description: "blocked",
+		Assignee:    "agent",
+		DependsOn:   []uuid.UUID{missing},
+		TaskID:      &taskID,
+	})
+	if err == nil {
+		t.Fatal("expected error for missing dependency")
+	}
+	if _, err := repo.GetTask(ctx, taskID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("task must not be stored after rollback, got %v", err)
+	}
+}
+
+func TestDependencyFailureFailsDependents(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	// Цепочка build <- deploy <- notify: отмена build завершает неудачей обе ожидающие задачи
+	build := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "build", Assignee: "agent"})
+	deploy := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "deploy", Assignee: "agent", DependsOn: []uuid.UUID{build.ID}})
+	notify := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "notify", Assignee: "agent", DependsOn: []uuid.UUID{deploy.ID}})
+	if deploy.Status != models.StatusWaiting || notify.Status != models.StatusWaiting {
+		t.Fatalf("dependents must wait: deploy %s, notify %s", deploy.Status, notify.Status)
+	}
+
+	if _, err := svc.Cancel(ctx, "manager", build.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	for _, task := range []*models.Task{deploy, notify} {
+		stored := mustGet(t, repo, task.ID)
+		if stored.Status != models.StatusFailed {
+			t.Fatalf("dependent %s: expected failed, got %s", task.Description, stored.Status)
+		}
+		assertEventTypes(t, repo, task.ID, "created", "failed")
+	}
+	if result := mustGet(t, repo, deploy.ID).Result; result != "FAILURE REASON: dependency "+build.ID.String()+" ended with status canceled" {
+		t.Fatalf("unexpected failure reason: %s", result)
+	}
+
+	// Новая задача не может зависеть от уже отмененной задачи
+	_, err := svc.Create(ctx, "manager", CreateTaskRequest{Description: "late", Assignee: "agent", DependsOn: []uuid.UUID{build.ID}})
+	assertKind(t, err, KindInvalid)
+}
+
+func TestDependencyCompletionUnblocksDependent(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	build := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "build", Assignee: "agent"})
+	deploy := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "deploy", Assignee: "agent", DependsOn: []uuid.UUID{build.ID}})
+
+	mustClaim(t, svc, "agent")
+	if _, err := svc.Complete(ctx, "agent", build.ID, CompleteTaskRequest{Description: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if status := mustGet(t, repo, deploy.ID).Status; status != models.StatusSubmitted {
+		t.Fatalf("dependent status: expected submitted, got %s", status)
+	}
+	assertEventTypes(t, repo, deploy.ID, "created", "unblocked")
+}
+
+func TestDependencyCycleThroughParentAndSubtasks(t *testing.T) {
+	ctx := context.Background()
+
+	// setup создает задачи и возвращает зависимость для новой подзадачи parent
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID
+		wantErr bool
+	}{
+		{
+			name: "dependency is the parent",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				return parent.ID
+			},
+			wantErr: true,
+		},
+		{
+			name: "dependency waits for the parent",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				return mustCreate(t, svc, "manager", CreateTaskRequest{Description: "report", Assignee: "agent", DependsOn: []uuid.UUID{parent.ID}}).ID
+			},
+			wantErr: true,
+		},
+		{
+			name: "dependency waits for the parent transitively",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				report := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "report", Assignee: "agent", DependsOn: []uuid.UUID{parent.ID}})
+				return mustCreate(t, svc, "manager", CreateTaskRequest{Description: "publish", Assignee: "agent", DependsOn: []uuid.UUID{report.ID}}).ID
+			},
+			wantErr: true,
+		},
+		{
+			name: "subtask of the dependency waits for the parent",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				release := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "release", Assignee: "agent"})
+				mustCreate(t, svc, "manager", CreateTaskRequest{Description: "changelog", Assignee: "agent", ParentTaskID: &release.ID, DependsOn: []uuid.UUID{parent.ID}})
+				return release.ID
+			},
+			wantErr: true,
+		},
+		{
+			name: "canceled subtask no longer waits",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				release := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "release", Assignee: "agent"})
+				changelog := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "changelog", Assignee: "agent", ParentTaskID: &release.ID, DependsOn: []uuid.UUID{parent.ID}})
+				if _, err := svc.Cancel(ctx, "manager", changelog.ID); err != nil {
+					t.Fatalf("Cancel: %v", err)
+				}
+				return release.ID
+			},
+		},
+		{
+			name: "independent dependency",
+			setup: func(t *testing.T, svc *TaskService, parent *models.Task) uuid.UUID {
+				return mustCreate(t, svc, "manager", CreateTaskRequest{Description: "build", Assignee: "agent"}).ID
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t)
+			parent := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "deploy", Assignee: "agent"})
+			dependsOn := tt.setup(t, svc, parent)
+
+			subtask, err := svc.Create(ctx, "manager", CreateTaskRequest{
+				Description:  "migrate",
+				Assignee:     "helper",
+				ParentTaskID: &parent.ID,
+				DependsOn:    []uuid.UUID{dependsOn},
+			})
+			if tt.wantErr {
+				assertKind(t, err, KindInvalid)
+				if !strings.Contains(err.Error(), "dependency cycle") {
+					t.Fatalf("expected dependency cycle error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if subtask.Status != models.StatusWaiting {
+				t.Fatalf("expected subtask to wait for its dependency, got %s", subtask.Status)
+			}
+		})
+	}
+}
+
+func TestRecurringCredentialsEncryption(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	newKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	oldKeyring, err := encryption.ParseKeyring("old:"+oldKey, "")
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	credentials := json.RawMessage(`{"github":{"GITHUB_TOKEN":"secret"}}`)
+
+	// Определение, зашифрованное прежним ключом, и определение, созданное до включения шифрования
+	dueAt := now.Add(-time.Minute)
+	sealed := &models.RecurringTask{
+		ID:                  uuid.New(),
+		CreatedBy:           "manager",
+		Name:                "sync",
+		CronExpression:      "0 * * * *",
+		Assignee:            "agent",
+		DescriptionTemplate: "sync",
+		Enabled:             true,
+		NextRunAt:           &dueAt,
+	}
+	if err := SealRecurringCredentials(oldKeyring, sealed, credentials); err != nil {
+		t.Fatalf("SealRecurringCredentials: %v", err)
+	}
+	if len(sealed.Credentials) != 0 || sealed.CredentialsKeyID != "old" {
+		t.Fatalf("credentials must be stored encrypted, got key %q", sealed.CredentialsKeyID)
+	}
+	plain := &models.RecurringTask{
+		ID:                  uuid.New(),
+		CreatedBy:           "manager",
+		Name:                "legacy",
+		CronExpression:      "0 * * * *",
+		DescriptionTemplate: "legacy",
+		Credentials:         credentials,
+	}
+	for _, recurringTask := range []*models.RecurringTask{sealed, plain} {
+		if err := repo.SaveRecurringTask(ctx, recurringTask); err != nil {
+			t.Fatalf("SaveRecurringTask: %v", err)
+		}
+	}
+
+	// Ротация: новый ключ активен, прежний нужен для расшифровки до перешифрования
+	svc.cfg.CredentialsKeyring, err = encryption.ParseKeyring("new:"+newKey+",old:"+oldKey, "new")
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	result, err := svc.ReencryptCredentials(ctx, 1)
+	if err != nil {
+		t.Fatalf("ReencryptCredentials: %v", err)
+	}
+	if result.RecurringRewrapped != 1 || result.RecurringEncrypted != 1 {
+		t.Fatalf("unexpected re-encryption result: %+v", result)
+	}
+	for _, id := range []uuid.UUID{sealed.ID, plain.ID} {
+		stored, err := repo.LockRecurringTask(ctx, id)
+		if err != nil {
+			t.Fatalf("LockRecurringTask: %v", err)
+		}
+		if len(stored.Credentials) != 0 || stored.CredentialsKeyID != "new" {
+			t.Fatalf("recurring task %s: expected credentials encrypted with the new key, got %q", stored.Name, stored.CredentialsKeyID)
+		}
+	}
+
+	// Экземпляр получает расшифрованные credentials определения, зашифрованные уже со своим ID
+	run, err := svc.RunRecurringTask(ctx, sealed.ID, now)
+	if err != nil || run.Task == nil {
+		t.Fatalf("RunRecurringTask: %+v, %v", run, err)
+	}
+	if spawned := mustGet(t, repo, run.Task.ID); len(spawned.Credentials) != 0 || spawned.CredentialsKeyID != "new" {
+		t.Fatalf("spawned task credentials must be encrypted, got key %q", spawned.CredentialsKeyID)
+	}
+	claimed := mustClaim(t, svc, "agent")
+	var got, want map[string]map[string]string
+	if err := json.Unmarshal(claimed.Credentials, &got); err != nil {
+		t.Fatalf("claimed credentials: %v", err)
+	}
+	json.Unmarshal(credentials, &want)
+	if got["github"]["GITHUB_TOKEN"] != want["github"]["GITHUB_TOKEN"] {
+		t.Fatalf("unexpected claimed credentials: %s", claimed.Credentials)
+	}
+}
+
+func TestTreeEventsResumeFromJournal(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	task := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "build", Assignee: "agent"})
+	mustClaim(t, svc, "agent")
+
+	createdEvents, err := svc.RootTaskEventsSince(ctx, task.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("RootTaskEventsSince: %v", err)
+	}
+	if len(createdEvents) != 2 {
+		t.Fatalf("expected 2 tree events, got %d", len(createdEvents))
+	}
+
+	// Клиент получил только событие создания и возобновляет поток после него
+	replay, complete, stream, unsubscribe, err := events.Subscribe(task.ID, createdEvents[0].ID, func(afterID int64, limit int) ([]events.Event, error) {
+		return svc.RootTaskEventsSince(ctx, task.ID, afterID, limit)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer unsubscribe()
+
+	if !complete || len(replay) != 1 || replay[0].Type != events.EventClaimed {
+		t.Fatalf("unexpected replay: complete %v, events %+v", complete, replay)
+	}
+	if replay[0].Task.Description != "build" || replay[0].Task.Status != models.StatusWorking {
+		t.Fatalf("replayed event has no task snapshot: %+v", replay[0].Task)
+	}
+
+	if _, err := svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	completed := <-stream
+	if completed.Type != events.EventCompleted || completed.ID <= replay[0].ID {
+		t.Fatalf("unexpected live event: %+v", completed)
+	}
+
+	// Оповещение Postgres о том же событии не приводит к повторной отправке
+	events.NotificationHandler(svc.TaskEventsByID)(task.ID.String() + ":" + strconv.FormatInt(completed.ID, 10))
+	select {
+	case duplicate := <-stream:
+		t.Fatalf("event %d delivered twice", duplicate.ID)
+	default:
+	}
+}
+
+func TestRedriveReturnsParentToWaiting(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	parent := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description:    "parent",
+		Assignee:       "lead",
+		OnChildFailure: models.ChildFailureResubmitParent,
+	})
+	mustClaim(t, svc, "lead")
+
+	subtask := mustCreate(t, svc, "lead", CreateTaskRequest{
+		Description:  "child",
+		Assignee:     "agent",
+		ParentTaskID: &parent.ID,
+		RetryPolicy:  &RetryPolicy{MaxAttempts: 2},
+	})
+	mustDeadLetter(t, svc, repo, "agent", subtask.ID)
+
+	for userID, want := range map[string]int{"lead": 1, "agent": 1, "manager": 0} {
+		deadLettered, err := svc.ListDeadLettered(ctx, userID)
+		if err != nil {
+			t.Fatalf("ListDeadLettered: %v", err)
+		}
+		if len(deadLettered) != want {
+			t.Fatalf("dead-lettered tasks of %s: expected %d, got %d", userID, want, len(deadLettered))
+		}
+	}
+
+	// Родитель вернулся в очередь и снова взят в работу
+	mustClaim(t, svc, "lead")
+
+	if _, err := svc.Redrive(ctx, "lead", subtask.ID); err != nil {
+		t.Fatalf("Redrive: %v", err)
+	}
+	if status := mustGet(t, repo, parent.ID).Status; status != models.StatusWaiting {
+		t.Fatalf("parent status: expected waiting, got %s", status)
+	}
+	assertEventTypes(t, repo, parent.ID, "created", "claimed", "waiting", "resubmitted", "claimed", "waiting")
+
+	// Завершившийся родитель не позволяет перезапустить подзадачу
+	mustDeadLetter(t, svc, repo, "agent", subtask.ID)
+	mustClaim(t, svc, "lead")
+	if _, err := svc.Complete(ctx, "lead", parent.ID, CompleteTaskRequest{Description: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	_, err := svc.Redrive(ctx, "lead", subtask.ID)
+	assertKind(t, err, KindInvalid)
+}
+
+// mustDeadLetter исчерпывает попытки задачи с политикой повторов, не дожидаясь задержек между ними
+func mustDeadLetter(t *testing.T, svc *TaskService, repo *MemoryRepository, userID string, taskID uuid.UUID) {
+	t.Helper()
+	for {
+		mustClaim(t, svc, userID)
+		task, err := svc.Fail(context.Background(), userID, taskID, FailTaskRequest{Reason: "broken"})
+		if err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+		if task.Status == models.StatusDeadLettered {
+			return
+		}
+		if task.Status != models.StatusSubmitted {
+			t.Fatalf("expected retry or dead-letter, got status %s", task.Status)
+		}
+
+		task.NotBefore = nil
+		if err := repo.SaveTask(context.Background(), task); err != nil {
+			t.Fatalf("SaveTask: %v", err)
+		}
+	}
+}
+
+func TestSecretReferencesRequireAccessPolicy(t *testing.T) {
+	t.Setenv("ATM_SECRET_TEAM_DB", "s3cret")
+	policy, err := secrets.ParseAccessPolicy("manager=secretref://team/")
+	if err != nil {
+		t.Fatalf("ParseAccessPolicy: %v", err)
+	}
+	svc, _ := newTestService(t)
+	svc.cfg.SecretResolver = secrets.NewResolver(secrets.NewEnvStore("ATM_SECRET_"), nil, policy)
+	credentials := json.RawMessage(`{"db": {"PASSWORD": "secretref://team/db"}}`)
+
+	// Пользователь без доступа к секрету не может сослаться на него даже в задаче для себя
+	_, err = svc.Create(context.Background(), "intruder", CreateTaskRequest{Description: "steal", Assignee: "intruder", Credentials: credentials})
+	assertKind(t, err, KindInvalid)
+
+	parent := mustCreate(t, svc, "manager", CreateTaskRequest{Description: "migrate", Assignee: "agent", Credentials: credentials})
+	claimed := mustClaim(t, svc, "agent")
+	if !bytes.Contains(claimed.Credentials, []byte("s3cret")) {
+		t.Fatalf("expected resolved secret, got %s", claimed.Credentials)
+	}
+
+	// Унаследованная ссылка разрешается для создателя родителя
+	mustCreate(t, svc, "agent", CreateTaskRequest{
+		Description:        "subtask",
+		Assignee:           "helper",
+		ParentTaskID:       &parent.ID,
+		InheritCredentials: []string{"db"},
+	})
+	claimed = mustClaim(t, svc, "helper")
+	if !bytes.Contains(claimed.Credentials, []byte("s3cret")) {
+		t.Fatalf("expected inherited secret, got %s", claimed.Credentials)
+	}
+}
+
+// unavailableStore хранилище секретов, которое временно недоступно
+type unavailableStore struct{}
+
+func (unavailableStore) Get(ctx context.Context, name string) (string, error) {
+	return "", secrets.ErrUnavailable
+}
+
+func TestClaimDeadLettersTaskWithUnresolvableCredentials(t *testing.T) {
+	t.Setenv("ATM_SECRET_TEAM_DB", "s3cret")
+	policy, err := secrets.ParseAccessPolicy("manager=secretref://team/")
+	if err != nil {
+		t.Fatalf("ParseAccessPolicy: %v", err)
+	}
+	svc, repo := newTestService(t)
+	svc.cfg.SecretResolver = secrets.NewResolver(secrets.NewEnvStore("ATM_SECRET_"), nil, policy)
+
+	broken := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description: "broken",
+		Assignee:    "agent",
+		Credentials: json.RawMessage(`{"db": {"PASSWORD": "secretref://team/missing"}}`),
+	})
+	valid := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description: "valid",
+		Assignee:    "agent",
+		Credentials: json.RawMessage(`{"db": {"PASSWORD": "secretref://team/db"}}`),
+	})
+
+	// Задача с отсутствующим секретом не блокирует очередь: она уходит в dead-letter, выдается следующая
+	claimed := mustClaim(t, svc, "agent")
+	if claimed.ID != valid.ID {
+		t.Fatalf("expected task %s to be claimed, got %s", valid.ID, claimed.ID)
+	}
+
+	task := mustGet(t, repo, broken.ID)
+	if task.Status != models.StatusDeadLettered {
+		t.Fatalf("expected status %s, got %s", models.StatusDeadLettered, task.Status)
+	}
+	if !strings.HasPrefix(task.Result, "FAILURE REASON: ") {
+		t.Fatalf("expected failure reason in result, got %q", task.Result)
+	}
+	assertEventTypes(t, repo, broken.ID, "created", "failed")
+
+	// Если других задач нет, неудача сохраняется, а исполнитель получает ответ об отсутствии задач
+	mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description: "broken again",
+		Assignee:    "agent",
+		Credentials: json.RawMessage(`{"db": {"PASSWORD": "secretref://team/missing"}}`),
+	})
+	_, err = svc.Claim(context.Background(), "agent", ClaimOptions{})
+	assertKind(t, err, KindNotFound)
+	deadLettered, err := svc.ListDeadLettered(context.Background(), "manager")
+	if err != nil {
+		t.Fatalf("ListDeadLettered: %v", err)
+	}
+	if len(deadLettered) != 2 {
+		t.Fatalf("expected 2 dead-lettered tasks, got %d", len(deadLettered))
+	}
+
+	// Недоступное хранилище секретов - временная ошибка: задача остается в очереди
+	svc.cfg.SecretResolver = secrets.NewResolver(unavailableStore{}, nil, policy)
+	pending := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description: "pending",
+		Assignee:    "agent",
+		Credentials: json.RawMessage(`{"db": {"PASSWORD": "secretref://team/db"}}`),
+	})
+	if _, err := svc.Claim(context.Background(), "agent", ClaimOptions{}); !errors.Is(err, secrets.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if task := mustGet(t, repo, pending.ID); task.Status != models.StatusSubmitted {
+		t.Fatalf("expected status %s, got %s", models.StatusSubmitted, task.Status)
+	}
+}
+
+func TestCheckPayload(t *testing.T) {
+	// Сервер схем: внешний $ref не должен загружаться
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type": "object"}`))
+	}))
+	defer server.Close()
+
+	objectSchema := `{"type": "object", "properties": {"repo": {"type": "string"}}, "required": ["repo"], "additionalProperties": false}`
+
+	tests := []struct {
+		name    string
+		schema  string
+		payload string
+		wantErr string
+	}{
+		{name: "no schema", payload: `[1, 2]`},
+		{name: "no schema and no payload"},
+		{name: "invalid JSON without schema", payload: `{"repo":`, wantErr: "must be valid JSON"},
+		{name: "matching object", schema: objectSchema, payload: `{"repo": "api"}`},
+		{name: "missing required property", schema: objectSchema, payload: `{}`, wantErr: "does not match input_schema"},
+		{name: "unexpected property", schema: objectSchema, payload: `{"repo": "api", "force": true}`, wantErr: "does not match input_schema"},
+		{name: "wrong type", schema: objectSchema, payload: `{"repo": 1}`, wantErr: "does not match input_schema"},
+		{name: "null payload", schema: objectSchema, payload: `null`, wantErr: "input is required"},
+		{name: "missing payload", schema: objectSchema, wantErr: "input is required"},
+		{name: "non-object value", schema: `{"type": "array", "items": {"type": "integer"}}`, payload: `[1, 2]`},
+		{name: "local ref", schema: `{"$defs": {"repo": {"type": "string"}}, "$ref": "#/$defs/repo"}`, payload: `"api"`},
+		{name: "boolean schema", schema: `false`, payload: `{}`, wantErr: "does not match input_schema"},
+		{name: "invalid schema", schema: `{"type": "nonsense"}`, payload: `{}`, wantErr: "invalid input_schema"},
+		{name: "remote ref", schema: `{"$ref": "` + server.URL + `/schema.json"}`, payload: `{}`, wantErr: "invalid input_schema"},
+		{name: "file ref", schema: `{"$ref": "file:///etc/passwd"}`, payload: `{}`, wantErr: "invalid input_schema"},
+		{name: "remote metaschema", schema: `{"$schema": "` + server.URL + `/meta.json", "type": "object"}`, payload: `{}`, wantErr: "invalid input_schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPayload(json.RawMessage(tt.schema), json.RawMessage(tt.payload), "input")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected payload to be accepted, got %v", err)
+				}
+				return
+			}
+			assertKind(t, err, KindInvalid)
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if requests != 0 {
+		t.Fatalf("expected no schema requests, got %d", requests)
+	}
+}
+
+func TestCompleteValidatesOutput(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := context.Background()
+
+	// Невалидная или внешняя схема результата отклоняется уже при создании
+	for _, schema := range []string{`{"type": 1}`, `{"$ref": "https://example.com/output.json"}`} {
+		_, err := svc.Create(ctx, "manager", CreateTaskRequest{Description: "report", Assignee: "agent", OutputSchema: json.RawMessage(schema)})
+		assertKind(t, err, KindInvalid)
+	}
+
+	task := mustCreate(t, svc, "manager", CreateTaskRequest{
+		Description:  "report",
+		Assignee:     "agent",
+		OutputSchema: json.RawMessage(`{"type": "object", "required": ["coverage"], "properties": {"coverage": {"type": "number"}}}`),
+	})
+	mustClaim(t, svc, "agent")
+
+	for _, output := range []string{``, `{"coverage": "high"}`} {
+		_, err := svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "done", Output: json.RawMessage(output)})
+		assertKind(t, err, KindInvalid)
+	}
+	if status := mustGet(t, repo, task.ID).Status; status != models.StatusWorking {
+		t.Fatalf("expected rejected output to keep the task working, got %s", status)
+	}
+
+	if _, err := svc.Complete(ctx, "agent", task.ID, CompleteTaskRequest{Description: "done", Output: json.RawMessage(`{"coverage": 0.93}`)}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ListRootTasks возвращает корневые задачи, созданные пользователем
+func (s *TaskService) ListRootTasks(ctx context.Context, userID string) ([]models.Task, error) {
+	tasks, err := s.repo.ListRootTasks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch root tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// GetRootTask возвращает корневую задачу, если пользователь является её создателем.
+// Дерево задач и его события доступны только создателю корневой задачи
+func (s *TaskService) GetRootTask(ctx context.Context, userID string, rootTaskID uuid.UUID) (*models.Task, error) {
+	rootTask, err := s.repo.GetTask(ctx, rootTaskID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, newError(KindNotFound, "root task not found")
+		}
+		return nil, fmt.Errorf("failed to find root task: %w", err)
+	}
+
+	if rootTask.CreatedBy != userID {
+		return nil, newError(KindForbidden, "access denied: you are not the creator of this root task")
+	}
+
+	return rootTask, nil
+}
+
+// GetTree возвращает все задачи дерева корневой задачи, созданной пользователем, с их зависимостями
+func (s *TaskService) GetTree(ctx context.Context, userID string, rootTaskID uuid.UUID) ([]models.Task, error) {
+	if _, err := s.GetRootTask(ctx, userID, rootTaskID); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.ListTreeTasks(ctx, rootTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	if err := attachDependencies(ctx, s.repo, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// RootTaskEventsSince возвращает до limit событий дерева корневой задачи из журнала с ID больше afterID.
+// Используется для возобновления потока событий по Last-Event-ID
+func (s *TaskService) RootTaskEventsSince(ctx context.Context, rootTaskID uuid.UUID, afterID int64, limit int) ([]events.Event, error) {
+	rows, err := s.repo.ListRootTaskEvents(ctx, rootTaskID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tree events: %w", err)
+	}
+	return eventsFromRows(rows)
+}
+
+// TaskEventsByID возвращает события журнала по ID. Используется для рассылки событий других реплик
+func (s *TaskService) TaskEventsByID(ctx context.Context, ids []int64) ([]events.Event, error) {
+	rows, err := s.repo.ListTaskEventsByID(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task events: %w", err)
+	}
+	return eventsFromRows(rows)
+}
+
+// eventsFromRows восстанавливает события из строк журнала
+func eventsFromRows(rows []models.TaskEvent) ([]events.Event, error) {
+	taskEvents := make([]events.Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := events.FromTaskEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		taskEvents = append(taskEvents, event)
+	}
+	return taskEvents, nil
+}
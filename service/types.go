@@ -0,0 +1,112 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateTaskRequest структура для запроса создания задачи
+type CreateTaskRequest struct {
+	Description  string          `json:"description" binding:"required" description:"Task description for the assignee"`
+	Assignee     string          `json:"assignee" description:"User ID of the agent that will execute the task"`
+	ParentTaskID *uuid.UUID      `json:"parent_task_id" description:"Parent task ID; the parent moves to waiting until its subtasks finish"`
+	DeleteAt     *time.Time      `json:"delete_at" description:"Deletion time, default is 3 months from now"`
+	Credentials  json.RawMessage `json:"credentials" description:"Credentials for the assignee: {\"service\": {\"ENV_VAR\": \"value\"}}; a value may be a secretref://name or file:///path reference resolved only when the task is claimed"`
+	RetryPolicy  *RetryPolicy    `json:"retry_policy" description:"Retry policy for failed attempts"`
+	Priority     *int            `json:"priority" description:"Priority from -100 to 100, higher is handed out first; subtasks inherit the parent's priority by default"` // Если не указан, подзадача наследует приоритет родителя
+	RunAt        *time.Time      `json:"run_at" description:"The task is not handed out before this time"`                                                                // Задача не будет выдана исполнителю раньше этого времени
+	DependsOn    []uuid.UUID     `json:"depends_on" description:"IDs of tasks that must be completed before this task is handed out; until then the task is waiting"`
+
+	// InheritCredentials сервисы из credentials родителя, которые подзадача получает вместе с собственными credentials
+	InheritCredentials []string `json:"inherit_credentials" description:"Names of the parent task's credential services the subtask inherits, e.g. [\"github\"]; requires parent_task_id and may only be granted by the parent's assignee or creator"`
+
+	// Структурированные входные данные и схемы входных данных и результата
+	Input        json.RawMessage `json:"input" description:"Structured task input (any JSON value), validated against input_schema"`
+	InputSchema  json.RawMessage `json:"input_schema" description:"JSON Schema (draft 2020-12 by default) the input must match; external $ref is not resolved"`
+	OutputSchema json.RawMessage `json:"output_schema" description:"JSON Schema the output must match when the task is completed; external $ref is not resolved"`
+
+	// OnChildFailure реакция задачи на окончательную неудачу подзадачи, по умолчанию wait
+	OnChildFailure models.ChildFailurePolicy `json:"on_child_failure" description:"What happens to this task when one of its subtasks finally fails: wait (default), resubmit_parent, fail_parent or cancel_siblings"`
+
+	// TaskID задает ID новой задачи при создании через другие протоколы (A2A), через HTTP API не принимается
+	TaskID *uuid.UUID `json:"-"`
+}
+
+// RetryPolicy политика повторных попыток выполнения задачи
+type RetryPolicy struct {
+	MaxAttempts        int `json:"max_attempts" description:"Total number of attempts including the first one"`              // Общее количество попыток, включая первую
+	BackoffBaseSeconds int `json:"backoff_base_seconds" description:"Delay before the first retry, doubled on each attempt"` // Задержка перед первым повтором, удваивается с каждой попыткой
+	MaxDelaySeconds    int `json:"max_delay_seconds" description:"Maximum delay between attempts"`                           // Максимальная задержка между попытками
+}
+
+// CompleteTaskRequest структура для запроса завершения задачи
+type CompleteTaskRequest struct {
+	Description string          `json:"description" binding:"required" description:"Task result"`
+	Output      json.RawMessage `json:"output" description:"Structured task result (any JSON value); required and validated if the task has an output_schema"`
+	DeleteAt    *time.Time      `json:"delete_at" description:"New deletion time of the task"`
+}
+
+// FailTaskRequest структура для запроса неудачного завершения задачи
+type FailTaskRequest struct {
+	Reason string `json:"reason" binding:"required" description:"Failure reason"`
+}
+
+// RequestInputRequest структура для запроса дополнительных данных у создателя задачи
+type RequestInputRequest struct {
+	Question string `json:"question" binding:"required"`
+}
+
+// ProvideInputRequest структура для ответа создателя на запрос дополнительных данных
+type ProvideInputRequest struct {
+	Answer string `json:"answer" binding:"required"`
+}
+
+// RejectTaskRequest структура для запроса отклонения задачи исполнителем
+type RejectTaskRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// TaskWithSubtasks структура для ответа с задачей и её завершенными, отклоненными и неудавшимися подзадачами
+type TaskWithSubtasks struct {
+	models.Task
+	// Credentials задачи для исполнителя: собственные и унаследованные от родителя, расшифрованные
+	// и с подставленными секретами. Единственный ответ, в котором credentials раскрываются
+	Credentials json.RawMessage `json:"credentials,omitempty"`
+
+	CompletedSubtasks []models.Task `json:"completed_subtasks,omitempty"`
+	RejectedSubtasks  []models.Task `json:"rejected_subtasks,omitempty"`
+	FailedSubtasks    []models.Task `json:"failed_subtasks,omitempty"` // Причина неудачи в result
+	Children          []TaskNode    `json:"children,omitempty"`        // Подзадачи всех статусов, если запрошены через ClaimOptions
+}
+
+// ClaimOptions параметры ответа при взятии задачи в работу
+type ClaimOptions struct {
+	// ChildrenDepth сколько уровней подзадач любых статусов вернуть в Children: 0 - не возвращать,
+	// 1 - только прямые подзадачи, больше - вложенное дерево (не больше maxChildrenDepth)
+	ChildrenDepth int
+
+	// ClientIP и Protocol (http, grpc, mcp) запроса исполнителя записываются в журнал выдачи credentials
+	ClientIP string
+	Protocol string
+}
+
+// TaskNode подзадача без credentials с вложенными подзадачами
+type TaskNode struct {
+	models.Task
+	Children []TaskNode `json:"children,omitempty"`
+}
+
+// CredentialAccessFilter условия выборки журнала выдачи credentials. Пустые поля выборку не ограничивают
+type CredentialAccessFilter struct {
+	TaskID        *uuid.UUID
+	TaskCreatedBy string
+	Assignee      string
+	Service       string // Исполнителю были переданы credentials этого сервиса
+	Reference     string // При выдаче была разрешена эта ссылка на секрет
+	Since         *time.Time
+	Until         *time.Time
+	Limit         int
+}
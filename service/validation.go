@@ -0,0 +1,132 @@
+package service
+
+import (
+	"agent-task-manager/models"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+const (
+	// maxRetryAttempts ограничивает количество попыток в политике повторов
+	maxRetryAttempts = 100
+	// defaultBackoffBaseSeconds задержка перед первым повтором по умолчанию
+	defaultBackoffBaseSeconds = 30
+	// defaultMaxDelaySeconds максимальная задержка между попытками по умолчанию
+	defaultMaxDelaySeconds = 3600
+
+	// minPriority и maxPriority ограничивают допустимый приоритет задачи
+	minPriority = -100
+	maxPriority = 100
+)
+
+// validateCredentials проверяет структуру credentials
+func validateCredentials(credentials json.RawMessage) (map[string]map[string]string, error) {
+	if credentials == nil || len(credentials) == 0 {
+		return nil, nil
+	}
+
+	var credsMap map[string]map[string]string
+	if err := json.Unmarshal(credentials, &credsMap); err != nil {
+		return nil, err
+	}
+
+	return credsMap, nil
+}
+
+// ValidateCredentialsPayload проверяет структуру credentials и что все поля заполнены
+func ValidateCredentialsPayload(credentials json.RawMessage) error {
+	credsMap, err := validateCredentials(credentials)
+	if err != nil {
+		return errors.New("invalid credentials format: " + err.Error())
+	}
+
+	for serviceName, serviceVars := range credsMap {
+		if serviceName == "" {
+			return errors.New("service name cannot be empty")
+		}
+		if serviceVars == nil || len(serviceVars) == 0 {
+			return errors.New("service credentials cannot be empty")
+		}
+
+		for envVar, value := range serviceVars {
+			if envVar == "" {
+				return errors.New("environment variable name cannot be empty")
+			}
+			if value == "" {
+				return errors.New("environment variable value cannot be empty")
+			}
+		}
+	}
+
+	return nil
+}
+
+// isParentStatusAllowed проверяет, разрешен ли статус родительской задачи
+func isParentStatusAllowed(status models.TaskStatus) bool {
+	allowedStatuses := []models.TaskStatus{
+		models.StatusWaiting,
+		models.StatusWorking,
+		models.StatusSubmitted,
+	}
+
+	for _, allowed := range allowedStatuses {
+		if status == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRetryPolicy проверяет политику повторных попыток и заполняет значения по умолчанию
+func validateRetryPolicy(policy *RetryPolicy) (*RetryPolicy, error) {
+	if policy == nil {
+		return &RetryPolicy{MaxAttempts: 1}, nil
+	}
+
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > maxRetryAttempts {
+		return nil, errors.New("max_attempts must be between 1 and 100")
+	}
+	if policy.BackoffBaseSeconds < 0 {
+		return nil, errors.New("backoff_base_seconds cannot be negative")
+	}
+	if policy.MaxDelaySeconds < 0 {
+		return nil, errors.New("max_delay_seconds cannot be negative")
+	}
+
+	validated := *policy
+	if validated.BackoffBaseSeconds == 0 {
+		validated.BackoffBaseSeconds = defaultBackoffBaseSeconds
+	}
+	if validated.MaxDelaySeconds == 0 {
+		validated.MaxDelaySeconds = defaultMaxDelaySeconds
+	}
+	if validated.MaxDelaySeconds < validated.BackoffBaseSeconds {
+		return nil, errors.New("max_delay_seconds cannot be less than backoff_base_seconds")
+	}
+
+	return &validated, nil
+}
+
+// ValidatePriority проверяет, что приоритет находится в допустимом диапазоне
+func ValidatePriority(priority *int) error {
+	if priority == nil {
+		return nil
+	}
+	if *priority < minPriority || *priority > maxPriority {
+		return errors.New("priority must be between -100 and 100")
+	}
+	return nil
+}
+
+// validateChildFailurePolicy проверяет политику реакции на неудачу подзадачи, по умолчанию wait
+func validateChildFailurePolicy(policy models.ChildFailurePolicy) (models.ChildFailurePolicy, error) {
+	if policy == "" {
+		return models.ChildFailureWait, nil
+	}
+	if !slices.Contains(models.ChildFailurePolicies, policy) {
+		return "", errors.New("on_child_failure must be one of wait, resubmit_parent, fail_parent, cancel_siblings")
+	}
+	return policy, nil
+}
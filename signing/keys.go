@@ -0,0 +1,111 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Алгоритмы подписи JWT: алгоритм определяется типом ключа
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minRSABits минимальный размер ключа RSA
+const minRSABits = 2048
+
+// algorithmFor возвращает алгоритм подписи для открытого ключа и проверяет, что ключ поддерживается
+func algorithmFor(public crypto.PublicKey) (string, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSABits {
+			return "", fmt.Errorf("RSA key must be at least %d bits, got %d", minRSABits, key.N.BitLen())
+		}
+		return AlgorithmRS256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T: only RSA and Ed25519 keys are supported", public)
+	}
+}
+
+// parsePrivateKey разбирает закрытый ключ RSA или Ed25519 в формате PEM (PKCS #8 или PKCS #1)
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q: expected PRIVATE KEY or RSA PRIVATE KEY", block.Type)
+	}
+}
+
+// parsePublicKey разбирает открытый ключ RSA или Ed25519 в формате PEM: PUBLIC KEY, RSA PUBLIC KEY или сертификат
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q: expected PUBLIC KEY, RSA PUBLIC KEY or CERTIFICATE", block.Type)
+	}
+}
+
+// GenerateKey создает закрытый ключ для алгоритма RS256 (RSA размером bits) или EdDSA (Ed25519)
+// и возвращает его в формате PEM (PKCS #8)
+func GenerateKey(algorithm string, bits int) ([]byte, error) {
+	var key crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		if bits < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		key, err = rsa.GenerateKey(rand.Reader, bits)
+	case AlgorithmEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q: use %s or %s", algorithm, AlgorithmRS256, AlgorithmEdDSA)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
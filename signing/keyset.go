@@ -0,0 +1,167 @@
+// Package signing подписывает и проверяет JWT асимметричными ключами RSA (RS256) и Ed25519 (EdDSA).
+// Каждый токен содержит в заголовке kid ключа подписи, поэтому для ротации достаточно опубликовать новый ключ
+// в JWKS, переключить подпись на него и удалить прежний ключ после истечения выданных им токенов.
+// Проверка подписи требует только открытых ключей, и другие сервисы получают их из /.well-known/jwks.json
+package signing
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// maxKeyIDLength максимальная длина kid ключа
+const maxKeyIDLength = 64
+
+// Key ключ проверки подписи JWT. Закрытая часть есть только у ключей подписи
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	private   crypto.Signer
+}
+
+// KeySet набор ключей JWT. Новые токены подписываются активным ключом, а принимаются токены,
+// подписанные любым ключом набора
+type KeySet struct {
+	keys     map[string]*Key
+	order    []string // Порядок ключей в конфигурации, в нем же они публикуются в JWKS
+	activeID string
+}
+
+// LoadKeySet читает ключи подписи signingSpec и ключи только для проверки verificationSpec.
+// Оба списка имеют вид "kid1:/path/key1.pem,kid2:/path/key2.pem": ключи подписи - закрытые ключи,
+// ключи проверки - открытые ключи или сертификаты. Если activeID пуст, активным становится первый ключ подписи
+func LoadKeySet(signingSpec, activeID, verificationSpec string) (*KeySet, error) {
+	keySet := &KeySet{keys: make(map[string]*Key)}
+
+	err := forEachKeyFile(signingSpec, func(id string, data []byte) error {
+		private, err := parsePrivateKey(data)
+		if err != nil {
+			return fmt.Errorf("signing key %q: %w", id, err)
+		}
+		if err := keySet.add(id, private.Public(), private); err != nil {
+			return err
+		}
+		if keySet.activeID == "" {
+			keySet.activeID = id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachKeyFile(verificationSpec, func(id string, data []byte) error {
+		public, err := parsePublicKey(data)
+		if err != nil {
+			return fmt.Errorf("verification key %q: %w", id, err)
+		}
+		return keySet.add(id, public, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if keySet.activeID == "" {
+		return nil, errors.New("no signing keys configured")
+	}
+	if activeID != "" {
+		key, ok := keySet.keys[activeID]
+		if !ok || key.private == nil {
+			return nil, fmt.Errorf("active key %q is not in the signing key list", activeID)
+		}
+		keySet.activeID = activeID
+	}
+
+	return keySet, nil
+}
+
+// forEachKeyFile разбирает список "kid:/path,..." и передает fn содержимое каждого файла
+func forEachKeyFile(spec string, fn func(id string, data []byte) error) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, path, ok := strings.Cut(entry, ":")
+		id, path = strings.TrimSpace(id), strings.TrimSpace(path)
+		if !ok || id == "" || path == "" {
+			return fmt.Errorf("invalid key entry %q: expected kid:/path/to/key.pem", entry)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", id, err)
+		}
+		if err := fn(id, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add добавляет ключ в набор, проверяя kid и тип ключа
+func (s *KeySet) add(id string, public crypto.PublicKey, private crypto.Signer) error {
+	if len(id) > maxKeyIDLength {
+		return fmt.Errorf("key id %q is longer than %d characters", id, maxKeyIDLength)
+	}
+	if _, exists := s.keys[id]; exists {
+		return fmt.Errorf("duplicate key id %q", id)
+	}
+
+	algorithm, err := algorithmFor(public)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", id, err)
+	}
+
+	s.keys[id] = &Key{ID: id, Algorithm: algorithm, Public: public, private: private}
+	s.order = append(s.order, id)
+	return nil
+}
+
+// ActiveKeyID возвращает kid ключа, которым подписываются новые токены
+func (s *KeySet) ActiveKeyID() string {
+	return s.activeID
+}
+
+// Sign подписывает claims активным ключом и указывает его kid в заголовке токена
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := s.keys[s.activeID]
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// VerificationKey возвращает открытый ключ для проверки подписи токена по kid из заголовка.
+// Используется как jwt.Keyfunc. Алгоритм токена должен совпадать с алгоритмом ключа
+func (s *KeySet) VerificationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	if id == "" {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", id)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("token algorithm %s does not match key %q algorithm %s", token.Method.Alg(), id, key.Algorithm)
+	}
+	return key.Public, nil
+}
+
+// Keys возвращает все ключи набора в порядке конфигурации
+func (s *KeySet) Keys() []*Key {
+	keys := make([]*Key, len(s.order))
+	for i, id := range s.order {
+		keys[i] = s.keys[id]
+	}
+	return keys
+}
@@ -0,0 +1,250 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys ключи, созданные для тестов пакета: RSA ключ генерируется долго, поэтому создается один раз
+var testKeys = map[string]crypto.Signer{}
+
+// testKey возвращает закрытый ключ алгоритма algorithm, созданный для теста
+func testKey(t *testing.T, algorithm string) crypto.Signer {
+	t.Helper()
+	if key, ok := testKeys[algorithm]; ok {
+		return key
+	}
+
+	data, err := GenerateKey(algorithm, minRSABits)
+	if err != nil {
+		t.Fatalf("GenerateKey(%s): %v", algorithm, err)
+	}
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		t.Fatalf("parsePrivateKey: %v", err)
+	}
+	testKeys[algorithm] = key
+	return key
+}
+
+// writePrivateKey сохраняет закрытый ключ в PEM файл и возвращает путь к нему
+func writePrivateKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return writePEM(t, "PRIVATE KEY", der)
+}
+
+// writePublicKey сохраняет открытый ключ в PEM файл и возвращает путь к нему
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return writePEM(t, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// newTestKeySet создает набор с ключом подписи RS256 "rsa" и ключом проверки EdDSA "ed"
+func newTestKeySet(t *testing.T) *KeySet {
+	t.Helper()
+	rsaKey := testKey(t, AlgorithmRS256)
+	edKey := testKey(t, AlgorithmEdDSA)
+
+	keySet, err := LoadKeySet("rsa:"+writePrivateKey(t, rsaKey), "", "ed:"+writePublicKey(t, edKey.Public()))
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	return keySet
+}
+
+func TestLoadKeySet(t *testing.T) {
+	rsaPath := writePrivateKey(t, testKey(t, AlgorithmRS256))
+	edPath := writePrivateKey(t, testKey(t, AlgorithmEdDSA))
+	edPublicPath := writePublicKey(t, testKey(t, AlgorithmEdDSA).Public())
+
+	tests := []struct {
+		name         string
+		signing      string
+		activeID     string
+		verification string
+		wantActive   string
+		wantErr      bool
+	}{
+		{name: "first signing key is active", signing: "rsa:" + rsaPath + ",ed:" + edPath, wantActive: "rsa"},
+		{name: "explicit active key", signing: "rsa:" + rsaPath + ",ed:" + edPath, activeID: "ed", wantActive: "ed"},
+		{name: "no signing keys", verification: "ed:" + edPublicPath, wantErr: true},
+		{name: "verification key cannot be active", signing: "rsa:" + rsaPath, activeID: "ed", verification: "ed:" + edPublicPath, wantErr: true},
+		{name: "duplicate kid", signing: "rsa:" + rsaPath, verification: "rsa:" + edPublicPath, wantErr: true},
+		{name: "public key as signing key", signing: "ed:" + edPublicPath, wantErr: true},
+		{name: "missing path", signing: "rsa:", wantErr: true},
+		{name: "missing file", signing: "rsa:" + filepath.Join(t.TempDir(), "missing.pem"), wantErr: true},
+		{name: "long kid", signing: strings.Repeat("k", maxKeyIDLength+1) + ":" + rsaPath, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keySet, err := LoadKeySet(tt.signing, tt.activeID, tt.verification)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeySet: %v", err)
+			}
+			if keySet.ActiveKeyID() != tt.wantActive {
+				t.Fatalf("expected active key %q, got %q", tt.wantActive, keySet.ActiveKeyID())
+			}
+		})
+	}
+}
+
+func TestVerificationKey(t *testing.T) {
+	keySet := newTestKeySet(t)
+	rsaKey := testKey(t, AlgorithmRS256)
+	edKey := testKey(t, AlgorithmEdDSA)
+	claims := jwt.MapClaims{"user_id": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+
+	// sign подписывает claims методом method и ключом key с kid в заголовке (пустой kid не указывается)
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return signed
+	}
+	rsaPublicPEM, err := os.ReadFile(writePublicKey(t, rsaKey.Public()))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	active, err := keySet.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "active signing key", token: active, valid: true},
+		{name: "verification key", token: sign(jwt.SigningMethodEdDSA, "ed", edKey), valid: true},
+		{name: "no kid", token: sign(jwt.SigningMethodRS256, "", rsaKey)},
+		{name: "unknown kid", token: sign(jwt.SigningMethodRS256, "other", rsaKey)},
+		{name: "algorithm of another key", token: sign(jwt.SigningMethodEdDSA, "rsa", edKey)},
+		{name: "kid of another key", token: sign(jwt.SigningMethodRS256, "ed", rsaKey)},
+		// Подмена алгоритма: HMAC подпись открытым ключом RSA как секретом
+		{name: "HMAC with public key", token: sign(jwt.SigningMethodHS256, "rsa", rsaPublicPEM)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, keySet.VerificationKey)
+			if tt.valid && err != nil {
+				t.Fatalf("expected token to be valid, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected token to be rejected")
+			}
+		})
+	}
+
+	// Токен активного ключа указывает его kid и алгоритм
+	token, _, err := jwt.NewParser().ParseUnverified(active, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	if token.Header["kid"] != "rsa" || token.Method.Alg() != AlgorithmRS256 {
+		t.Fatalf("unexpected token header %v", token.Header)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keySet := newTestKeySet(t)
+	rsaKey := testKey(t, AlgorithmRS256).Public().(*rsa.PublicKey)
+	edKey := testKey(t, AlgorithmEdDSA).Public().(ed25519.PublicKey)
+
+	data, err := json.Marshal(keySet.JWKS())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %s", data)
+	}
+
+	// Ключи публикуются в порядке конфигурации: сначала ключи подписи, затем ключи проверки
+	rsaJWK, edJWK := jwks.Keys[0], jwks.Keys[1]
+	for _, jwk := range jwks.Keys {
+		if jwk["use"] != "sig" {
+			t.Errorf("expected use sig, got %v", jwk)
+		}
+		// Закрытые параметры ключа не публикуются
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+			if _, ok := jwk[private]; ok {
+				t.Errorf("JWK %s contains private parameter %q", jwk["kid"], private)
+			}
+		}
+	}
+
+	if rsaJWK["kid"] != "rsa" || rsaJWK["kty"] != "RSA" || rsaJWK["alg"] != AlgorithmRS256 {
+		t.Fatalf("unexpected RSA JWK %v", rsaJWK)
+	}
+	if decodeSegment(t, rsaJWK["n"]).Cmp(rsaKey.N) != 0 || decodeSegment(t, rsaJWK["e"]).Int64() != int64(rsaKey.E) {
+		t.Fatal("RSA JWK does not match the public key")
+	}
+
+	if edJWK["kid"] != "ed" || edJWK["kty"] != "OKP" || edJWK["crv"] != "Ed25519" || edJWK["alg"] != AlgorithmEdDSA {
+		t.Fatalf("unexpected Ed25519 JWK %v", edJWK)
+	}
+	if x, err := base64.RawURLEncoding.DecodeString(edJWK["x"]); err != nil || !edKey.Equal(ed25519.PublicKey(x)) {
+		t.Fatalf("Ed25519 JWK does not match the public key: %v", err)
+	}
+}
+
+// decodeSegment декодирует целое число JWK из base64url без выравнивания
+func decodeSegment(t *testing.T, value string) *big.Int {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		t.Fatalf("invalid base64url %q: %v", value, err)
+	}
+	return new(big.Int).SetBytes(data)
+}
@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// reservedNetworks сети, не покрытые проверками net/netip, в которые вебхуки не отправляются:
+// "эта" сеть, CGNAT, служебные адреса IETF, сеть тестирования производительности и зарезервированный диапазон
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// TargetPolicy ограничивает адреса, на которые сервер отправляет вебхуки: URL подписчика не может указывать
+// на loopback, частные, link-local и зарезервированные адреса, если хост или сеть не разрешены явно.
+// Без этого подписка позволила бы пользователю обращаться от имени сервера к внутренним сервисам
+type TargetPolicy struct {
+	hosts    []string       // Имена хостов, которым разрешены внутренние адреса
+	networks []netip.Prefix // Внутренние сети, в которые разрешена отправка
+}
+
+// ParseTargetPolicy разбирает список разрешенных внутренних получателей вида "hooks.internal,10.1.0.0/16":
+// имена хостов и сети в нотации CIDR. Пустой список запрещает все внутренние адреса
+func ParseTargetPolicy(spec string) (*TargetPolicy, error) {
+	policy := &TargetPolicy{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", entry, err)
+			}
+			policy.networks = append(policy.networks, prefix.Masked())
+			continue
+		}
+		policy.hosts = append(policy.hosts, normalizeHost(entry))
+	}
+	return policy, nil
+}
+
+// normalizeHost приводит имя хоста к виду для сравнения: нижний регистр без завершающей точки
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// isInternal сообщает, что адрес относится к loopback, частным, link-local, multicast или зарезервированным сетям
+func isInternal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	return slices.ContainsFunc(reservedNetworks, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+// allowsHost сообщает, что хост разрешен явно и может указывать на внутренние адреса
+func (p *TargetPolicy) allowsHost(host string) bool {
+	return slices.Contains(p.hosts, normalizeHost(host))
+}
+
+// allowsAddr сообщает, что на адрес можно отправлять вебхуки
+func (p *TargetPolicy) allowsAddr(addr netip.Addr) bool {
+	if !isInternal(addr) {
+		return true
+	}
+	addr = addr.Unmap()
+	return slices.ContainsFunc(p.networks, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+// CheckURL проверяет URL подписки: хост должен разрешаться только в допустимые адреса.
+// Вызывается при создании подписки; при отправке адрес проверяется еще раз, так как DNS-запись могла измениться
+func (p *TargetPolicy) CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := parsed.Hostname()
+	if p.allowsHost(host) {
+		return nil
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("cannot resolve host %s: %w", host, err)
+		}
+	}
+
+	for _, addr := range addrs {
+		if !p.allowsAddr(addr) {
+			return fmt.Errorf("url host %s resolves to a loopback, private or link-local address %s", host, addr)
+		}
+	}
+	return nil
+}
+
+// dialContext устанавливает соединение с подписчиком, проверяя адрес после разрешения имени,
+// непосредственно перед подключением. Проверка действует и для перенаправлений
+func (p *TargetPolicy) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: deliveryTimeout}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if !p.allowsHost(host) {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				return err
+			}
+			if !p.allowsAddr(addr) {
+				return errors.New("webhook target " + host + " resolves to a forbidden address " + addr.String())
+			}
+			return nil
+		}
+	}
+
+	return dialer.DialContext(ctx, network, address)
+}
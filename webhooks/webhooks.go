@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agent-task-manager/events"
+	"agent-task-manager/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxDeliveryAttempts - после стольких неудачных попыток доставка помечается как failed
+	MaxDeliveryAttempts = 10
+	// deliveryTimeout - таймаут одного HTTP запроса к подписчику
+	deliveryTimeout = 10 * time.Second
+	// baseRetryDelay и maxRetryDelay задают экспоненциальную задержку между попытками
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+	// maxErrorLength - ограничение длины сохраняемого текста ошибки
+	maxErrorLength = 1000
+)
+
+// Заголовки, которые получает подписчик
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// targets адреса, на которые разрешена отправка вебхуков. По умолчанию внутренние адреса запрещены
+var targets = &TargetPolicy{}
+
+// httpClient отправляет вебхуки напрямую, без прокси из окружения, чтобы проверка адреса получателя
+// относилась к самому подписчику
+var httpClient = &http.Client{
+	Timeout: deliveryTimeout,
+	Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return targets.dialContext(ctx, network, address)
+		},
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: deliveryTimeout,
+	},
+}
+
+// Register задает допустимые адреса получателей и подключает постановку событий задач в очередь доставки
+// вебхуков в транзакции перехода. policy nil - внутренние адреса запрещены
+func Register(policy *TargetPolicy) {
+	if policy != nil {
+		targets = policy
+	}
+	events.RegisterRecorder(Enqueue)
+}
+
+// CheckURL проверяет, что URL подписки не указывает на запрещенные внутренние адреса
+func CheckURL(ctx context.Context, rawURL string) error {
+	return targets.CheckURL(ctx, rawURL)
+}
+
+// GenerateSecret создает случайный ключ подписи для подписки
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign вычисляет подпись тела запроса: hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue сохраняет доставки событий для всех подходящих активных подписок в транзакции перехода tx,
+// поэтому доставка не теряется при остановке сервиса сразу после коммита.
+// ID события в доставке и её теле - ID строки журнала task_events
+func Enqueue(tx *gorm.DB, recorded []events.Event) error {
+	now := time.Now()
+	for _, event := range recorded {
+		var subscriptions []models.WebhookSubscription
+		if err := tx.
+			Where("active = ?", true).
+			Where("(filter_type = ? AND filter_value = ?) OR (filter_type = ? AND filter_value = ?) OR (filter_type = ? AND filter_value = ?)",
+				models.WebhookFilterCreator, event.Task.CreatedBy,
+				models.WebhookFilterAssignee, event.Task.Assignee,
+				models.WebhookFilterRootTask, event.RootTaskID.String()).
+			Find(&subscriptions).Error; err != nil {
+			return fmt.Errorf("failed to find webhook subscriptions for event %d: %w", event.ID, err)
+		}
+
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload for event %d: %w", event.ID, err)
+		}
+
+		deliveries := make([]models.WebhookDelivery, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			// Пустой список типов означает подписку на все события
+			if len(subscription.EventTypes) > 0 && !subscription.EventTypes.Contains(string(event.Type)) {
+				continue
+			}
+
+			deliveries = append(deliveries, models.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				EventType:      string(event.Type),
+				Payload:        payload,
+				Status:         models.DeliveryPending,
+				NextAttemptAt:  now,
+			})
+		}
+
+		if len(deliveries) == 0 {
+			continue
+		}
+
+		if err := tx.Create(&deliveries).Error; err != nil {
+			return fmt.Errorf("failed to enqueue webhook deliveries for event %d: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// Deliver отправляет одну доставку подписчику. Возвращает ошибку, если подписчик не ответил 2xx
+func Deliver(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) error {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "agent-task-manager-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// RetryDelay возвращает задержку перед следующей попыткой: 30s * 2^(attempts-1), но не более 6 часов
+func RetryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// TruncateError обрезает текст ошибки до допустимой длины
+func TruncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
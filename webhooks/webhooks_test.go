@@ -0,0 +1,135 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"agent-task-manager/models"
+
+	"github.com/google/uuid"
+)
+
+var signaturePattern = regexp.MustCompile(`^sha256=[0-9a-f]{64}$`)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"completed"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	// Подпись проверяется получателем так: HMAC-SHA256 от "<timestamp>.<body>"
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Fatalf("expected %s, got %s", want, signature)
+	}
+	if !signaturePattern.MatchString(signature) {
+		t.Fatalf("unexpected signature format %q", signature)
+	}
+
+	// Подпись зависит от ключа, времени и тела
+	for name, other := range map[string]string{
+		"secret":    Sign("other", 1700000000, body),
+		"timestamp": Sign("secret", 1700000001, body),
+		"body":      Sign("secret", 1700000000, []byte(`{"type":"failed"}`)),
+	} {
+		if other == signature {
+			t.Errorf("signature does not depend on %s", name)
+		}
+	}
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	subscription := models.WebhookSubscription{URL: server.URL, Secret: "secret"}
+	delivery := models.WebhookDelivery{ID: uuid.New(), EventType: "completed", Payload: []byte(`{"id":1}`)}
+
+	// По умолчанию loopback адрес тестового сервера запрещен и проверяется при подключении
+	if err := Deliver(context.Background(), subscription, delivery); err == nil {
+		t.Fatal("expected delivery to a loopback address to be rejected")
+	}
+	if received != nil {
+		t.Fatal("expected no request to reach the server")
+	}
+
+	previous := targets
+	targets = &TargetPolicy{networks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}
+	defer func() { targets = previous }()
+
+	if err := Deliver(context.Background(), subscription, delivery); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s header: %v", HeaderTimestamp, err)
+	}
+	if got, want := received.Header.Get(HeaderSignature), Sign("secret", timestamp, receivedBody); got != want {
+		t.Fatalf("expected signature %s, got %s", want, got)
+	}
+	if received.Header.Get(HeaderEvent) != "completed" || received.Header.Get(HeaderDelivery) != delivery.ID.String() {
+		t.Fatalf("unexpected headers %v", received.Header)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	policy, err := ParseTargetPolicy("Hooks.Internal., 10.1.0.0/16")
+	if err != nil {
+		t.Fatalf("ParseTargetPolicy: %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{url: "https://8.8.8.8/hook", allowed: true},
+		{url: "https://[2606:4700:4700::1111]/hook", allowed: true},
+		{url: "http://127.0.0.1:8081/task"},
+		{url: "http://[::1]/hook"},
+		{url: "http://[::ffff:127.0.0.1]/hook"},
+		{url: "http://0.0.0.0/hook"},
+		{url: "http://169.254.169.254/latest/meta-data"},
+		{url: "http://192.168.1.10/hook"},
+		{url: "http://172.16.0.1/hook"},
+		{url: "http://100.64.0.1/hook"},
+		{url: "http://[fe80::1]/hook"},
+		{url: "http://[fc00::1]/hook"},
+		{url: "http://224.0.0.1/hook"},
+		{url: "http://10.2.0.1/hook"},
+		{url: "http://10.1.2.3/hook", allowed: true},
+		{url: "https://hooks.internal/hook", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := policy.CheckURL(context.Background(), tt.url)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected %s to be allowed, got %v", tt.url, err)
+			}
+			if !tt.allowed && err == nil {
+				t.Fatalf("expected %s to be rejected", tt.url)
+			}
+		})
+	}
+}
+
+func TestParseTargetPolicy(t *testing.T) {
+	for _, spec := range []string{"10.0.0.0/33", "not-an-ip/8"} {
+		if _, err := ParseTargetPolicy(spec); err == nil {
+			t.Errorf("ParseTargetPolicy(%q): expected error", spec)
+		}
+	}
+}